@@ -1,9 +1,15 @@
 package server
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 // handlerWithGlobalMiddlewares wraps the handler function for a request with
@@ -12,8 +18,13 @@ import (
 func (s *Server) handlerWithGlobalMiddlewares(handler httputils.APIFunc) httputils.APIFunc {
 	next := handler
 
+	traced := tracing.Enabled()
 	for _, m := range s.middlewares {
-		next = m.WrapHandler(next)
+		if traced {
+			next = traceMiddleware(m, next)
+		} else {
+			next = m.WrapHandler(next)
+		}
 	}
 
 	if s.cfg.Logging && logrus.GetLevel() == logrus.DebugLevel {
@@ -22,3 +33,28 @@ func (s *Server) handlerWithGlobalMiddlewares(handler httputils.APIFunc) httputi
 
 	return next
 }
+
+// traceMiddleware wraps handler with m, recording the time spent in m
+// before it calls handler in a span. handler runs as a child of the span of
+// the request, not of the middleware's. Only the errors returned by m
+// before it calls handler are recorded on the span: once handler is called,
+// the span is finished and exported, and must not be modified.
+func traceMiddleware(m middleware.Middleware, handler httputils.APIFunc) httputils.APIFunc {
+	name := "middleware " + strings.TrimPrefix(fmt.Sprintf("%T", m), "*")
+	return func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		parent := tracing.FromContext(ctx)
+		span, spanCtx := tracing.StartSpan(ctx, name)
+		handled := false
+		wrapped := m.WrapHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+			handled = true
+			span.Finish()
+			return handler(tracing.WithSpan(ctx, parent), w, r, vars)
+		})
+		err := wrapped(spanCtx, w, r, vars)
+		if !handled {
+			span.SetError(err)
+			span.Finish()
+		}
+		return err
+	}
+}
@@ -1,10 +1,18 @@
 package checkpoint
 
-import "github.com/docker/docker/api/types"
+import (
+	"io"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"golang.org/x/net/context"
+)
 
 // Backend for Checkpoint
 type Backend interface {
 	CheckpointCreate(container string, config types.CheckpointCreateOptions) error
 	CheckpointDelete(container string, config types.CheckpointDeleteOptions) error
+	CheckpointExport(container string, config types.CheckpointExportOptions, out io.Writer) error
+	CheckpointImport(ctx context.Context, config types.CheckpointImportOptions, in io.Reader) (container.ContainerCreateCreatedBody, error)
 	CheckpointList(container string, config types.CheckpointListOptions) ([]types.Checkpoint, error)
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/types"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
@@ -63,3 +64,52 @@ func (s *checkpointRouter) deleteContainerCheckpoint(ctx context.Context, w http
 	w.WriteHeader(http.StatusNoContent)
 	return nil
 }
+
+func (s *checkpointRouter) getContainerCheckpointExport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	w.Header().Set("Content-Type", "application/x-tar")
+	return s.backend.CheckpointExport(vars["name"], types.CheckpointExportOptions{
+		CheckpointDir: r.Form.Get("dir"),
+		CheckpointID:  vars["checkpoint"],
+	}, w)
+}
+
+func (s *checkpointRouter) postCheckpointImport(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+	if err := httputils.ParseForm(r); err != nil {
+		return err
+	}
+
+	options := types.CheckpointImportOptions{
+		Name: r.Form.Get("name"),
+	}
+	if hostConfigJSON := r.Form.Get("hostConfig"); hostConfigJSON != "" {
+		if err := json.Unmarshal([]byte(hostConfigJSON), &options.HostConfig); err != nil {
+			return invalidRequestError{errors.Wrap(err, "error reading host config")}
+		}
+	}
+	if networkingConfigJSON := r.Form.Get("networkingConfig"); networkingConfigJSON != "" {
+		if err := json.Unmarshal([]byte(networkingConfigJSON), &options.NetworkingConfig); err != nil {
+			return invalidRequestError{errors.Wrap(err, "error reading networking config")}
+		}
+	}
+
+	created, err := s.backend.CheckpointImport(ctx, options, r.Body)
+	if err != nil {
+		return err
+	}
+
+	return httputils.WriteJSON(w, http.StatusCreated, created)
+}
+
+type invalidRequestError struct {
+	cause error
+}
+
+func (e invalidRequestError) Error() string {
+	return e.cause.Error()
+}
+
+func (e invalidRequestError) InvalidParameter() {}
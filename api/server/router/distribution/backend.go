@@ -1,9 +1,12 @@
 package distribution
 
 import (
+	"io"
+
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"golang.org/x/net/context"
 )
 
@@ -11,4 +14,5 @@ import (
 // to provide image specific functionality.
 type Backend interface {
 	GetRepository(context.Context, reference.Named, *types.AuthConfig) (distribution.Repository, bool, error)
+	PushManifestList(ctx context.Context, image string, manifests []registrytypes.ManifestListEntry, format, compression string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error
 }
@@ -2,6 +2,20 @@ package distribution
 
 import "github.com/docker/docker/api/server/router"
 
+type validationError struct {
+	cause error
+}
+
+func (e validationError) Error() string {
+	return e.cause.Error()
+}
+
+func (e validationError) Cause() error {
+	return e.cause
+}
+
+func (e validationError) InvalidParameter() {}
+
 // distributionRouter is a router to talk with the registry
 type distributionRouter struct {
 	backend Backend
@@ -27,5 +41,7 @@ func (r *distributionRouter) initRoutes() {
 	r.routes = []router.Route{
 		// GET
 		router.NewGetRoute("/distribution/{name:.*}/json", r.getDistributionInfo),
+		// POST
+		router.NewPostRoute("/distribution/{name:.*}/push", r.postDistributionPush),
 	}
 }
@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/api/server/router"
 	"github.com/docker/docker/api/server/router/debug"
 	"github.com/docker/docker/dockerversion"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/gorilla/mux"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
@@ -127,6 +128,16 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 		// immediate function being called should still be passed
 		// as 'args' on the function call.
 		ctx := context.WithValue(context.Background(), dockerversion.UAStringKey, r.Header.Get("User-Agent"))
+
+		// The root span of the request joins the trace of the client if it
+		// sent a traceparent header. The trace ID is returned to the client so
+		// that slow requests can be looked up in the tracing backend.
+		span, spanCtx := tracing.StartRemoteSpan(ctx, r.Method+" "+r.URL.Path, r.Header.Get(tracing.TraceparentHeader))
+		defer span.Finish()
+		if span != nil {
+			ctx = spanCtx
+			w.Header().Set(tracing.TraceIDHeader, span.TraceID)
+		}
 		handlerFunc := s.handlerWithGlobalMiddlewares(handler)
 
 		vars := mux.Vars(r)
@@ -135,6 +146,7 @@ func (s *Server) makeHTTPHandler(handler httputils.APIFunc) http.HandlerFunc {
 		}
 
 		if err := handlerFunc(ctx, w, r, vars); err != nil {
+			span.SetError(err)
 			statusCode := httputils.GetHTTPErrorStatusCode(err)
 			if statusCode >= 500 {
 				logrus.Errorf("Handler for %s %s returned error: %v", r.Method, r.URL.Path, err)
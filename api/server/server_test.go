@@ -1,6 +1,7 @@
 package server
 
 import (
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
@@ -9,6 +10,7 @@ import (
 	"github.com/docker/docker/api"
 	"github.com/docker/docker/api/server/httputils"
 	"github.com/docker/docker/api/server/middleware"
+	"github.com/docker/docker/pkg/tracing"
 
 	"golang.org/x/net/context"
 )
@@ -44,3 +46,57 @@ func TestMiddlewares(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+type recordingExporter struct {
+	spans []*tracing.Span
+}
+
+func (e *recordingExporter) Export(s *tracing.Span) {
+	e.spans = append(e.spans, s)
+}
+
+func (e *recordingExporter) Close() error {
+	return nil
+}
+
+func TestTracing(t *testing.T) {
+	e := &recordingExporter{}
+	tracing.SetExporter(e)
+	defer tracing.SetExporter(nil)
+
+	srv := &Server{
+		cfg: &Config{},
+	}
+	srv.UseMiddleware(middleware.NewVersionMiddleware("0.1omega2", api.DefaultVersion, api.MinVersion))
+
+	var handlerSpan *tracing.Span
+	handler := srv.makeHTTPHandler(func(ctx context.Context, w http.ResponseWriter, r *http.Request, vars map[string]string) error {
+		handlerSpan = tracing.FromContext(ctx)
+		return errors.New("handler failed")
+	})
+
+	req, _ := http.NewRequest("POST", "/containers/create", nil)
+	req.Header.Set(tracing.TraceparentHeader, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	resp := httptest.NewRecorder()
+	handler(resp, req)
+
+	if len(e.spans) != 2 {
+		t.Fatalf("expected a middleware and a request span, got %d spans", len(e.spans))
+	}
+	mw, root := e.spans[0], e.spans[1]
+	if root.Name != "POST /containers/create" || root.ParentID != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected request span: %+v", root)
+	}
+	if mw.Name != "middleware middleware.VersionMiddleware" || mw.ParentID != root.SpanID {
+		t.Fatalf("unexpected middleware span: %+v", mw)
+	}
+	if _, ok := mw.Attributes["error"]; ok {
+		t.Fatalf("expected the error of the handler not to be recorded on the middleware span: %+v", mw)
+	}
+	if handlerSpan != root {
+		t.Fatalf("expected the handler to run in the request span, got %+v", handlerSpan)
+	}
+	if id := resp.Header().Get(tracing.TraceIDHeader); id != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected trace ID header: %q", id)
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/api/types/network"
 	units "github.com/docker/go-units"
 )
 
@@ -28,6 +29,26 @@ type CheckpointDeleteOptions struct {
 	CheckpointDir string
 }
 
+// CheckpointExportOptions holds parameters to export a checkpoint of a container
+type CheckpointExportOptions struct {
+	CheckpointID  string
+	CheckpointDir string
+}
+
+// CheckpointImportOptions holds parameters to create a container from an
+// exported checkpoint
+type CheckpointImportOptions struct {
+	// Name is the name of the container to create
+	Name string
+	// HostConfig, if set, is the host configuration of the container to
+	// create. Otherwise only the portable settings of the host
+	// configuration in the archive are used.
+	HostConfig *container.HostConfig
+	// NetworkingConfig is the networking configuration of the container
+	// to create.
+	NetworkingConfig *network.NetworkingConfig
+}
+
 // ContainerAttachOptions holds parameters to attach to a container.
 type ContainerAttachOptions struct {
 	Stream     bool
@@ -57,6 +78,21 @@ type ContainerExecInspect struct {
 	Pid         int
 }
 
+// ContainerDiffOptions holds parameters to list the changes to the
+// filesystem of a container.
+type ContainerDiffOptions struct {
+	// Image is the image to report the changes against, instead of the
+	// image of the container.
+	Image string
+	// Paths restricts the changes to these paths, the files under them, or
+	// the paths matching these patterns.
+	Paths   []string
+	Details bool
+	// Digest requests the digest of the added and modified regular files.
+	// It implies Details.
+	Digest bool
+}
+
 // ContainerListOptions holds parameters to list containers with.
 type ContainerListOptions struct {
 	Quiet   bool
@@ -240,7 +276,30 @@ type ImagePullOptions struct {
 type RequestPrivilegeFunc func() (string, error)
 
 //ImagePushOptions holds information to push images.
-type ImagePushOptions ImagePullOptions
+type ImagePushOptions struct {
+	All           bool
+	RegistryAuth  string // RegistryAuth is the base64 encoded credentials for the registry
+	PrivilegeFunc RequestPrivilegeFunc
+	// Format is the manifest format to push: "docker" (the default) for
+	// schema2 manifests, or "oci" for OCI image manifests.
+	Format string
+	// Compression is the compression of the uploaded layers, either "gzip"
+	// (the default) or "zstd", which is only supported with the "oci" format.
+	Compression string
+}
+
+// ManifestListPushOptions holds information to push manifest lists.
+type ManifestListPushOptions struct {
+	RegistryAuth  string // RegistryAuth is the base64 encoded credentials for the registry
+	PrivilegeFunc RequestPrivilegeFunc
+	// Format is the format of the pushed manifests: "docker" (the default)
+	// for a manifest list of schema2 manifests, or "oci" for an OCI image
+	// index of OCI image manifests.
+	Format string
+	// Compression is the compression of the uploaded layers, either "gzip"
+	// (the default) or "zstd", which is only supported with the "oci" format.
+	Compression string
+}
 
 // ImageRemoveOptions holds parameters to remove images.
 type ImageRemoveOptions struct {
@@ -248,6 +307,16 @@ type ImageRemoveOptions struct {
 	PruneChildren bool
 }
 
+// ImageSaveOptions holds parameters to save images with.
+type ImageSaveOptions struct {
+	// Format is the format of the archive, either "docker" (the default)
+	// or "oci" for the OCI image layout.
+	Format string
+	// Compression is the compression of the layers of the OCI image
+	// layout, either "gzip" (the default) or "zstd".
+	Compression string
+}
+
 // ImageSearchOptions holds parameters to search images with.
 type ImageSearchOptions struct {
 	RegistryAuth  string
@@ -52,6 +52,7 @@ type ExecConfig struct {
 	DetachKeys   string   // Escape keys for detach
 	Env          []string // Environment variables
 	Cmd          []string // Execution commands and args
+	Timeout      int      `json:",omitempty"` // Timeout in seconds after which the process is killed, 0 means no timeout
 }
 
 // PluginRmConfig holds arguments for plugin remove.
@@ -22,6 +22,8 @@ type HealthConfig struct {
 	// {"NONE"} : disable healthcheck
 	// {"CMD", args...} : exec arguments directly
 	// {"CMD-SHELL", command} : run command with system's default shell
+	// {"HTTP", url[, status range[, body]]} : GET url from the container's network namespace
+	// {"TCP", port} : connect to port from the container's network namespace
 	Test []string `json:",omitempty"`
 
 	// Zero means to inherit. Durations are expressed as integer nanoseconds.
@@ -34,6 +36,26 @@ type HealthConfig struct {
 	Retries int `json:",omitempty"`
 }
 
+// StopHookConfig holds the command run inside a container before it is sent
+// its stop signal.
+type StopHookConfig struct {
+	// Cmd is the command to run. An empty command disables the stop hook
+	// of the image.
+	Cmd strslice.StrSlice `json:",omitempty"`
+
+	// Timeout is the time to wait for the command to complete before killing
+	// it and proceeding with the stop. Zero means to use the default.
+	// Durations are expressed as integer nanoseconds.
+	Timeout time.Duration `json:",omitempty"`
+}
+
+// StopStage is a signal sent to a container that did not exit after the
+// signals sent before it.
+type StopStage struct {
+	Signal string        // Signal to send
+	Delay  time.Duration // Delay is the time to wait after the previous signal before sending this one.
+}
+
 // Config contains the configuration data about a container.
 // It should hold only portable information about the container.
 // Here, "portable" means "independent from the host we are running on".
@@ -65,5 +87,7 @@ type Config struct {
 	Labels          map[string]string   // List of labels set to this container
 	StopSignal      string              `json:",omitempty"` // Signal to stop a container
 	StopTimeout     *int                `json:",omitempty"` // Timeout (in seconds) to stop a container
+	StopHook        *StopHookConfig     `json:",omitempty"` // Command to run inside the container before it is sent its stop signal
+	StopEscalation  []StopStage         `json:",omitempty"` // Signals to send after the stop signal if the container does not exit
 	Shell           strslice.StrSlice   `json:",omitempty"` // Shell for shell-form of RUN, CMD, ENTRYPOINT
 }
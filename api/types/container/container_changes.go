@@ -11,6 +11,9 @@ package container
 // swagger:model ContainerChangeResponseItem
 type ContainerChangeResponseItem struct {
 
+	// details
+	Details *ContainerChangeResponseItemDetails `json:"Details,omitempty"`
+
 	// Kind of change
 	// Required: true
 	Kind uint8 `json:"Kind"`
@@ -19,3 +22,31 @@ type ContainerChangeResponseItem struct {
 	// Required: true
 	Path string `json:"Path"`
 }
+
+// ContainerChangeResponseItemDetails State of the changed file in the container. Only returned when details are requested, and not for deleted files.
+// swagger:model ContainerChangeResponseItemDetails
+type ContainerChangeResponseItemDetails struct {
+
+	// Digest of the content of the file. Only returned for regular files, when digests are requested.
+	Digest string `json:"Digest,omitempty"`
+
+	// Group ID of the owner of the file
+	// Required: true
+	GID int64 `json:"GID"`
+
+	// Mode and permission bits of the file, as a Go `os.FileMode`
+	// Required: true
+	Mode uint32 `json:"Mode"`
+
+	// Modification time of the file in RFC 3339 format with nano-seconds
+	// Required: true
+	ModTime string `json:"ModTime"`
+
+	// Size of the file in bytes
+	// Required: true
+	Size int64 `json:"Size"`
+
+	// User ID of the owner of the file
+	// Required: true
+	UID int64 `json:"UID"`
+}
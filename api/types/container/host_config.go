@@ -2,6 +2,7 @@ package container
 
 import (
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/blkiodev"
 	"github.com/docker/docker/api/types/mount"
@@ -234,6 +235,12 @@ type DeviceMapping struct {
 type RestartPolicy struct {
 	Name              string
 	MaximumRetryCount int
+
+	// Backoff between restarts. Zero means to use the default.
+	// Durations are expressed as integer nanoseconds.
+	InitialDelay time.Duration `json:",omitempty"` // InitialDelay is the delay before the first restart.
+	MaxDelay     time.Duration `json:",omitempty"` // MaxDelay is the maximum delay the backoff doubles up to.
+	ResetWindow  time.Duration `json:",omitempty"` // ResetWindow is the run time after which the backoff is reset.
 }
 
 // IsNone indicates whether the container has the "no" restart policy.
@@ -254,6 +261,13 @@ func (rp *RestartPolicy) IsOnFailure() bool {
 	return rp.Name == "on-failure"
 }
 
+// IsOnUnhealthy indicates whether the container has the "on-unhealthy" restart policy.
+// This means the container will automatically restart when its health check marks it
+// unhealthy, or when it exits with a non-zero exit status.
+func (rp *RestartPolicy) IsOnUnhealthy() bool {
+	return rp.Name == "on-unhealthy"
+}
+
 // IsUnlessStopped indicates whether the container has the
 // "unless-stopped" restart policy. This means the container will
 // automatically restart unless user has put it to stopped state.
@@ -263,7 +277,31 @@ func (rp *RestartPolicy) IsUnlessStopped() bool {
 
 // IsSame compares two RestartPolicy to see if they are the same
 func (rp *RestartPolicy) IsSame(tp *RestartPolicy) bool {
-	return rp.Name == tp.Name && rp.MaximumRetryCount == tp.MaximumRetryCount
+	return rp.Name == tp.Name && rp.MaximumRetryCount == tp.MaximumRetryCount &&
+		rp.InitialDelay == tp.InitialDelay && rp.MaxDelay == tp.MaxDelay && rp.ResetWindow == tp.ResetWindow
+}
+
+// DependencyCondition is the condition a dependency must reach before the
+// container depending on it is started.
+type DependencyCondition string
+
+// Available dependency conditions
+const (
+	DependencyConditionStarted DependencyCondition = "started" // the dependency is running
+	DependencyConditionHealthy DependencyCondition = "healthy" // the dependency is running and its health check passes
+)
+
+// Dependency represents a container that must be up before the container
+// depending on it is started.
+type Dependency struct {
+	Container string              // Name or ID of the container depended on
+	Condition DependencyCondition `json:",omitempty"` // Condition to wait for, defaults to "started"
+}
+
+// IsHealthy indicates whether the dependency requires the container to be
+// healthy rather than just running.
+func (d *Dependency) IsHealthy() bool {
+	return d.Condition == DependencyConditionHealthy
 }
 
 // LogMode is a type to define the available modes for logging
@@ -328,6 +366,17 @@ type UpdateConfig struct {
 	// Contains container's resources (cgroups, ulimits)
 	Resources
 	RestartPolicy RestartPolicy
+
+	// Labels, if not nil, replaces the labels of the container. An empty,
+	// non-nil map removes all the labels. It has no omitempty option, so
+	// that an empty map is sent as {}, and a nil map as null.
+	Labels map[string]string
+	// Healthcheck, if not nil, replaces the healthcheck of the container.
+	// The health monitor of a running container is restarted.
+	Healthcheck *HealthConfig `json:",omitempty"`
+	// LogOptions are merged into the options of the log driver of the
+	// container. An option with an empty value is removed.
+	LogOptions map[string]string `json:",omitempty"`
 }
 
 // HostConfig the non-portable Config structure of a container.
@@ -344,6 +393,7 @@ type HostConfig struct {
 	AutoRemove      bool          // Automatically remove container when it exits
 	VolumeDriver    string        // Name of the volume driver used to mount volumes
 	VolumesFrom     []string      // List of volumes to take from other container
+	DependsOn       []Dependency  `json:",omitempty"` // Containers that must be up before this container is started
 
 	// Applicable to UNIX platforms
 	CapAdd          strslice.StrSlice // List of kernel capabilities to add to the container
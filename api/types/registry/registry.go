@@ -117,3 +117,19 @@ type DistributionInspect struct {
 	// obtained by parsing the manifest
 	Platforms []v1.Platform
 }
+
+// ManifestListEntry is a local image to reference in a manifest list.
+type ManifestListEntry struct {
+	// Image is the name or ID of the image.
+	Image string
+	// Platform overrides the platform of the image in the manifest list.
+	// The fields which are not set are taken from the configuration of the
+	// image.
+	Platform v1.Platform
+}
+
+// ManifestListPushRequest is the body of a request to push a manifest list.
+type ManifestListPushRequest struct {
+	// Manifests are the images referenced by the manifest list.
+	Manifests []ManifestListEntry
+}
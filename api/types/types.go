@@ -50,7 +50,8 @@ type ImageInspect struct {
 
 // ImageMetadata contains engine-local data about the image
 type ImageMetadata struct {
-	LastTagTime time.Time `json:",omitempty"`
+	LastTagTime  time.Time `json:",omitempty"`
+	LastUsedTime time.Time `json:",omitempty"`
 }
 
 // Container contains response of Engine API:
@@ -296,6 +297,33 @@ type ContainerState struct {
 	StartedAt  string
 	FinishedAt string
 	Health     *Health `json:",omitempty"`
+
+	TerminationReason *TerminationReason `json:",omitempty"` // TerminationReason tells why the container last exited
+}
+
+// TerminationInitiator tells what caused a container to exit
+type TerminationInitiator string
+
+// Possible values for TerminationInitiator
+const (
+	TerminationExit        TerminationInitiator = "exit"         // The main process exited on its own
+	TerminationSignal      TerminationInitiator = "signal"       // The main process was killed by a signal not sent by the daemon
+	TerminationOOM         TerminationInitiator = "oom"          // The main process was killed by the kernel OOM killer
+	TerminationStop        TerminationInitiator = "stop"         // The container was stopped through the API
+	TerminationStopTimeout TerminationInitiator = "stop-timeout" // The container was killed after failing to stop within its stop timeout
+	TerminationKill        TerminationInitiator = "kill"         // The container was killed through the API
+	TerminationRestart     TerminationInitiator = "restart"      // The container was stopped to be restarted
+	TerminationRemove      TerminationInitiator = "remove"       // The container was killed to be removed
+	TerminationShutdown    TerminationInitiator = "shutdown"     // The container was stopped because the daemon shut down
+	TerminationHealth      TerminationInitiator = "health"       // The container was killed because its health check failed
+)
+
+// TerminationReason describes why the main process of a container exited
+type TerminationReason struct {
+	Initiator TerminationInitiator
+	Signal    int      `json:",omitempty"` // Signal the main process was killed with, if any
+	OOMEvents int      `json:",omitempty"` // Number of OOM events reported by the kernel for the container's cgroup during the run
+	Stderr    []string `json:",omitempty"` // Last lines written by the container to stderr
 }
 
 // ContainerNode stores information about the node that a container
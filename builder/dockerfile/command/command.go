@@ -17,6 +17,7 @@ const (
 	Onbuild     = "onbuild"
 	Run         = "run"
 	Shell       = "shell"
+	StopHook    = "stophook"
 	StopSignal  = "stopsignal"
 	User        = "user"
 	Volume      = "volume"
@@ -39,6 +40,7 @@ var Commands = map[string]struct{}{
 	Onbuild:     {},
 	Run:         {},
 	Shell:       {},
+	StopHook:    {},
 	StopSignal:  {},
 	User:        {},
 	Volume:      {},
@@ -493,17 +493,46 @@ func dispatchVolume(d dispatchRequest, c *instructions.VolumeCommand) error {
 	return d.builder.commit(d.state, fmt.Sprintf("VOLUME %v", c.Volumes))
 }
 
-// STOPSIGNAL signal
+// STOPSIGNAL signal [delay signal...]
 //
-// Set the signal that will be used to kill the container.
+// Set the signal that will be used to kill the container, and the signals
+// sent after it if the container does not exit.
 func dispatchStopSignal(d dispatchRequest, c *instructions.StopSignalCommand) error {
 
 	_, err := signal.ParseSignal(c.Signal)
 	if err != nil {
 		return validationError{err}
 	}
+	words := []string{c.Signal}
+	for _, stage := range c.Escalation {
+		if _, err := signal.ParseSignal(stage.Signal); err != nil {
+			return validationError{err}
+		}
+		words = append(words, stage.Delay.String(), stage.Signal)
+	}
 	d.state.runConfig.StopSignal = c.Signal
-	return d.builder.commit(d.state, fmt.Sprintf("STOPSIGNAL %v", c.Signal))
+	d.state.runConfig.StopEscalation = c.Escalation
+	return d.builder.commit(d.state, fmt.Sprintf("STOPSIGNAL %v", strings.Join(words, " ")))
+}
+
+// STOPHOOK [--timeout=DURATION] command
+//
+// Set the command run inside the container before it is sent its stop
+// signal. Argument handling is the same as RUN.
+func dispatchStopHook(d dispatchRequest, c *instructions.StopHookCommand) error {
+	runConfig := d.state.runConfig
+	runConfig.StopHook = &container.StopHookConfig{
+		Cmd:     resolveCmdLine(c.ShellDependantCmdLine, runConfig, d.builder.platform),
+		Timeout: c.Timeout,
+	}
+	commitMessage := "STOPHOOK NONE"
+	if len(runConfig.StopHook.Cmd) != 0 {
+		commitMessage = fmt.Sprintf("STOPHOOK %q", runConfig.StopHook.Cmd)
+		if c.Timeout != 0 {
+			commitMessage = fmt.Sprintf("STOPHOOK --timeout=%s %q", c.Timeout, runConfig.StopHook.Cmd)
+		}
+	}
+	return d.builder.commit(d.state, commitMessage)
 }
 
 // ARG name[=value]
@@ -5,6 +5,7 @@ import (
 	"context"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/backend"
@@ -368,6 +369,63 @@ func TestStopSignal(t *testing.T) {
 	assert.Equal(t, signal, sb.state.runConfig.StopSignal)
 }
 
+func TestStopSignalEscalation(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Windows does not support stopsignal")
+		return
+	}
+	b := newBuilderWithMockBackend()
+	sb := newDispatchRequest(b, '`', nil, newBuildArgs(make(map[string]*string)), newStagesBuildResults())
+	escalation := []container.StopStage{{Signal: "SIGINT", Delay: 10 * time.Second}}
+
+	cmd := &instructions.StopSignalCommand{
+		Signal:     "SIGTERM",
+		Escalation: escalation,
+	}
+	err := dispatch(sb, cmd)
+	require.NoError(t, err)
+	assert.Equal(t, "SIGTERM", sb.state.runConfig.StopSignal)
+	assert.Equal(t, escalation, sb.state.runConfig.StopEscalation)
+
+	cmd = &instructions.StopSignalCommand{
+		Signal:     "SIGTERM",
+		Escalation: []container.StopStage{{Signal: "SIGNOPE", Delay: time.Second}},
+	}
+	err = dispatch(sb, cmd)
+	assert.Error(t, err)
+}
+
+func TestStopHook(t *testing.T) {
+	b := newBuilderWithMockBackend()
+	sb := newDispatchRequest(b, '`', nil, newBuildArgs(make(map[string]*string)), newStagesBuildResults())
+	command := "/bin/drain"
+
+	cmd := &instructions.StopHookCommand{
+		ShellDependantCmdLine: instructions.ShellDependantCmdLine{
+			CmdLine:      strslice.StrSlice{command},
+			PrependShell: true,
+		},
+		Timeout: time.Minute,
+	}
+	err := dispatch(sb, cmd)
+	require.NoError(t, err)
+
+	var expectedCommand strslice.StrSlice
+	if runtime.GOOS == "windows" {
+		expectedCommand = strslice.StrSlice(append([]string{"cmd"}, "/S", "/C", command))
+	} else {
+		expectedCommand = strslice.StrSlice(append([]string{"/bin/sh"}, "-c", command))
+	}
+	require.NotNil(t, sb.state.runConfig.StopHook)
+	assert.Equal(t, expectedCommand, sb.state.runConfig.StopHook.Cmd)
+	assert.Equal(t, time.Minute, sb.state.runConfig.StopHook.Timeout)
+
+	err = dispatch(sb, &instructions.StopHookCommand{})
+	require.NoError(t, err)
+	require.NotNil(t, sb.state.runConfig.StopHook)
+	assert.Len(t, sb.state.runConfig.StopHook.Cmd, 0)
+}
+
 func TestArg(t *testing.T) {
 	b := newBuilderWithMockBackend()
 	sb := newDispatchRequest(b, '`', nil, newBuildArgs(make(map[string]*string)), newStagesBuildResults())
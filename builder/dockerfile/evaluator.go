@@ -87,6 +87,8 @@ func dispatch(d dispatchRequest, cmd instructions.Command) error {
 		return dispatchVolume(d, c)
 	case *instructions.StopSignalCommand:
 		return dispatchStopSignal(d, c)
+	case *instructions.StopHookCommand:
+		return dispatchStopHook(d, c)
 	case *instructions.ArgCommand:
 		return dispatchArg(d, c)
 	case *instructions.ShellCommand:
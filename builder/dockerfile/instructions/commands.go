@@ -4,6 +4,7 @@ import (
 	"errors"
 
 	"strings"
+	"time"
 
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/strslice"
@@ -293,12 +294,14 @@ func (c *VolumeCommand) Expand(expander SingleWordExpander) error {
 	return expandSliceInPlace(c.Volumes, expander)
 }
 
-// StopSignalCommand : STOPSIGNAL signal
+// StopSignalCommand : STOPSIGNAL signal [delay signal...]
 //
-// Set the signal that will be used to kill the container.
+// Set the signal that will be used to kill the container, and the signals
+// sent after it, each one after its delay, if the container does not exit.
 type StopSignalCommand struct {
 	withNameAndCode
-	Signal string
+	Signal     string
+	Escalation []container.StopStage
 }
 
 // Expand variables
@@ -308,6 +311,13 @@ func (c *StopSignalCommand) Expand(expander SingleWordExpander) error {
 		return err
 	}
 	c.Signal = p
+	for i, stage := range c.Escalation {
+		p, err := expander(stage.Signal)
+		if err != nil {
+			return err
+		}
+		c.Escalation[i].Signal = p
+	}
 	return nil
 }
 
@@ -319,6 +329,17 @@ func (c *StopSignalCommand) CheckPlatform(platform string) error {
 	return nil
 }
 
+// StopHookCommand : STOPHOOK [--timeout=DURATION] command
+//
+// Set the command run inside the container before it is sent its stop
+// signal. Argument handling is the same as RUN. STOPHOOK NONE disables the
+// stop hook inherited from the base image.
+type StopHookCommand struct {
+	withNameAndCode
+	ShellDependantCmdLine
+	Timeout time.Duration
+}
+
 // ArgCommand : ARG name[=value]
 //
 // Adds the variable foo to the trusted list of variables that can be passed
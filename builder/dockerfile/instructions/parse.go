@@ -2,6 +2,8 @@ package instructions
 
 import (
 	"fmt"
+	"net"
+	"net/url"
 	"regexp"
 	"sort"
 	"strconv"
@@ -84,6 +86,8 @@ func ParseInstruction(node *parser.Node) (interface{}, error) {
 		return parseVolume(req)
 	case command.StopSignal:
 		return parseStopSignal(req)
+	case command.StopHook:
+		return parseStopHook(req)
 	case command.Arg:
 		return parseArg(req)
 	case command.Shell:
@@ -404,6 +408,35 @@ func parseOptInterval(f *Flag) (time.Duration, error) {
 	}
 	return d, nil
 }
+
+var healthcheckStatusRange = regexp.MustCompile(`^[1-5][0-9][0-9](-[1-5][0-9][0-9])?$`)
+
+// parseHTTPHealthcheck returns the test of a HEALTHCHECK --type=http
+// instruction, checking url with the expected status range and body.
+func parseHTTPHealthcheck(args []string, status, body string) (strslice.StrSlice, error) {
+	if len(args) != 1 {
+		return nil, errors.New("HEALTHCHECK --type=http requires exactly one URL")
+	}
+	u, err := url.Parse(args[0])
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("Invalid URL %#v in HEALTHCHECK --type=http", args[0])
+	}
+	if host := u.Hostname(); host != "localhost" && net.ParseIP(host) == nil {
+		return nil, fmt.Errorf("Invalid URL %#v in HEALTHCHECK --type=http (the host must be localhost or an IP address)", args[0])
+	}
+	if status != "" && !healthcheckStatusRange.MatchString(status) {
+		return nil, fmt.Errorf("Invalid --status %#v in HEALTHCHECK (expected a status code or a range such as 200-299)", status)
+	}
+	test := strslice.StrSlice{"HTTP", args[0]}
+	if status != "" || body != "" {
+		test = append(test, status)
+	}
+	if body != "" {
+		test = append(test, body)
+	}
+	return test, nil
+}
+
 func parseHealthcheck(req parseRequest) (*HealthCheckCommand, error) {
 	if len(req.args) == 0 {
 		return nil, errAtLeastOneArgument("HEALTHCHECK")
@@ -430,25 +463,52 @@ func parseHealthcheck(req parseRequest) (*HealthCheckCommand, error) {
 		flTimeout := req.flags.AddString("timeout", "")
 		flStartPeriod := req.flags.AddString("start-period", "")
 		flRetries := req.flags.AddString("retries", "")
+		flType := req.flags.AddString("type", "")
+		flStatus := req.flags.AddString("status", "")
+		flBody := req.flags.AddString("body", "")
 
 		if err := req.flags.Parse(); err != nil {
 			return nil, err
 		}
 
-		switch typ {
-		case "CMD":
-			cmdSlice := handleJSONArgs(args, req.attributes)
-			if len(cmdSlice) == 0 {
-				return nil, errors.New("Missing command after HEALTHCHECK CMD")
-			}
+		probeType := strings.ToLower(flType.Value)
+		if probeType != "http" && (flStatus.Value != "" || flBody.Value != "") {
+			return nil, errors.New("--status and --body are only supported by HEALTHCHECK --type=http")
+		}
 
-			if !req.attributes["json"] {
-				typ = "CMD-SHELL"
+		switch probeType {
+		case "", "cmd":
+			switch typ {
+			case "CMD":
+				cmdSlice := handleJSONArgs(args, req.attributes)
+				if len(cmdSlice) == 0 {
+					return nil, errors.New("Missing command after HEALTHCHECK CMD")
+				}
+
+				if !req.attributes["json"] {
+					typ = "CMD-SHELL"
+				}
+
+				healthcheck.Test = strslice.StrSlice(append([]string{typ}, cmdSlice...))
+			default:
+				return nil, fmt.Errorf("Unknown type %#v in HEALTHCHECK (try CMD)", typ)
 			}
-
-			healthcheck.Test = strslice.StrSlice(append([]string{typ}, cmdSlice...))
+		case "http":
+			test, err := parseHTTPHealthcheck(req.args, flStatus.Value, flBody.Value)
+			if err != nil {
+				return nil, err
+			}
+			healthcheck.Test = test
+		case "tcp":
+			if len(req.args) != 1 {
+				return nil, errors.New("HEALTHCHECK --type=tcp requires exactly one port")
+			}
+			if port, err := strconv.Atoi(req.args[0]); err != nil || port < 1 || port > 65535 {
+				return nil, fmt.Errorf("Invalid port %#v in HEALTHCHECK --type=tcp", req.args[0])
+			}
+			healthcheck.Test = strslice.StrSlice{"TCP", req.args[0]}
 		default:
-			return nil, fmt.Errorf("Unknown type %#v in HEALTHCHECK (try CMD)", typ)
+			return nil, fmt.Errorf("Unknown --type %#v in HEALTHCHECK (try cmd, http or tcp)", flType.Value)
 		}
 
 		interval, err := parseOptInterval(flInterval)
@@ -547,16 +607,63 @@ func parseStopSignal(req parseRequest) (*StopSignalCommand, error) {
 	if len(req.args) != 1 {
 		return nil, errExactlyOneArgument("STOPSIGNAL")
 	}
-	sig := req.args[0]
+	words := strings.Fields(req.args[0])
+	if len(words) == 0 {
+		return nil, errExactlyOneArgument("STOPSIGNAL")
+	}
+	if len(words)%2 == 0 {
+		return nil, errors.New("STOPSIGNAL requires a signal after each delay")
+	}
 
 	cmd := &StopSignalCommand{
-		Signal:          sig,
+		Signal:          words[0],
 		withNameAndCode: newWithNameAndCode(req),
 	}
+	for i := 1; i < len(words); i += 2 {
+		delay, err := time.ParseDuration(words[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid delay %#v in STOPSIGNAL", words[i])
+		}
+		if delay < 0 {
+			return nil, fmt.Errorf("Delay %#v in STOPSIGNAL cannot be negative", words[i])
+		}
+		cmd.Escalation = append(cmd.Escalation, container.StopStage{
+			Signal: words[i+1],
+			Delay:  delay,
+		})
+	}
 	return cmd, nil
 
 }
 
+func parseStopHook(req parseRequest) (*StopHookCommand, error) {
+	flTimeout := req.flags.AddString("timeout", "")
+	if err := req.flags.Parse(); err != nil {
+		return nil, err
+	}
+
+	cmd := &StopHookCommand{
+		withNameAndCode: newWithNameAndCode(req),
+	}
+	if !req.attributes["json"] && len(req.args) == 1 && strings.ToUpper(req.args[0]) == "NONE" {
+		if flTimeout.Value != "" {
+			return nil, errors.New("STOPHOOK NONE takes no options")
+		}
+		return cmd, nil
+	}
+
+	cmd.ShellDependantCmdLine = parseShellDependentCommand(req, true)
+	if len(cmd.CmdLine) == 0 {
+		return nil, errAtLeastOneArgument("STOPHOOK")
+	}
+	timeout, err := parseOptInterval(flTimeout)
+	if err != nil {
+		return nil, err
+	}
+	cmd.Timeout = timeout
+	return cmd, nil
+}
+
 func parseArg(req parseRequest) (*ArgCommand, error) {
 	if len(req.args) != 1 {
 		return nil, errExactlyOneArgument("ARG")
@@ -3,7 +3,10 @@ package instructions
 import (
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/builder/dockerfile/command"
 	"github.com/docker/docker/builder/dockerfile/parser"
 	"github.com/docker/docker/internal/testutil"
@@ -33,6 +36,7 @@ func TestCommandsAtLeastOneArgument(t *testing.T) {
 		"LABEL",
 		"ONBUILD",
 		"HEALTHCHECK",
+		"STOPHOOK",
 		"EXPOSE",
 		"VOLUME",
 	}
@@ -127,6 +131,151 @@ func TestHealthCheckCmd(t *testing.T) {
 	assert.Equal(t, expected, hc.Health.Test)
 }
 
+func TestHealthCheckTypes(t *testing.T) {
+	cases := []struct {
+		dockerfile string
+		expected   []string
+	}{
+		{
+			dockerfile: "HEALTHCHECK --type=cmd CMD [\"/bin/check\"]",
+			expected:   []string{"CMD", "/bin/check"},
+		},
+		{
+			dockerfile: "HEALTHCHECK --type=http http://localhost:8080/health",
+			expected:   []string{"HTTP", "http://localhost:8080/health"},
+		},
+		{
+			dockerfile: "HEALTHCHECK --type=http --status=200-299 http://localhost/",
+			expected:   []string{"HTTP", "http://localhost/", "200-299"},
+		},
+		{
+			dockerfile: "HEALTHCHECK --type=http --body=ok --interval=5s http://localhost/",
+			expected:   []string{"HTTP", "http://localhost/", "", "ok"},
+		},
+		{
+			dockerfile: "HEALTHCHECK --type=tcp 6379",
+			expected:   []string{"TCP", "6379"},
+		},
+	}
+	for _, c := range cases {
+		ast, err := parser.Parse(strings.NewReader(c.dockerfile))
+		require.NoError(t, err)
+		cmd, err := ParseInstruction(ast.AST.Children[0])
+		require.NoError(t, err, c.dockerfile)
+		hc, ok := cmd.(*HealthCheckCommand)
+		require.True(t, ok)
+		assert.Equal(t, c.expected, []string(hc.Health.Test), c.dockerfile)
+	}
+}
+
+func TestStopSignalEscalation(t *testing.T) {
+	ast, err := parser.Parse(strings.NewReader("STOPSIGNAL SIGTERM 10s SIGINT 5s SIGQUIT"))
+	require.NoError(t, err)
+	cmd, err := ParseInstruction(ast.AST.Children[0])
+	require.NoError(t, err)
+	ss, ok := cmd.(*StopSignalCommand)
+	require.True(t, ok)
+	assert.Equal(t, "SIGTERM", ss.Signal)
+	expected := []container.StopStage{
+		{Signal: "SIGINT", Delay: 10 * time.Second},
+		{Signal: "SIGQUIT", Delay: 5 * time.Second},
+	}
+	assert.Equal(t, expected, ss.Escalation)
+
+	for dockerfile, expectedError := range map[string]string{
+		"STOPSIGNAL SIGTERM 10s":        "STOPSIGNAL requires a signal after each delay",
+		"STOPSIGNAL SIGTERM ten SIGINT": "invalid delay \"ten\" in STOPSIGNAL",
+		"STOPSIGNAL SIGTERM -1s SIGINT": "Delay \"-1s\" in STOPSIGNAL cannot be negative",
+	} {
+		ast, err := parser.Parse(strings.NewReader(dockerfile))
+		require.NoError(t, err)
+		_, err = ParseInstruction(ast.AST.Children[0])
+		require.Error(t, err, dockerfile)
+		assert.Contains(t, err.Error(), expectedError, dockerfile)
+	}
+}
+
+func TestStopHook(t *testing.T) {
+	cases := []struct {
+		dockerfile string
+		expected   ShellDependantCmdLine
+		timeout    time.Duration
+	}{
+		{
+			dockerfile: "STOPHOOK NONE",
+		},
+		{
+			dockerfile: "STOPHOOK /bin/drain --wait",
+			expected:   ShellDependantCmdLine{CmdLine: strslice.StrSlice{"/bin/drain --wait"}, PrependShell: true},
+		},
+		{
+			dockerfile: "STOPHOOK --timeout=1m [\"/bin/drain\", \"--wait\"]",
+			expected:   ShellDependantCmdLine{CmdLine: strslice.StrSlice{"/bin/drain", "--wait"}},
+			timeout:    time.Minute,
+		},
+	}
+	for _, c := range cases {
+		ast, err := parser.Parse(strings.NewReader(c.dockerfile))
+		require.NoError(t, err)
+		cmd, err := ParseInstruction(ast.AST.Children[0])
+		require.NoError(t, err, c.dockerfile)
+		sh, ok := cmd.(*StopHookCommand)
+		require.True(t, ok)
+		assert.Equal(t, c.expected, sh.ShellDependantCmdLine, c.dockerfile)
+		assert.Equal(t, c.timeout, sh.Timeout, c.dockerfile)
+	}
+
+	ast, err := parser.Parse(strings.NewReader("STOPHOOK --timeout=1m NONE"))
+	require.NoError(t, err)
+	_, err = ParseInstruction(ast.AST.Children[0])
+	assert.EqualError(t, err, "STOPHOOK NONE takes no options")
+}
+
+func TestHealthCheckTypeErrors(t *testing.T) {
+	cases := []struct {
+		name          string
+		dockerfile    string
+		expectedError string
+	}{
+		{
+			name:          "HEALTHCHECK unknown type",
+			dockerfile:    "HEALTHCHECK --type=grpc localhost:50051",
+			expectedError: "Unknown --type \"grpc\" in HEALTHCHECK",
+		},
+		{
+			name:          "HEALTHCHECK http invalid URL",
+			dockerfile:    "HEALTHCHECK --type=http localhost:8080",
+			expectedError: "Invalid URL \"localhost:8080\" in HEALTHCHECK --type=http",
+		},
+		{
+			name:          "HEALTHCHECK http host name",
+			dockerfile:    "HEALTHCHECK --type=http http://db:8080/health",
+			expectedError: "the host must be localhost or an IP address",
+		},
+		{
+			name:          "HEALTHCHECK http invalid status",
+			dockerfile:    "HEALTHCHECK --type=http --status=2xx http://localhost/",
+			expectedError: "Invalid --status \"2xx\" in HEALTHCHECK",
+		},
+		{
+			name:          "HEALTHCHECK tcp invalid port",
+			dockerfile:    "HEALTHCHECK --type=tcp 70000",
+			expectedError: "Invalid port \"70000\" in HEALTHCHECK --type=tcp",
+		},
+		{
+			name:          "HEALTHCHECK status without http",
+			dockerfile:    "HEALTHCHECK --status=200 CMD true",
+			expectedError: "--status and --body are only supported by HEALTHCHECK --type=http",
+		},
+	}
+	for _, c := range cases {
+		ast, err := parser.Parse(strings.NewReader(c.dockerfile))
+		require.NoError(t, err)
+		_, err = ParseInstruction(ast.AST.Children[0])
+		testutil.ErrorContains(t, err, c.expectedError)
+	}
+}
+
 func TestParseOptInterval(t *testing.T) {
 	flInterval := &Flag{
 		name:     "interval",
@@ -203,6 +203,7 @@ func init() {
 		command.Onbuild:     parseSubCommand,
 		command.Run:         parseMaybeJSON,
 		command.Shell:       parseMaybeJSON,
+		command.StopHook:    parseMaybeJSON,
 		command.StopSignal:  parseString,
 		command.User:        parseString,
 		command.Volume:      parseMaybeJSONToList,
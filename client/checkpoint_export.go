@@ -0,0 +1,28 @@
+package client
+
+import (
+	"io"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"golang.org/x/net/context"
+)
+
+// CheckpointExport retrieves a checkpoint of the given container, along with
+// the changes to its filesystem, as a tar archive. It's up to the caller to
+// close the stream.
+func (cli *Client) CheckpointExport(ctx context.Context, container string, options types.CheckpointExportOptions) (io.ReadCloser, error) {
+	if err := cli.NewVersionError("1.33", "checkpoint export"); err != nil {
+		return nil, err
+	}
+	query := url.Values{}
+	if options.CheckpointDir != "" {
+		query.Set("dir", options.CheckpointDir)
+	}
+
+	resp, err := cli.get(ctx, "/containers/"+container+"/checkpoints/"+options.CheckpointID+"/export", query, nil)
+	if err != nil {
+		return nil, wrapResponseError(err, resp, "container", container)
+	}
+	return resp.body, nil
+}
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"golang.org/x/net/context"
+)
+
+func TestCheckpointExportError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.CheckpointExport(context.Background(), "container_id", types.CheckpointExportOptions{CheckpointID: "cp"})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestCheckpointExport(t *testing.T) {
+	expectedURL := "/containers/container_id/checkpoints/cp/export"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if dir := req.URL.Query().Get("dir"); dir != "/checkpoints" {
+				return nil, fmt.Errorf("dir not set in URL query properly. Expected '/checkpoints', got %s", dir)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("archive"))),
+			}, nil
+		}),
+	}
+	body, err := client.CheckpointExport(context.Background(), "container_id", types.CheckpointExportOptions{
+		CheckpointID:  "cp",
+		CheckpointDir: "/checkpoints",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	b, err := ioutil.ReadAll(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "archive" {
+		t.Fatalf("expected the archive to be returned, got %q", b)
+	}
+}
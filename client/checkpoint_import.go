@@ -0,0 +1,47 @@
+package client
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"golang.org/x/net/context"
+)
+
+// CheckpointImport creates a container from an archive returned by
+// CheckpointExport, and starts it from the checkpoint in the archive.
+func (cli *Client) CheckpointImport(ctx context.Context, input io.Reader, options types.CheckpointImportOptions) (container.ContainerCreateCreatedBody, error) {
+	var response container.ContainerCreateCreatedBody
+	if err := cli.NewVersionError("1.33", "checkpoint import"); err != nil {
+		return response, err
+	}
+	query := url.Values{}
+	if options.Name != "" {
+		query.Set("name", options.Name)
+	}
+	if options.HostConfig != nil {
+		hostConfigJSON, err := json.Marshal(options.HostConfig)
+		if err != nil {
+			return response, err
+		}
+		query.Set("hostConfig", string(hostConfigJSON))
+	}
+	if options.NetworkingConfig != nil {
+		networkingConfigJSON, err := json.Marshal(options.NetworkingConfig)
+		if err != nil {
+			return response, err
+		}
+		query.Set("networkingConfig", string(networkingConfigJSON))
+	}
+
+	headers := map[string][]string{"Content-Type": {"application/x-tar"}}
+	resp, err := cli.postRaw(ctx, "/checkpoints/import", query, input, headers)
+	if err != nil {
+		return response, err
+	}
+	err = json.NewDecoder(resp.body).Decode(&response)
+	ensureReaderClosed(resp)
+	return response, err
+}
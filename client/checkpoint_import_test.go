@@ -0,0 +1,74 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"golang.org/x/net/context"
+)
+
+func TestCheckpointImportError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.CheckpointImport(context.Background(), strings.NewReader("archive"), types.CheckpointImportOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestCheckpointImport(t *testing.T) {
+	expectedURL := "/checkpoints/import"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if name := req.URL.Query().Get("name"); name != "restored" {
+				return nil, fmt.Errorf("name not set in URL query properly. Expected 'restored', got %s", name)
+			}
+			var hostConfig container.HostConfig
+			if err := json.Unmarshal([]byte(req.URL.Query().Get("hostConfig")), &hostConfig); err != nil || hostConfig.Memory != 1024 {
+				return nil, fmt.Errorf("host config not set in URL query properly, got %q: %v", req.URL.Query().Get("hostConfig"), err)
+			}
+			if networkingConfig := req.URL.Query().Get("networkingConfig"); networkingConfig != "" {
+				return nil, fmt.Errorf("expected no networking config, got %s", networkingConfig)
+			}
+			if contentType := req.Header.Get("Content-Type"); contentType != "application/x-tar" {
+				return nil, fmt.Errorf("expected content type application/x-tar, got %s", contentType)
+			}
+			b, err := ioutil.ReadAll(req.Body)
+			if err != nil {
+				return nil, err
+			}
+			if string(b) != "archive" {
+				return nil, fmt.Errorf("expected the archive to be sent, got %q", b)
+			}
+			content, err := json.Marshal(container.ContainerCreateCreatedBody{ID: "container_id"})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusCreated,
+				Body:       ioutil.NopCloser(bytes.NewReader(content)),
+			}, nil
+		}),
+	}
+	created, err := client.CheckpointImport(context.Background(), strings.NewReader("archive"), types.CheckpointImportOptions{
+		Name:       "restored",
+		HostConfig: &container.HostConfig{Resources: container.Resources{Memory: 1024}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != "container_id" {
+		t.Fatalf("expected container_id, got %s", created.ID)
+	}
+}
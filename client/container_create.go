@@ -26,6 +26,14 @@ func (cli *Client) ContainerCreate(ctx context.Context, config *container.Config
 		return response, err
 	}
 
+	if err := cli.NewVersionError("1.33", "container dependencies"); hostConfig != nil && len(hostConfig.DependsOn) > 0 && err != nil {
+		return response, err
+	}
+
+	if err := cli.NewVersionError("1.33", "stop hooks and stop escalation"); config != nil && (config.StopHook != nil || len(config.StopEscalation) > 0) && err != nil {
+		return response, err
+	}
+
 	// When using API 1.24 and under, the client is responsible for removing the container
 	if hostConfig != nil && versions.LessThan(cli.ClientVersion(), "1.25") {
 		hostConfig.AutoRemove = false
@@ -116,3 +116,31 @@ func TestContainerCreateAutoRemove(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestContainerCreateDependsOnVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	hostConfig := &container.HostConfig{
+		DependsOn: []container.Dependency{{Container: "db", Condition: container.DependencyConditionHealthy}},
+	}
+	_, err := client.ContainerCreate(context.Background(), nil, hostConfig, nil, "app")
+	if err == nil || !strings.Contains(err.Error(), "container dependencies") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestContainerCreateStopHookVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	config := &container.Config{
+		StopHook: &container.StopHookConfig{Cmd: []string{"/drain"}},
+	}
+	_, err := client.ContainerCreate(context.Background(), config, nil, nil, "app")
+	if err == nil || !strings.Contains(err.Error(), "stop hooks and stop escalation") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
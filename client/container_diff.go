@@ -4,15 +4,42 @@ import (
 	"encoding/json"
 	"net/url"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"golang.org/x/net/context"
 )
 
 // ContainerDiff shows differences in a container filesystem since it was started.
 func (cli *Client) ContainerDiff(ctx context.Context, containerID string) ([]container.ContainerChangeResponseItem, error) {
+	return cli.ContainerDiffWithOptions(ctx, containerID, types.ContainerDiffOptions{})
+}
+
+// ContainerDiffWithOptions shows differences in a container filesystem, against
+// the image it was created from or another image, and optionally the state of
+// the changed files.
+func (cli *Client) ContainerDiffWithOptions(ctx context.Context, containerID string, options types.ContainerDiffOptions) ([]container.ContainerChangeResponseItem, error) {
 	var changes []container.ContainerChangeResponseItem
 
-	serverResp, err := cli.get(ctx, "/containers/"+containerID+"/changes", url.Values{}, nil)
+	query := url.Values{}
+	if options.Image != "" || len(options.Paths) > 0 || options.Details || options.Digest {
+		if err := cli.NewVersionError("1.33", "diff options"); err != nil {
+			return changes, err
+		}
+	}
+	if options.Image != "" {
+		query.Set("image", options.Image)
+	}
+	for _, p := range options.Paths {
+		query.Add("path", p)
+	}
+	if options.Details {
+		query.Set("details", "1")
+	}
+	if options.Digest {
+		query.Set("digest", "1")
+	}
+
+	serverResp, err := cli.get(ctx, "/containers/"+containerID+"/changes", query, nil)
 	if err != nil {
 		return changes, err
 	}
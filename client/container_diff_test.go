@@ -9,6 +9,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/api/types/container"
 	"golang.org/x/net/context"
 )
@@ -59,3 +60,68 @@ func TestContainerDiff(t *testing.T) {
 		t.Fatalf("expected an array of 2 changes, got %v", changes)
 	}
 }
+
+func TestContainerDiffWithOptions(t *testing.T) {
+	expectedURL := "/containers/container_id/changes"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			query := req.URL.Query()
+			if image := query.Get("image"); image != "busybox" {
+				return nil, fmt.Errorf("image not set in URL query properly. Expected 'busybox', got %s", image)
+			}
+			if paths := query["path"]; len(paths) != 2 || paths[0] != "/etc" || paths[1] != "*.log" {
+				return nil, fmt.Errorf("path not set in URL query properly. Expected [/etc *.log], got %v", paths)
+			}
+			if digest := query.Get("digest"); digest != "1" {
+				return nil, fmt.Errorf("digest not set in URL query properly. Expected '1', got %s", digest)
+			}
+			if details := query.Get("details"); details != "" {
+				return nil, fmt.Errorf("details not expected in URL query, got %s", details)
+			}
+			b, err := json.Marshal([]container.ContainerChangeResponseItem{
+				{
+					Kind: 1,
+					Path: "/etc/hosts",
+					Details: &container.ContainerChangeResponseItemDetails{
+						Size:   42,
+						Mode:   0644,
+						Digest: "sha256:5891b5b522d5df086d0ff0b110fbd9d21bb4fc7163af34d08286a2e846f6be03",
+					},
+				},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	changes, err := client.ContainerDiffWithOptions(context.Background(), "container_id", types.ContainerDiffOptions{
+		Image:  "busybox",
+		Paths:  []string{"/etc", "*.log"},
+		Digest: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Details == nil || changes[0].Details.Size != 42 {
+		t.Fatalf("expected a change with details, got %v", changes)
+	}
+}
+
+func TestContainerDiffWithOptionsVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ContainerDiffWithOptions(context.Background(), "container_id", types.ContainerDiffOptions{Details: true})
+	if err == nil || !strings.Contains(err.Error(), "diff options") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
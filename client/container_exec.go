@@ -2,6 +2,7 @@ package client
 
 import (
 	"encoding/json"
+	"net/url"
 
 	"github.com/docker/docker/api/types"
 	"golang.org/x/net/context"
@@ -14,6 +15,9 @@ func (cli *Client) ContainerExecCreate(ctx context.Context, container string, co
 	if err := cli.NewVersionError("1.25", "env"); len(config.Env) != 0 && err != nil {
 		return response, err
 	}
+	if err := cli.NewVersionError("1.33", "timeout"); config.Timeout != 0 && err != nil {
+		return response, err
+	}
 
 	resp, err := cli.post(ctx, "/containers/"+container+"/exec", nil, config, nil)
 	if err != nil {
@@ -52,3 +56,33 @@ func (cli *Client) ContainerExecInspect(ctx context.Context, execID string) (typ
 	ensureReaderClosed(resp)
 	return response, err
 }
+
+// ContainerExecList returns the exec processes of a container, including the
+// ones which have exited recently.
+func (cli *Client) ContainerExecList(ctx context.Context, container string) ([]types.ContainerExecInspect, error) {
+	var response []types.ContainerExecInspect
+	if err := cli.NewVersionError("1.33", "exec list"); err != nil {
+		return response, err
+	}
+	resp, err := cli.get(ctx, "/containers/"+container+"/execs", nil, nil)
+	if err != nil {
+		return response, err
+	}
+
+	err = json.NewDecoder(resp.body).Decode(&response)
+	ensureReaderClosed(resp)
+	return response, err
+}
+
+// ContainerExecKill sends a signal to a running exec process.
+func (cli *Client) ContainerExecKill(ctx context.Context, execID, signal string) error {
+	if err := cli.NewVersionError("1.33", "exec kill"); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("signal", signal)
+
+	resp, err := cli.post(ctx, "/exec/"+execID+"/kill", query, nil, nil)
+	ensureReaderClosed(resp)
+	return err
+}
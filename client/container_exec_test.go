@@ -155,3 +155,72 @@ func TestContainerExecInspect(t *testing.T) {
 		t.Fatalf("expected ContainerID `container_id`, got %s", inspect.ContainerID)
 	}
 }
+
+func TestContainerExecList(t *testing.T) {
+	expectedURL := "/containers/container_id/execs"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "GET" {
+				return nil, fmt.Errorf("expected GET method, got %s", req.Method)
+			}
+			b, err := json.Marshal([]map[string]interface{}{
+				{"ExecID": "exec_1", "ContainerID": "container_id", "Running": true},
+				{"ExecID": "exec_2", "ContainerID": "container_id", "ExitCode": 1},
+			})
+			if err != nil {
+				return nil, err
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader(b)),
+			}, nil
+		}),
+	}
+
+	execs, err := client.ContainerExecList(context.Background(), "container_id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(execs) != 2 || execs[0].ExecID != "exec_1" || !execs[0].Running || execs[1].ExitCode != 1 {
+		t.Fatalf("unexpected execs: %+v", execs)
+	}
+}
+
+func TestContainerExecKill(t *testing.T) {
+	expectedURL := "/exec/exec_id/kill"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			if signal := req.URL.Query().Get("signal"); signal != "SIGTERM" {
+				return nil, fmt.Errorf("signal not set in URL query properly. Expected 'SIGTERM', got %s", signal)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+
+	if err := client.ContainerExecKill(context.Background(), "exec_id", "SIGTERM"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestContainerExecKillVersion(t *testing.T) {
+	client := &Client{
+		version: "1.32",
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	err := client.ContainerExecKill(context.Background(), "exec_id", "SIGTERM")
+	if err == nil || !strings.Contains(err.Error(), "exec kill") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
@@ -0,0 +1,32 @@
+package client
+
+import (
+	"net/url"
+
+	"github.com/docker/docker/api/types/mount"
+	"golang.org/x/net/context"
+)
+
+// ContainerMountAdd mounts a volume or a host path into a running container.
+func (cli *Client) ContainerMountAdd(ctx context.Context, containerID string, m mount.Mount) error {
+	if err := cli.NewVersionError("1.33", "container mount add"); err != nil {
+		return err
+	}
+	resp, err := cli.post(ctx, "/containers/"+containerID+"/mounts", nil, m, nil)
+	ensureReaderClosed(resp)
+	return wrapResponseError(err, resp, "container", containerID)
+}
+
+// ContainerMountRemove unmounts the volume or host path mounted at target
+// from a running container.
+func (cli *Client) ContainerMountRemove(ctx context.Context, containerID, target string) error {
+	if err := cli.NewVersionError("1.33", "container mount remove"); err != nil {
+		return err
+	}
+	query := url.Values{}
+	query.Set("target", target)
+
+	resp, err := cli.delete(ctx, "/containers/"+containerID+"/mounts", query, nil)
+	ensureReaderClosed(resp)
+	return wrapResponseError(err, resp, "container", containerID)
+}
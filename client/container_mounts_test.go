@@ -0,0 +1,90 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types/mount"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/context"
+)
+
+func TestContainerMountAddError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	err := client.ContainerMountAdd(context.Background(), "container_id", mount.Mount{})
+	assert.EqualError(t, err, "Error response from daemon: Server error")
+}
+
+func TestContainerMountAdd(t *testing.T) {
+	expectedURL := "/containers/container_id/mounts"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			var m mount.Mount
+			if err := json.NewDecoder(req.Body).Decode(&m); err != nil {
+				return nil, err
+			}
+			if m.Type != mount.TypeVolume || m.Source != "data" || m.Target != "/data" {
+				return nil, fmt.Errorf("unexpected mount: %+v", m)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+
+	err := client.ContainerMountAdd(context.Background(), "container_id", mount.Mount{
+		Type:   mount.TypeVolume,
+		Source: "data",
+		Target: "/data",
+	})
+	assert.NoError(t, err)
+}
+
+func TestContainerMountRemove(t *testing.T) {
+	expectedURL := "/containers/container_id/mounts"
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "DELETE" {
+				return nil, fmt.Errorf("expected DELETE method, got %s", req.Method)
+			}
+			if target := req.URL.Query().Get("target"); target != "/data" {
+				return nil, fmt.Errorf("target not set in URL query properly. Expected '/data', got %s", target)
+			}
+			return &http.Response{
+				StatusCode: http.StatusNoContent,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte(""))),
+			}, nil
+		}),
+	}
+
+	err := client.ContainerMountRemove(context.Background(), "container_id", "/data")
+	assert.NoError(t, err)
+}
+
+func TestContainerMountRemoveVersion(t *testing.T) {
+	client := &Client{
+		version: "1.32",
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	err := client.ContainerMountRemove(context.Background(), "container_id", "/data")
+	if err == nil || !strings.Contains(err.Error(), "container mount remove") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
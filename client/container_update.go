@@ -10,6 +10,15 @@ import (
 // ContainerUpdate updates resources of a container
 func (cli *Client) ContainerUpdate(ctx context.Context, containerID string, updateConfig container.UpdateConfig) (container.ContainerUpdateOKBody, error) {
 	var response container.ContainerUpdateOKBody
+	if err := cli.NewVersionError("1.33", "labels"); updateConfig.Labels != nil && err != nil {
+		return response, err
+	}
+	if err := cli.NewVersionError("1.33", "healthcheck"); updateConfig.Healthcheck != nil && err != nil {
+		return response, err
+	}
+	if err := cli.NewVersionError("1.33", "log options"); updateConfig.LogOptions != nil && err != nil {
+		return response, err
+	}
 	serverResp, err := cli.post(ctx, "/containers/"+containerID+"/update", nil, updateConfig, nil)
 	if err != nil {
 		return response, err
@@ -56,3 +56,16 @@ func TestContainerUpdate(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestContainerUpdateHealthcheckVersion(t *testing.T) {
+	client := &Client{
+		version: "1.32",
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ContainerUpdate(context.Background(), "container_id", container.UpdateConfig{
+		Healthcheck: &container.HealthConfig{Test: []string{"CMD", "true"}},
+	})
+	if err == nil || !strings.Contains(err.Error(), "healthcheck") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
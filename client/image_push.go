@@ -35,6 +35,18 @@ func (cli *Client) ImagePush(ctx context.Context, image string, options types.Im
 
 	query := url.Values{}
 	query.Set("tag", tag)
+	if options.Format != "" {
+		if err := cli.NewVersionError("1.33", "image push format"); err != nil {
+			return nil, err
+		}
+		query.Set("format", options.Format)
+	}
+	if options.Compression != "" {
+		if err := cli.NewVersionError("1.33", "image push compression"); err != nil {
+			return nil, err
+		}
+		query.Set("compression", options.Compression)
+	}
 
 	resp, err := cli.tryImagePush(ctx, name, query, options.RegistryAuth)
 	if resp.statusCode == http.StatusUnauthorized && options.PrivilegeFunc != nil {
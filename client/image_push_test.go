@@ -178,3 +178,63 @@ func TestImagePushWithoutErrors(t *testing.T) {
 		}
 	}
 }
+
+func TestImagePushFormat(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if format := req.URL.Query().Get("format"); format != "oci" {
+				return nil, fmt.Errorf("format not set in URL query properly. Expected 'oci', got %s", format)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("hello world"))),
+			}, nil
+		}),
+	}
+	resp, err := client.ImagePush(context.Background(), "myimage:tag", types.ImagePushOptions{Format: "oci"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
+
+func TestImagePushFormatVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ImagePush(context.Background(), "myimage:tag", types.ImagePushOptions{Format: "oci"})
+	if err == nil || !strings.Contains(err.Error(), "image push format") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestImagePushCompression(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if compression := req.URL.Query().Get("compression"); compression != "zstd" {
+				return nil, fmt.Errorf("compression not set in URL query properly. Expected 'zstd', got %s", compression)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("hello world"))),
+			}, nil
+		}),
+	}
+	resp, err := client.ImagePush(context.Background(), "myimage:tag", types.ImagePushOptions{Compression: "zstd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Close()
+}
+
+func TestImagePushCompressionVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ImagePush(context.Background(), "myimage:tag", types.ImagePushOptions{Compression: "zstd"})
+	if err == nil || !strings.Contains(err.Error(), "image push compression") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/docker/docker/api/types"
 	"golang.org/x/net/context"
 
 	"strings"
@@ -56,3 +57,47 @@ func TestImageSave(t *testing.T) {
 		t.Fatalf("expected response to contain 'response', got %s", string(response))
 	}
 }
+
+func TestImageSaveWithOptions(t *testing.T) {
+	client := &Client{
+		client: newMockClient(func(r *http.Request) (*http.Response, error) {
+			if format := r.URL.Query().Get("format"); format != "oci" {
+				return nil, fmt.Errorf("format not set in URL query properly. Expected 'oci', got %s", format)
+			}
+			if compression := r.URL.Query().Get("compression"); compression != "zstd" {
+				return nil, fmt.Errorf("compression not set in URL query properly. Expected 'zstd', got %s", compression)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("response"))),
+			}, nil
+		}),
+	}
+	saveResponse, err := client.ImageSaveWithOptions(context.Background(), []string{"image_id1"}, types.ImageSaveOptions{Format: "oci", Compression: "zstd"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	saveResponse.Close()
+}
+
+func TestImageSaveWithOptionsVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ImageSaveWithOptions(context.Background(), []string{"image_id1"}, types.ImageSaveOptions{Format: "oci"})
+	if err == nil || !strings.Contains(err.Error(), "image save format") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestImageSaveWithOptionsCompressionVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ImageSaveWithOptions(context.Background(), []string{"image_id1"}, types.ImageSaveOptions{Compression: "zstd"})
+	if err == nil || !strings.Contains(err.Error(), "image save compression") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
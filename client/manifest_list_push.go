@@ -0,0 +1,60 @@
+package client
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"golang.org/x/net/context"
+)
+
+// ManifestListPush requests the docker host to push the given local images
+// to a remote registry, and to tag a manifest list referencing them as ref.
+// It executes the privileged function if the operation is unauthorized
+// and it tries one more time.
+// It's up to the caller to handle the io.ReadCloser and close it properly.
+func (cli *Client) ManifestListPush(ctx context.Context, ref string, manifests []registrytypes.ManifestListEntry, options types.ManifestListPushOptions) (io.ReadCloser, error) {
+	if err := cli.NewVersionError("1.33", "manifest list push"); err != nil {
+		return nil, err
+	}
+
+	named, err := reference.ParseNormalizedNamed(ref)
+	if err != nil {
+		return nil, err
+	}
+	if _, isCanonical := named.(reference.Canonical); isCanonical {
+		return nil, errors.New("cannot push a manifest list to a digest reference")
+	}
+
+	name := reference.FamiliarString(reference.TagNameOnly(named))
+	query := url.Values{}
+	if options.Format != "" {
+		query.Set("format", options.Format)
+	}
+	if options.Compression != "" {
+		query.Set("compression", options.Compression)
+	}
+	request := registrytypes.ManifestListPushRequest{Manifests: manifests}
+
+	resp, err := cli.tryManifestListPush(ctx, name, query, request, options.RegistryAuth)
+	if resp.statusCode == http.StatusUnauthorized && options.PrivilegeFunc != nil {
+		newAuthHeader, privilegeErr := options.PrivilegeFunc()
+		if privilegeErr != nil {
+			return nil, privilegeErr
+		}
+		resp, err = cli.tryManifestListPush(ctx, name, query, request, newAuthHeader)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return resp.body, nil
+}
+
+func (cli *Client) tryManifestListPush(ctx context.Context, ref string, query url.Values, request registrytypes.ManifestListPushRequest, registryAuth string) (serverResponse, error) {
+	headers := map[string][]string{"X-Registry-Auth": {registryAuth}}
+	return cli.post(ctx, "/distribution/"+ref+"/push", query, request, headers)
+}
@@ -0,0 +1,111 @@
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+func TestManifestListPushError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "Server error")),
+	}
+	_, err := client.ManifestListPush(context.Background(), "myimage", nil, types.ManifestListPushOptions{})
+	if err == nil || err.Error() != "Error response from daemon: Server error" {
+		t.Fatalf("expected a Server Error, got %v", err)
+	}
+}
+
+func TestManifestListPushReferenceError(t *testing.T) {
+	client := &Client{
+		client: newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+	}
+	_, err := client.ManifestListPush(context.Background(), "repo@sha256:ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff", nil, types.ManifestListPushOptions{})
+	if err == nil || !strings.Contains(err.Error(), "digest reference") {
+		t.Fatalf("expected a digest reference error, got %v", err)
+	}
+}
+
+func TestManifestListPushVersion(t *testing.T) {
+	client := &Client{
+		client:  newMockClient(errorMock(http.StatusInternalServerError, "should not be called")),
+		version: "1.32",
+	}
+	_, err := client.ManifestListPush(context.Background(), "myimage", nil, types.ManifestListPushOptions{})
+	if err == nil || !strings.Contains(err.Error(), "manifest list push") {
+		t.Fatalf("expected a version error, got %v", err)
+	}
+}
+
+func TestManifestListPush(t *testing.T) {
+	expectedURL := "/distribution/myimage:latest/push"
+	manifests := []registrytypes.ManifestListEntry{
+		{Image: "myimage:amd64"},
+		{Image: "myimage:arm", Platform: v1.Platform{Variant: "v7"}},
+	}
+	client := &Client{
+		client: newMockClient(func(req *http.Request) (*http.Response, error) {
+			if !strings.HasPrefix(req.URL.Path, expectedURL) {
+				return nil, fmt.Errorf("Expected URL '%s', got '%s'", expectedURL, req.URL)
+			}
+			if req.Method != "POST" {
+				return nil, fmt.Errorf("expected POST method, got %s", req.Method)
+			}
+			if format := req.URL.Query().Get("format"); format != "oci" {
+				return nil, fmt.Errorf("format not set in URL query properly. Expected 'oci', got %s", format)
+			}
+			if compression := req.URL.Query().Get("compression"); compression != "zstd" {
+				return nil, fmt.Errorf("compression not set in URL query properly. Expected 'zstd', got %s", compression)
+			}
+			auth := req.Header.Get("X-Registry-Auth")
+			if auth == "NotValid" {
+				return &http.Response{
+					StatusCode: http.StatusUnauthorized,
+					Body:       ioutil.NopCloser(bytes.NewReader([]byte("Invalid credentials"))),
+				}, nil
+			}
+			if auth != "IAmValid" {
+				return nil, fmt.Errorf("Invalid auth header : expected %s, got %s", "IAmValid", auth)
+			}
+			var request registrytypes.ManifestListPushRequest
+			if err := json.NewDecoder(req.Body).Decode(&request); err != nil {
+				return nil, err
+			}
+			if len(request.Manifests) != 2 || request.Manifests[1].Image != "myimage:arm" || request.Manifests[1].Platform.Variant != "v7" {
+				return nil, fmt.Errorf("unexpected manifests %+v", request.Manifests)
+			}
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       ioutil.NopCloser(bytes.NewReader([]byte("hello world"))),
+			}, nil
+		}),
+	}
+	privilegeFunc := func() (string, error) {
+		return "IAmValid", nil
+	}
+	resp, err := client.ManifestListPush(context.Background(), "myimage", manifests, types.ManifestListPushOptions{
+		RegistryAuth:  "NotValid",
+		PrivilegeFunc: privilegeFunc,
+		Format:        "oci",
+		Compression:   "zstd",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, err := ioutil.ReadAll(resp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(body) != "hello world" {
+		t.Fatalf("expected 'hello world', got %s", string(body))
+	}
+}
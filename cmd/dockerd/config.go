@@ -56,12 +56,31 @@ func installCommonConfigFlags(conf *config.Config, flags *pflag.FlagSet) {
 	flags.StringVar(&conf.CorsHeaders, "api-cors-header", "", "Set CORS headers in the Engine API")
 	flags.IntVar(&maxConcurrentDownloads, "max-concurrent-downloads", config.DefaultMaxConcurrentDownloads, "Set the max concurrent downloads for each pull")
 	flags.IntVar(&maxConcurrentUploads, "max-concurrent-uploads", config.DefaultMaxConcurrentUploads, "Set the max concurrent uploads for each push")
+	flags.IntVar(&conf.MaxConcurrentDownloadChunks, "max-concurrent-download-chunks", config.DefaultMaxConcurrentDownloadChunks, "Set the max concurrent chunk downloads for each layer")
+	flags.StringVar(&conf.DownloadChunkSize, "download-chunk-size", config.DefaultDownloadChunkSize, "Size of the chunks large layers are downloaded in")
 	flags.IntVar(&conf.ShutdownTimeout, "shutdown-timeout", defaultShutdownTimeout, "Set the default shutdown timeout")
 
 	flags.StringVar(&conf.SwarmDefaultAdvertiseAddr, "swarm-default-advertise-addr", "", "Set default address or interface for swarm advertised address")
 	flags.BoolVar(&conf.Experimental, "experimental", false, "Enable experimental features")
 
 	flags.StringVar(&conf.MetricsAddress, "metrics-addr", "", "Set default address and port to serve the metrics api on")
+	flags.BoolVar(&conf.MetricsContainers, "metrics-containers", false, "Serve per-container metrics on the metrics api")
+	flags.Var(opts.NewNamedListOptsRef("metrics-container-labels", &conf.MetricsContainerLabels, nil), "metrics-container-label", "Container label to add to the per-container metrics")
+
+	flags.BoolVar(&conf.EventsJournalConfig.Enabled, "events-journal", false, "Persist events on disk to serve queries for past events")
+	flags.StringVar(&conf.EventsJournalConfig.MaxSize, "events-journal-max-size", config.DefaultEventsJournalMaxSize, "Maximum size of the events journal")
+	flags.StringVar(&conf.EventsJournalConfig.MaxAge, "events-journal-max-age", config.DefaultEventsJournalMaxAge, "Maximum age of the events kept in the events journal")
+	flags.BoolVar(&conf.ImageGCConfig.Enabled, "image-gc", false, "Delete the least recently used images when the image filesystem usage is high")
+	flags.StringVar(&conf.ImageGCConfig.Interval, "image-gc-interval", config.DefaultImageGCInterval, "Interval between the checks of the image filesystem usage")
+	flags.IntVar(&conf.ImageGCConfig.HighThreshold, "image-gc-high-threshold", config.DefaultImageGCHighThreshold, "Image filesystem usage percentage triggering image garbage collection")
+	flags.IntVar(&conf.ImageGCConfig.LowThreshold, "image-gc-low-threshold", config.DefaultImageGCLowThreshold, "Image filesystem usage percentage image garbage collection frees space down to")
+	flags.StringVar(&conf.ImageGCConfig.MinAge, "image-gc-min-age", config.DefaultImageGCMinAge, "Minimum time since an image was last used before it is garbage collected")
+	flags.Var(opts.NewNamedListOptsRef("image-gc-keep-references", &conf.ImageGCConfig.KeepReferences, nil), "image-gc-keep-reference", "Never garbage collect the images with a reference matching the pattern")
+	flags.Var(opts.NewNamedListOptsRef("image-gc-keep-labels", &conf.ImageGCConfig.KeepLabels, nil), "image-gc-keep-label", "Never garbage collect the images with the label (label or label=value)")
+	flags.Var(config.NewNamedEventSinksOpt("event-sinks", &conf.EventSinks), "event-sink", "Forward events to an external sink")
+	flags.Var(config.NewNamedImagePolicyOpt("image-policy", &conf.ImagePolicy), "image-policy", "Require the images of matching repositories to be signed by one of the keys")
+	flags.StringVar(&conf.TracingExporter, "tracing-exporter", "", "Export traces of API requests (otlp, file)")
+	flags.StringVar(&conf.TracingEndpoint, "tracing-endpoint", "", "URL of the OTLP traces endpoint or path of the traces file")
 
 	flags.StringVar(&conf.NodeGenericResources, "node-generic-resources", "", "user defined resources (e.g. fpga=2;gpu={UUID1,UUID2,UUID3})")
 	flags.IntVar(&conf.NetworkControlPlaneMTU, "network-control-plane-mtu", config.DefaultNetworkMtu, "Network Control plane MTU")
@@ -84,6 +103,7 @@ func installRegistryServiceFlags(options *registry.ServiceOptions, flags *pflag.
 
 	flags.Var(ana, "allow-nondistributable-artifacts", "Allow push of nondistributable artifacts to registry")
 	flags.Var(mirrors, "registry-mirror", "Preferred Docker registry mirror")
+	flags.Var(registry.NewNamedMirrorRulesOpt("registry-mirror-rules", &options.MirrorRules), "registry-mirror-rule", "Preferred mirrors of the repositories matching a pattern")
 	flags.Var(insecureRegistries, "insecure-registry", "Enable insecure registry communication")
 
 	if runtime.GOOS != "windows" {
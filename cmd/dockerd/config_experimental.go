@@ -6,4 +6,5 @@ import (
 )
 
 func attachExperimentalFlags(conf *config.Config, cmd *pflag.FlagSet) {
+	cmd.BoolVar(&conf.LazyPull, "lazy-pull", false, "Defer the download of pulled layers until they are first used (layers are downloaded whole, not per file)")
 }
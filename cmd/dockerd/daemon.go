@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/tls"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -226,6 +227,11 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 		logrus.Warnln("LCOW support is enabled - this feature is incomplete")
 	}
 
+	if err := startTracing(cli.Config.TracingExporter, cli.Config.TracingEndpoint); err != nil {
+		return err
+	}
+	defer stopTracing()
+
 	d, err := daemon.NewDaemon(cli.Config, registryService, containerdRemote, pluginStore)
 	if err != nil {
 		return fmt.Errorf("Error starting daemon: %v", err)
@@ -243,7 +249,11 @@ func (cli *DaemonCli) start(opts *daemonOptions) (err error) {
 		if !d.HasExperimental() {
 			return fmt.Errorf("metrics-addr is only supported when experimental is enabled")
 		}
-		if err := startMetricsServer(cli.Config.MetricsAddress); err != nil {
+		var containerMetrics http.Handler
+		if cli.Config.MetricsContainers {
+			containerMetrics = d.ContainerMetricsHandler(cli.Config.MetricsContainerLabels)
+		}
+		if err := startMetricsServer(cli.Config.MetricsAddress, containerMetrics); err != nil {
 			return err
 		}
 	}
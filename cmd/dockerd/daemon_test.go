@@ -129,7 +129,11 @@ func TestLoadDaemonConfigWithRegistryOptions(t *testing.T) {
 	content := `{
 		"allow-nondistributable-artifacts": ["allow-nondistributable-artifacts.com"],
 		"registry-mirrors": ["https://mirrors.docker.com"],
-		"insecure-registries": ["https://insecure.docker.com"]
+		"insecure-registries": ["https://insecure.docker.com"],
+		"registry-mirror-rules": [{
+			"repository": "ghcr.io/org/*",
+			"mirrors": [{"location": "mirror.local/ghcr/org/*", "username": "user", "password": "pass"}]
+		}]
 	}`
 	tempFile := fs.NewFile(t, "config", fs.WithContent(content))
 	defer tempFile.Remove()
@@ -142,4 +146,6 @@ func TestLoadDaemonConfigWithRegistryOptions(t *testing.T) {
 	assert.Len(t, loadedConfig.AllowNondistributableArtifacts, 1)
 	assert.Len(t, loadedConfig.Mirrors, 1)
 	assert.Len(t, loadedConfig.InsecureRegistries, 1)
+	require.Len(t, loadedConfig.MirrorRules, 1)
+	assert.Equal(t, "user", loadedConfig.MirrorRules[0].Mirrors[0].Username)
 }
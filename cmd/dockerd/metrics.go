@@ -8,7 +8,9 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
-func startMetricsServer(addr string) error {
+// startMetricsServer serves the engine metrics on addr. If containers is not
+// nil, it is served as the per-container metrics endpoint.
+func startMetricsServer(addr string, containers http.Handler) error {
 	if err := allocateDaemonPort(addr); err != nil {
 		return err
 	}
@@ -18,6 +20,9 @@ func startMetricsServer(addr string) error {
 	}
 	mux := http.NewServeMux()
 	mux.Handle("/metrics", metrics.Handler())
+	if containers != nil {
+		mux.Handle("/metrics/containers", containers)
+	}
 	go func() {
 		if err := http.Serve(l, mux); err != nil {
 			logrus.Errorf("serve metrics api: %s", err)
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/docker/docker/pkg/tracing"
+	"github.com/sirupsen/logrus"
+)
+
+// startTracing sets up the export of request traces to endpoint, using the
+// exporter named by typ. Tracing is left disabled if typ is empty.
+func startTracing(typ, endpoint string) error {
+	var (
+		e   tracing.Exporter
+		err error
+	)
+	switch typ {
+	case "":
+		return nil
+	case "otlp":
+		e, err = tracing.NewOTLPExporter(endpoint, "dockerd")
+	case "file":
+		e, err = tracing.NewFileExporter(endpoint)
+	default:
+		err = fmt.Errorf("unknown tracing exporter: %q", typ)
+	}
+	if err != nil {
+		return fmt.Errorf("Error starting tracing: %v", err)
+	}
+	tracing.SetExporter(e)
+	return nil
+}
+
+// stopTracing disables tracing and flushes the spans not exported yet.
+func stopTracing() {
+	if e := tracing.SetExporter(nil); e != nil {
+		if err := e.Close(); err != nil {
+			logrus.Warnf("Error closing tracing exporter: %v", err)
+		}
+	}
+}
@@ -33,9 +33,11 @@ type State struct {
 	StartedAt         time.Time
 	FinishedAt        time.Time
 	Health            *Health
+	TerminationReason *types.TerminationReason `json:",omitempty"`
 
-	waitStop   chan struct{}
-	waitRemove chan struct{}
+	termination termination
+	waitStop    chan struct{}
+	waitRemove  chan struct{}
 }
 
 // StateStatus is used to return container wait results.
@@ -282,6 +284,7 @@ func (s *State) SetRunning(pid int, initial bool) {
 		s.Paused = false
 	}
 	s.ExitCodeValue = 0
+	s.resetTermination()
 	s.Pid = pid
 	if initial {
 		s.StartedAt = time.Now().UTC()
@@ -296,6 +299,7 @@ func (s *State) SetStopped(exitStatus *ExitStatus) {
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
 	s.setFromExitStatus(exitStatus)
+	s.setTerminationReason()
 	close(s.waitStop) // Fire waiters for stop
 	s.waitStop = make(chan struct{})
 }
@@ -311,6 +315,7 @@ func (s *State) SetRestarting(exitStatus *ExitStatus) {
 	s.Pid = 0
 	s.FinishedAt = time.Now().UTC()
 	s.setFromExitStatus(exitStatus)
+	s.setTerminationReason()
 	close(s.waitStop) // Fire waiters for stop
 	s.waitStop = make(chan struct{})
 }
@@ -0,0 +1,155 @@
+package container
+
+import (
+	"bytes"
+	"sync"
+
+	"github.com/docker/docker/api/types"
+)
+
+const (
+	// Number of stderr lines kept in the termination reason of a container.
+	terminationStderrLines = 10
+
+	// Longest stderr line kept in the termination reason. Longer lines are truncated.
+	terminationStderrLineLen = 1024
+)
+
+// termination holds what is known about the termination of the current run
+// of a container, until it exits.
+type termination struct {
+	initiator types.TerminationInitiator
+	signal    int
+	oomEvents int
+	stderr    *tailWriter
+}
+
+// CaptureStderr starts keeping the last lines written by the container to
+// stderr, to report them in its termination reason. It must be called
+// before the container's stdio is initialized, with the container locked.
+func (container *Container) CaptureStderr() {
+	w := newTailWriter(terminationStderrLines)
+	container.termination.stderr = w
+	container.StreamConfig.Stderr().Add(w)
+}
+
+// SetTerminationRequest records that the daemon is about to terminate the
+// container on behalf of initiator by sending it sig. It is called with
+// the container locked.
+func (s *State) SetTerminationRequest(initiator types.TerminationInitiator, sig int) {
+	s.termination.initiator = initiator
+	s.termination.signal = sig
+}
+
+// AddOOMEvent records an OOM event reported for the container's cgroup.
+// It is called with the container locked.
+func (s *State) AddOOMEvent() {
+	s.termination.oomEvents++
+}
+
+// resetTermination forgets the termination details of the previous run.
+func (s *State) resetTermination() {
+	s.TerminationReason = nil
+	s.termination.initiator = ""
+	s.termination.signal = 0
+	s.termination.oomEvents = 0
+}
+
+// setTerminationReason sets the termination reason of the container from
+// the exit status set on the state, and the termination details recorded
+// during the run.
+func (s *State) setTerminationReason() {
+	reason := &types.TerminationReason{
+		OOMEvents: s.termination.oomEvents,
+	}
+	// Shells and container runtimes report a process killed by a signal
+	// with an exit code of 128 + the signal number.
+	if s.ExitCodeValue > 128 && s.ExitCodeValue <= 128+64 {
+		reason.Signal = s.ExitCodeValue - 128
+	}
+	if s.termination.stderr != nil {
+		reason.Stderr = s.termination.stderr.Lines()
+	}
+
+	switch {
+	case s.OOMKilled && reason.Signal == 9 && s.termination.signal != 9:
+		reason.Initiator = types.TerminationOOM
+	case s.termination.initiator != "":
+		reason.Initiator = s.termination.initiator
+	case s.OOMKilled:
+		reason.Initiator = types.TerminationOOM
+	case reason.Signal != 0:
+		reason.Initiator = types.TerminationSignal
+	default:
+		reason.Initiator = types.TerminationExit
+	}
+	s.TerminationReason = reason
+}
+
+// tailWriter is an io.WriteCloser that keeps the last lines written to it.
+type tailWriter struct {
+	mu      sync.Mutex
+	max     int
+	lines   []string
+	partial []byte
+}
+
+func newTailWriter(max int) *tailWriter {
+	return &tailWriter{max: max}
+}
+
+func (w *tailWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		i := bytes.IndexByte(p, '\n')
+		if i < 0 {
+			w.appendPartial(p)
+			break
+		}
+		w.appendPartial(p[:i])
+		w.addLine()
+		p = p[i+1:]
+	}
+	return n, nil
+}
+
+func (w *tailWriter) appendPartial(p []byte) {
+	if room := terminationStderrLineLen - len(w.partial); len(p) > room {
+		p = p[:room]
+	}
+	w.partial = append(w.partial, p...)
+}
+
+func (w *tailWriter) addLine() {
+	w.lines = append(w.lines, string(bytes.TrimSuffix(w.partial, []byte{'\r'})))
+	if len(w.lines) > w.max {
+		w.lines = append(w.lines[:0], w.lines[len(w.lines)-w.max:]...)
+	}
+	w.partial = w.partial[:0]
+}
+
+// Lines returns the last lines written, including the last line if it is
+// not terminated.
+func (w *tailWriter) Lines() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lines := append([]string{}, w.lines...)
+	if len(w.partial) > 0 {
+		lines = append(lines, string(w.partial))
+		if len(lines) > w.max {
+			lines = lines[1:]
+		}
+	}
+	if len(lines) == 0 {
+		return nil
+	}
+	return lines
+}
+
+func (w *tailWriter) Close() error {
+	return nil
+}
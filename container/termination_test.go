@@ -0,0 +1,126 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container/stream"
+)
+
+func TestTailWriter(t *testing.T) {
+	w := newTailWriter(3)
+	if lines := w.Lines(); lines != nil {
+		t.Fatalf("expected no lines, got %q", lines)
+	}
+
+	fmt.Fprint(w, "one\ntwo\r\nthr")
+	fmt.Fprint(w, "ee\nfour\nfive")
+	expected := []string{"three", "four", "five"}
+	if lines := w.Lines(); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected %q, got %q", expected, lines)
+	}
+
+	fmt.Fprint(w, "\n")
+	if lines := w.Lines(); !reflect.DeepEqual(lines, expected) {
+		t.Fatalf("expected %q, got %q", expected, lines)
+	}
+
+	w.Write([]byte(strings.Repeat("x", 2*terminationStderrLineLen) + "\n"))
+	lines := w.Lines()
+	if len(lines) != 3 || len(lines[2]) != terminationStderrLineLen {
+		t.Fatalf("expected the last line to be truncated to %d bytes, got %q", terminationStderrLineLen, lines)
+	}
+}
+
+func TestStateTerminationReason(t *testing.T) {
+	tests := []struct {
+		doc       string
+		initiator types.TerminationInitiator
+		signal    int
+		oomKilled bool
+		exitCode  int
+		expected  types.TerminationReason
+	}{
+		{
+			doc:      "exited on its own",
+			exitCode: 1,
+			expected: types.TerminationReason{Initiator: types.TerminationExit},
+		},
+		{
+			doc:      "killed by another process",
+			exitCode: 128 + 15,
+			expected: types.TerminationReason{Initiator: types.TerminationSignal, Signal: 15},
+		},
+		{
+			doc:       "stopped gracefully",
+			initiator: types.TerminationStop,
+			signal:    15,
+			exitCode:  0,
+			expected:  types.TerminationReason{Initiator: types.TerminationStop},
+		},
+		{
+			doc:       "killed after the stop timeout",
+			initiator: types.TerminationStopTimeout,
+			signal:    9,
+			exitCode:  128 + 9,
+			expected:  types.TerminationReason{Initiator: types.TerminationStopTimeout, Signal: 9},
+		},
+		{
+			doc:       "OOM killed while stopping",
+			initiator: types.TerminationStop,
+			signal:    15,
+			oomKilled: true,
+			exitCode:  128 + 9,
+			expected:  types.TerminationReason{Initiator: types.TerminationOOM, Signal: 9, OOMEvents: 1},
+		},
+		{
+			doc:       "OOM killed",
+			oomKilled: true,
+			exitCode:  128 + 9,
+			expected:  types.TerminationReason{Initiator: types.TerminationOOM, Signal: 9, OOMEvents: 1},
+		},
+	}
+
+	for _, tc := range tests {
+		s := NewState()
+		s.SetRunning(1, true)
+		if tc.initiator != "" {
+			s.SetTerminationRequest(tc.initiator, tc.signal)
+		}
+		if tc.oomKilled {
+			s.AddOOMEvent()
+		}
+		s.SetStopped(&ExitStatus{ExitCode: tc.exitCode})
+		if tc.oomKilled {
+			s.OOMKilled = true
+			s.setTerminationReason()
+		}
+		if s.TerminationReason == nil || !reflect.DeepEqual(*s.TerminationReason, tc.expected) {
+			t.Errorf("%s: expected %+v, got %+v", tc.doc, tc.expected, s.TerminationReason)
+		}
+
+		s.SetRunning(2, true)
+		if s.TerminationReason != nil {
+			t.Errorf("%s: expected the termination reason to be reset on start, got %+v", tc.doc, s.TerminationReason)
+		}
+	}
+}
+
+func TestStateTerminationReasonStderr(t *testing.T) {
+	c := &Container{
+		State:        NewState(),
+		StreamConfig: stream.NewConfig(),
+	}
+	c.CaptureStderr()
+	c.SetRunning(1, true)
+	fmt.Fprint(c.StreamConfig.Stderr(), "panic: oops\ngoroutine 1 [running]:\n")
+	c.SetStopped(&ExitStatus{ExitCode: 2})
+
+	expected := []string{"panic: oops", "goroutine 1 [running]:"}
+	if c.TerminationReason == nil || !reflect.DeepEqual(c.TerminationReason.Stderr, expected) {
+		t.Fatalf("expected stderr %q, got %+v", expected, c.TerminationReason)
+	}
+}
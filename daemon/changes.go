@@ -1,15 +1,26 @@
 package daemon
 
 import (
-	"errors"
+	"os"
+	"path/filepath"
 	"runtime"
+	"strings"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
 	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/containerfs"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
-// ContainerChanges returns a list of container fs changes
-func (daemon *Daemon) ContainerChanges(name string) ([]archive.Change, error) {
+// ContainerChanges returns a list of container fs changes. The changes are
+// reported against options.Image if it is set, and are restricted to
+// options.Paths. If options.Details or options.Digest is set, the state of
+// each changed file in the container is returned along with its change.
+func (daemon *Daemon) ContainerChanges(name string, options types.ContainerDiffOptions) ([]containertypes.ContainerChangeResponseItem, error) {
 	start := time.Now()
 	container, err := daemon.GetContainer(name)
 	if err != nil {
@@ -19,13 +30,118 @@ func (daemon *Daemon) ContainerChanges(name string) ([]archive.Change, error) {
 	if runtime.GOOS == "windows" && container.IsRunning() {
 		return nil, errors.New("Windows does not support diff of a running container")
 	}
+	for _, p := range options.Paths {
+		if _, err := filepath.Match(p, ""); err != nil {
+			return nil, validationError{errors.Wrapf(err, "invalid path filter %q", p)}
+		}
+	}
+	details := options.Details || options.Digest
 
 	container.Lock()
 	defer container.Unlock()
-	c, err := container.RWLayer.Changes()
+
+	var root containerfs.ContainerFS
+	if options.Image != "" || details {
+		root, err = container.RWLayer.Mount(container.GetMountLabel())
+		if err != nil {
+			return nil, err
+		}
+		defer container.RWLayer.Unmount()
+	}
+
+	var c []archive.Change
+	if options.Image == "" {
+		c, err = container.RWLayer.Changes()
+	} else {
+		c, err = daemon.imageChanges(container, root, options.Image)
+	}
 	if err != nil {
 		return nil, err
 	}
+
+	var changes []containertypes.ContainerChangeResponseItem
+	for _, change := range filterChanges(c, options.Paths) {
+		item := containertypes.ContainerChangeResponseItem{
+			Path: change.Path,
+			Kind: uint8(change.Kind),
+		}
+		if details {
+			d, err := archive.StatChange(root.Path(), change, options.Digest)
+			if err != nil && !os.IsNotExist(err) {
+				return nil, err
+			}
+			if err != nil {
+				// The file was removed since the changes were computed.
+				logrus.Debugf("Cannot get details of change %s in container %s: %v", change.String(), container.ID, err)
+			}
+			if d != nil {
+				item.Details = &containertypes.ContainerChangeResponseItemDetails{
+					Size:    d.Size,
+					Mode:    uint32(d.Mode),
+					UID:     int64(d.UID),
+					GID:     int64(d.GID),
+					ModTime: d.ModTime.Format(time.RFC3339Nano),
+					Digest:  d.Digest.String(),
+				}
+			}
+		}
+		changes = append(changes, item)
+	}
 	containerActions.WithValues("changes").UpdateSince(start)
-	return c, nil
+	return changes, nil
+}
+
+// imageChanges returns the changes between the root filesystem of the image
+// refOrID and the mounted root filesystem of the container. Unlike the
+// changes of the container's RW layer, they include the files the daemon
+// sets up in every container, such as /etc/hosts.
+func (daemon *Daemon) imageChanges(container *container.Container, root containerfs.ContainerFS, refOrID string) ([]archive.Change, error) {
+	img, err := daemon.GetImage(refOrID)
+	if err != nil {
+		return nil, err
+	}
+	if img.Platform() != container.Platform {
+		return nil, validationError{errors.Errorf("cannot compare a %s container with a %s image", container.Platform, img.Platform())}
+	}
+
+	l, err := newReleasableLayerForImage(img, daemon.stores[container.Platform].layerStore)
+	if err != nil {
+		return nil, err
+	}
+	defer l.Release()
+	imageRoot, err := l.Mount()
+	if err != nil {
+		return nil, err
+	}
+	return archive.ChangesDirs(root.Path(), imageRoot.Path())
+}
+
+// filterChanges returns the changes to the given paths, to the files under
+// them, and to the paths matching them as patterns. All the changes are
+// returned if no paths are given.
+func filterChanges(changes []archive.Change, paths []string) []archive.Change {
+	if len(paths) == 0 {
+		return changes
+	}
+	var filtered []archive.Change
+	for _, change := range changes {
+		for _, p := range paths {
+			if matchChangePath(change.Path, p) {
+				filtered = append(filtered, change)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+func matchChangePath(path, filter string) bool {
+	// As this runs on the daemon side, file paths are OS specific.
+	sep := string(os.PathSeparator)
+	filter = filepath.Clean(sep + filter)
+	if filter == sep || path == filter || strings.HasPrefix(path, filter+sep) {
+		return true
+	}
+	matched, _ := filepath.Match(filter, path)
+	return matched
 }
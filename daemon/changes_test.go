@@ -0,0 +1,45 @@
+// +build !windows
+
+package daemon
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/docker/pkg/archive"
+)
+
+func TestFilterChanges(t *testing.T) {
+	changes := []archive.Change{
+		{Path: "/etc", Kind: archive.ChangeModify},
+		{Path: "/etc/hosts", Kind: archive.ChangeModify},
+		{Path: "/etc/nginx", Kind: archive.ChangeAdd},
+		{Path: "/etc/nginx/nginx.conf", Kind: archive.ChangeAdd},
+		{Path: "/etcd", Kind: archive.ChangeAdd},
+		{Path: "/var/log/app.log", Kind: archive.ChangeAdd},
+		{Path: "/tmp", Kind: archive.ChangeDelete},
+	}
+
+	tests := []struct {
+		paths    []string
+		expected []string
+	}{
+		{paths: nil, expected: []string{"/etc", "/etc/hosts", "/etc/nginx", "/etc/nginx/nginx.conf", "/etcd", "/var/log/app.log", "/tmp"}},
+		{paths: []string{"/"}, expected: []string{"/etc", "/etc/hosts", "/etc/nginx", "/etc/nginx/nginx.conf", "/etcd", "/var/log/app.log", "/tmp"}},
+		{paths: []string{"etc/nginx/"}, expected: []string{"/etc/nginx", "/etc/nginx/nginx.conf"}},
+		{paths: []string{"/etc"}, expected: []string{"/etc", "/etc/hosts", "/etc/nginx", "/etc/nginx/nginx.conf"}},
+		{paths: []string{"/var/log/*.log", "/tmp"}, expected: []string{"/var/log/app.log", "/tmp"}},
+		{paths: []string{"/etc/*"}, expected: []string{"/etc/hosts", "/etc/nginx"}},
+		{paths: []string{"/usr"}, expected: nil},
+	}
+
+	for _, tc := range tests {
+		var paths []string
+		for _, c := range filterChanges(changes, tc.paths) {
+			paths = append(paths, c.Path)
+		}
+		if !reflect.DeepEqual(paths, tc.expected) {
+			t.Errorf("%v: expected %v, got %v", tc.paths, tc.expected, paths)
+		}
+	}
+}
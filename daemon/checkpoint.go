@@ -1,14 +1,28 @@
 package daemon
 
 import (
+	"archive/tar"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"os"
 	"path/filepath"
+	"runtime"
+	"strings"
 
 	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/names"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/chrootarchive"
+	"github.com/docker/docker/volume"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 var (
@@ -16,6 +30,29 @@ var (
 	validCheckpointNamePattern = names.RestrictedNamePattern
 )
 
+const (
+	// checkpointArchiveVersion is the version of the format of the archives
+	// created by CheckpointExport.
+	checkpointArchiveVersion = 1
+	// checkpointArchiveConfig is the name of the entry holding the
+	// checkpointArchive metadata, which is always the first entry.
+	checkpointArchiveConfig = "config.json"
+	// checkpointArchiveCheckpointDir prefixes the files of the CRIU checkpoint.
+	checkpointArchiveCheckpointDir = "checkpoint/"
+	// checkpointArchiveRWDir prefixes the entries of the diff of the RW layer
+	// of the container.
+	checkpointArchiveRWDir = "rw/"
+)
+
+// checkpointArchive is the metadata of a checkpoint archive.
+type checkpointArchive struct {
+	Version      int
+	CheckpointID string
+	ImageID      image.ID
+	Config       *containertypes.Config
+	HostConfig   *containertypes.HostConfig
+}
+
 // getCheckpointDir verifies checkpoint directory for create,remove, list options and checks if checkpoint already exists
 func getCheckpointDir(checkDir, checkpointID string, ctrName string, ctrID string, ctrCheckpointDir string, create bool) (string, error) {
 	var checkpointDir string
@@ -135,3 +172,368 @@ func (daemon *Daemon) CheckpointList(name string, config types.CheckpointListOpt
 
 	return out, nil
 }
+
+// CheckpointExport writes to out a tar archive holding the checkpoint of the
+// container along with the diff of its RW layer and its configuration, from
+// which CheckpointImport restores the container on another host.
+func (daemon *Daemon) CheckpointExport(name string, config types.CheckpointExportOptions, out io.Writer) error {
+	container, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	checkpointDir, err := getCheckpointDir(config.CheckpointDir, config.CheckpointID, name, container.ID, container.CheckpointDir(), false)
+	if err != nil {
+		return objNotFoundError{"checkpoint", config.CheckpointID}
+	}
+
+	container.Lock()
+	meta, err := json.Marshal(checkpointArchive{
+		Version:      checkpointArchiveVersion,
+		CheckpointID: config.CheckpointID,
+		ImageID:      container.ImageID,
+		Config:       container.Config,
+		HostConfig:   container.HostConfig,
+	})
+	container.Unlock()
+	if err != nil {
+		return err
+	}
+
+	rw, err := daemon.exportContainerRw(container)
+	if err != nil {
+		return err
+	}
+	defer rw.Close()
+
+	tw := tar.NewWriter(out)
+	if err := tw.WriteHeader(&tar.Header{Name: checkpointArchiveConfig, Mode: 0600, Size: int64(len(meta)), Typeflag: tar.TypeReg}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(meta); err != nil {
+		return err
+	}
+	if err := writeCheckpointFiles(tw, filepath.Join(checkpointDir, config.CheckpointID)); err != nil {
+		return errors.Wrapf(err, "error exporting checkpoint %s of container %s", config.CheckpointID, name)
+	}
+	if err := copyTarEntries(tw, tar.NewReader(rw), func(p string) string { return checkpointArchiveRWDir + p }); err != nil {
+		return errors.Wrapf(err, "error exporting the filesystem changes of container %s", name)
+	}
+	return tw.Close()
+}
+
+// writeCheckpointFiles adds the files of the checkpoint in dir to tw.
+func writeCheckpointFiles(tw *tar.Writer, dir string) error {
+	return filepath.Walk(dir, func(path string, fi os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil || rel == "." {
+			return err
+		}
+		if !fi.Mode().IsRegular() && !fi.IsDir() {
+			return nil
+		}
+		hdr, err := tar.FileInfoHeader(fi, "")
+		if err != nil {
+			return err
+		}
+		hdr.Name = checkpointArchiveCheckpointDir + filepath.ToSlash(rel)
+		if fi.IsDir() {
+			hdr.Name += "/"
+		}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+		if fi.IsDir() {
+			return nil
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		_, err = io.Copy(tw, f)
+		return err
+	})
+}
+
+// copyTarEntries copies the entries of tr to tw, renaming them with rename.
+func copyTarEntries(tw *tar.Writer, tr *tar.Reader, rename func(string) string) error {
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if err := copyTarEntry(tw, tr, hdr, rename); err != nil {
+			return err
+		}
+	}
+}
+
+func copyTarEntry(tw *tar.Writer, r io.Reader, hdr *tar.Header, rename func(string) string) error {
+	hdr.Name = rename(hdr.Name)
+	if hdr.Typeflag == tar.TypeLink {
+		hdr.Linkname = rename(hdr.Linkname)
+	}
+	if err := tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	_, err := io.Copy(tw, r)
+	return err
+}
+
+// CheckpointImport creates a container from an archive written by
+// CheckpointExport, and starts it from the checkpoint in the archive.
+// The image the container was created from must be present. The container
+// is created with the host configuration of config if it is set, and with
+// the portable settings of the host configuration in the archive otherwise.
+func (daemon *Daemon) CheckpointImport(ctx context.Context, config types.CheckpointImportOptions, in io.Reader) (created containertypes.ContainerCreateCreatedBody, retErr error) {
+	tr := tar.NewReader(in)
+	meta, err := readCheckpointArchiveConfig(tr)
+	if err != nil {
+		return created, validationError{err}
+	}
+
+	hostConfig := config.HostConfig
+	if hostConfig == nil {
+		hostConfig, err = portableHostConfig(meta.HostConfig)
+		if err != nil {
+			return created, validationError{err}
+		}
+	}
+
+	created, err = daemon.ContainerCreate(ctx, types.ContainerCreateConfig{
+		Name:             config.Name,
+		Config:           meta.Config,
+		HostConfig:       hostConfig,
+		NetworkingConfig: config.NetworkingConfig,
+	})
+	if err != nil {
+		return created, err
+	}
+	defer func() {
+		if retErr != nil {
+			if err := daemon.ContainerRm(created.ID, &types.ContainerRmConfig{ForceRemove: true, RemoveVolume: true}); err != nil {
+				logrus.Warnf("Failed to remove container %s after failed checkpoint import: %v", created.ID, err)
+			}
+		}
+	}()
+
+	container, err := daemon.GetContainer(created.ID)
+	if err != nil {
+		return created, err
+	}
+	if container.ImageID != meta.ImageID {
+		return created, validationError{errors.Errorf("image %s does not match image %s the checkpoint was created from", container.ImageID, meta.ImageID)}
+	}
+
+	if err := daemon.importCheckpointEntries(container, meta.CheckpointID, tr); err != nil {
+		return created, errors.Wrapf(err, "error importing checkpoint %s", meta.CheckpointID)
+	}
+
+	if err := daemon.ContainerStart(ctx, created.ID, nil, meta.CheckpointID, ""); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// portableHostConfig returns the settings of hostConfig, the host
+// configuration of a container exported from another host, which can be
+// used on this host. Settings referring to other containers or to the
+// resources of the other host are dropped. Settings giving the container
+// access to the host are rejected: they must be requested explicitly by
+// the operator of this host.
+func portableHostConfig(hostConfig *containertypes.HostConfig) (*containertypes.HostConfig, error) {
+	if hostConfig == nil {
+		return nil, nil
+	}
+
+	var privileged []string
+	if hostConfig.Privileged {
+		privileged = append(privileged, "privileged mode")
+	}
+	if len(hostConfig.CapAdd) > 0 {
+		privileged = append(privileged, "added capabilities")
+	}
+	if len(hostConfig.Devices) > 0 || len(hostConfig.DeviceCgroupRules) > 0 {
+		privileged = append(privileged, "devices")
+	}
+	for _, opt := range hostConfig.SecurityOpt {
+		if opt != "no-new-privileges" && opt != "no-new-privileges:true" {
+			privileged = append(privileged, "security options")
+			break
+		}
+	}
+	if hostConfig.NetworkMode.IsHost() || hostConfig.IpcMode.IsHost() || hostConfig.PidMode.IsHost() || hostConfig.UTSMode.IsHost() || hostConfig.UsernsMode.IsHost() {
+		privileged = append(privileged, "host namespaces")
+	}
+	parser := volume.NewParser(runtime.GOOS)
+	hostBinds := false
+	for _, bind := range hostConfig.Binds {
+		mp, err := parser.ParseMountRaw(bind, hostConfig.VolumeDriver)
+		if err != nil {
+			return nil, err
+		}
+		hostBinds = hostBinds || mp.Type == mounttypes.TypeBind
+	}
+	for _, m := range hostConfig.Mounts {
+		hostBinds = hostBinds || m.Type == mounttypes.TypeBind
+	}
+	if hostBinds {
+		privileged = append(privileged, "host binds")
+	}
+	if len(privileged) > 0 {
+		return nil, errors.Errorf("the checkpoint archive requests %s: pass a host config to import it", strings.Join(privileged, ", "))
+	}
+
+	portable := *hostConfig
+	portable.ContainerIDFile = ""
+	portable.LogConfig = containertypes.LogConfig{}
+	if portable.NetworkMode.IsContainer() || portable.NetworkMode.IsUserDefined() {
+		portable.NetworkMode = ""
+	}
+	portable.VolumeDriver = ""
+	portable.VolumesFrom = nil
+	portable.DependsOn = nil
+	portable.Links = nil
+	if portable.IpcMode.IsContainer() {
+		portable.IpcMode = ""
+	}
+	if portable.PidMode.IsContainer() {
+		portable.PidMode = ""
+	}
+	portable.Cgroup = ""
+	portable.StorageOpt = nil
+	portable.Runtime = ""
+	portable.CgroupParent = ""
+	portable.CpusetCpus = ""
+	portable.CpusetMems = ""
+	portable.BlkioWeightDevice = nil
+	portable.BlkioDeviceReadBps = nil
+	portable.BlkioDeviceWriteBps = nil
+	portable.BlkioDeviceReadIOps = nil
+	portable.BlkioDeviceWriteIOps = nil
+	return &portable, nil
+}
+
+func readCheckpointArchiveConfig(tr *tar.Reader) (*checkpointArchive, error) {
+	hdr, err := tr.Next()
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid checkpoint archive")
+	}
+	if hdr.Name != checkpointArchiveConfig {
+		return nil, errors.Errorf("invalid checkpoint archive: expected %s as the first entry, got %s", checkpointArchiveConfig, hdr.Name)
+	}
+	var meta checkpointArchive
+	if err := json.NewDecoder(tr).Decode(&meta); err != nil {
+		return nil, errors.Wrap(err, "invalid checkpoint archive")
+	}
+	if meta.Version != checkpointArchiveVersion {
+		return nil, errors.Errorf("unsupported checkpoint archive version %d", meta.Version)
+	}
+	if !validCheckpointNamePattern.MatchString(meta.CheckpointID) {
+		return nil, errors.Errorf("invalid checkpoint ID (%s), only %s are allowed", meta.CheckpointID, validCheckpointNameChars)
+	}
+	if meta.Config == nil {
+		return nil, errors.New("invalid checkpoint archive: missing container configuration")
+	}
+	return &meta, nil
+}
+
+// importCheckpointEntries extracts the checkpoint files read from tr to the
+// checkpoint directory of the container, and applies the diff of its RW
+// layer to the container's filesystem.
+func (daemon *Daemon) importCheckpointEntries(container *container.Container, checkpointID string, tr *tar.Reader) (retErr error) {
+	if err := daemon.Mount(container); err != nil {
+		return err
+	}
+	defer daemon.Unmount(container)
+
+	checkpointDir := filepath.Join(container.CheckpointDir(), checkpointID)
+	if err := os.MkdirAll(checkpointDir, 0700); err != nil {
+		return err
+	}
+
+	// The RW layer entries are streamed to the layer being applied, so they
+	// don't have to be held in memory or on disk.
+	pr, pw := io.Pipe()
+	applied := make(chan error, 1)
+	go func() {
+		_, err := chrootarchive.ApplyUncompressedLayer(container.BaseFS.Path(), pr, &archive.TarOptions{
+			UIDMaps: daemon.idMappings.UIDs(),
+			GIDMaps: daemon.idMappings.GIDs(),
+		})
+		pr.CloseWithError(err)
+		applied <- err
+		close(applied)
+	}()
+	rw := tar.NewWriter(pw)
+	defer func() {
+		if retErr != nil {
+			pw.CloseWithError(retErr)
+			<-applied
+		}
+	}()
+
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		switch {
+		case strings.HasPrefix(hdr.Name, checkpointArchiveRWDir):
+			if hdr.Name == checkpointArchiveRWDir {
+				continue
+			}
+			if err := copyTarEntry(rw, tr, hdr, func(p string) string { return strings.TrimPrefix(p, checkpointArchiveRWDir) }); err != nil {
+				return err
+			}
+		case strings.HasPrefix(hdr.Name, checkpointArchiveCheckpointDir):
+			if err := extractCheckpointFile(checkpointDir, strings.TrimPrefix(hdr.Name, checkpointArchiveCheckpointDir), hdr, tr); err != nil {
+				return err
+			}
+		default:
+			return errors.Errorf("unexpected entry in checkpoint archive: %s", hdr.Name)
+		}
+	}
+
+	if err := rw.Close(); err != nil {
+		return err
+	}
+	pw.Close()
+	return <-applied
+}
+
+// extractCheckpointFile writes the checkpoint file name, read from r, to dir.
+func extractCheckpointFile(dir, name string, hdr *tar.Header, r io.Reader) error {
+	path := filepath.Join(dir, filepath.FromSlash(name))
+	if rel, err := filepath.Rel(dir, path); err != nil || strings.HasPrefix(rel, "..") {
+		return errors.Errorf("invalid checkpoint file name: %s", hdr.Name)
+	}
+	switch hdr.Typeflag {
+	case tar.TypeDir:
+		return os.MkdirAll(path, 0700)
+	case tar.TypeReg, tar.TypeRegA:
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			return err
+		}
+		f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+		if err != nil {
+			return err
+		}
+		if _, err := io.Copy(f, r); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	default:
+		return errors.Errorf("unexpected checkpoint file type for %s", hdr.Name)
+	}
+}
@@ -0,0 +1,193 @@
+package daemon
+
+import (
+	"archive/tar"
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+)
+
+func TestCheckpointArchiveEntries(t *testing.T) {
+	src, err := ioutil.TempDir("", "docker-checkpoint-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(src)
+	if err := os.MkdirAll(filepath.Join(src, "images"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "images", "pages-1.img"), []byte("pages"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(filepath.Join(src, "config.json"), []byte(`{"Name":"cp"}`), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	if err := writeCheckpointFiles(tw, src); err != nil {
+		t.Fatal(err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	dst, err := ioutil.TempDir("", "docker-checkpoint-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dst)
+	tr := tar.NewReader(&buf)
+	for {
+		hdr, err := tr.Next()
+		if err != nil {
+			break
+		}
+		if hdr.Name[:len(checkpointArchiveCheckpointDir)] != checkpointArchiveCheckpointDir {
+			t.Fatalf("expected entry %s to be in %s", hdr.Name, checkpointArchiveCheckpointDir)
+		}
+		if err := extractCheckpointFile(dst, hdr.Name[len(checkpointArchiveCheckpointDir):], hdr, tr); err != nil {
+			t.Fatal(err)
+		}
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dst, "images", "pages-1.img"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(b) != "pages" {
+		t.Fatalf("unexpected checkpoint file content: %q", b)
+	}
+
+	hdr := &tar.Header{Name: "checkpoint/../escape", Typeflag: tar.TypeReg}
+	if err := extractCheckpointFile(dst, "../escape", hdr, bytes.NewReader(nil)); err == nil {
+		t.Fatal("expected an error for a checkpoint file outside of the checkpoint directory")
+	}
+}
+
+func TestCopyTarEntriesRename(t *testing.T) {
+	var src bytes.Buffer
+	tw := tar.NewWriter(&src)
+	for _, hdr := range []*tar.Header{
+		{Name: "file", Typeflag: tar.TypeReg, Size: 4},
+		{Name: "link", Typeflag: tar.TypeLink, Linkname: "file"},
+	} {
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if hdr.Size > 0 {
+			tw.Write([]byte("data"))
+		}
+	}
+	tw.Close()
+
+	var dst bytes.Buffer
+	tw = tar.NewWriter(&dst)
+	if err := copyTarEntries(tw, tar.NewReader(&src), func(p string) string { return checkpointArchiveRWDir + p }); err != nil {
+		t.Fatal(err)
+	}
+	tw.Close()
+
+	tr := tar.NewReader(&dst)
+	hdr, err := tr.Next()
+	if err != nil || hdr.Name != "rw/file" {
+		t.Fatalf("expected rw/file, got %v, %v", hdr, err)
+	}
+	hdr, err = tr.Next()
+	if err != nil || hdr.Name != "rw/link" || hdr.Linkname != "rw/file" {
+		t.Fatalf("expected rw/link linking to rw/file, got %v, %v", hdr, err)
+	}
+}
+
+func TestReadCheckpointArchiveConfig(t *testing.T) {
+	archive := func(name string, meta checkpointArchive) *tar.Reader {
+		b, err := json.Marshal(meta)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		tw := tar.NewWriter(&buf)
+		tw.WriteHeader(&tar.Header{Name: name, Typeflag: tar.TypeReg, Size: int64(len(b))})
+		tw.Write(b)
+		tw.Close()
+		return tar.NewReader(&buf)
+	}
+	valid := checkpointArchive{
+		Version:      checkpointArchiveVersion,
+		CheckpointID: "cp1",
+		Config:       &containertypes.Config{Image: "busybox"},
+	}
+
+	meta, err := readCheckpointArchiveConfig(archive(checkpointArchiveConfig, valid))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if meta.CheckpointID != "cp1" || meta.Config.Image != "busybox" {
+		t.Fatalf("unexpected metadata: %+v", meta)
+	}
+
+	if _, err := readCheckpointArchiveConfig(archive("other.json", valid)); err == nil {
+		t.Fatal("expected an error when the metadata is not the first entry")
+	}
+	invalid := valid
+	invalid.Version = checkpointArchiveVersion + 1
+	if _, err := readCheckpointArchiveConfig(archive(checkpointArchiveConfig, invalid)); err == nil {
+		t.Fatal("expected an error for an unsupported version")
+	}
+	invalid = valid
+	invalid.CheckpointID = "../cp"
+	if _, err := readCheckpointArchiveConfig(archive(checkpointArchiveConfig, invalid)); err == nil {
+		t.Fatal("expected an error for an invalid checkpoint ID")
+	}
+}
+
+func TestPortableHostConfig(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("Unix bind syntax")
+	}
+
+	hostConfig := &containertypes.HostConfig{
+		Binds:         []string{"data:/data"},
+		NetworkMode:   "container:other",
+		RestartPolicy: containertypes.RestartPolicy{Name: "always"},
+		Links:         []string{"/other:/container/other"},
+		Resources: containertypes.Resources{
+			Memory:       1024,
+			CgroupParent: "/custom",
+		},
+	}
+	portable, err := portableHostConfig(hostConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if portable.NetworkMode != "" || portable.CgroupParent != "" || portable.Links != nil {
+		t.Fatalf("expected the host-specific settings to be dropped, got %+v", portable)
+	}
+	if portable.Memory != 1024 || portable.RestartPolicy.Name != "always" || len(portable.Binds) != 1 {
+		t.Fatalf("expected the portable settings to be kept, got %+v", portable)
+	}
+	if hostConfig.CgroupParent != "/custom" {
+		t.Fatal("expected the archive host config to be left unchanged")
+	}
+
+	for _, hostConfig := range []*containertypes.HostConfig{
+		{Privileged: true},
+		{Binds: []string{"/etc:/host/etc"}},
+		{Mounts: []mounttypes.Mount{{Type: mounttypes.TypeBind, Source: "/etc", Target: "/host/etc"}}},
+		{Resources: containertypes.Resources{Devices: []containertypes.DeviceMapping{{PathOnHost: "/dev/kmsg"}}}},
+		{NetworkMode: "host"},
+		{CapAdd: []string{"SYS_ADMIN"}},
+		{SecurityOpt: []string{"seccomp=unconfined"}},
+	} {
+		if _, err := portableHostConfig(hostConfig); err == nil || !strings.Contains(err.Error(), "pass a host config") {
+			t.Fatalf("expected %+v to be rejected, got %v", hostConfig, err)
+		}
+	}
+}
@@ -116,6 +116,12 @@ func merge(userConf, imageConf *containertypes.Config) error {
 
 	if userConf.StopSignal == "" {
 		userConf.StopSignal = imageConf.StopSignal
+		if len(userConf.StopEscalation) == 0 {
+			userConf.StopEscalation = imageConf.StopEscalation
+		}
+	}
+	if userConf.StopHook == nil {
+		userConf.StopHook = imageConf.StopHook
 	}
 	return nil
 }
@@ -7,16 +7,19 @@ import (
 	"fmt"
 	"io"
 	"io/ioutil"
+	"path"
 	"reflect"
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	daemondiscovery "github.com/docker/docker/daemon/discovery"
 	"github.com/docker/docker/opts"
 	"github.com/docker/docker/pkg/authorization"
 	"github.com/docker/docker/pkg/discovery"
 	"github.com/docker/docker/registry"
+	units "github.com/docker/go-units"
 	"github.com/imdario/mergo"
 	"github.com/sirupsen/logrus"
 	"github.com/spf13/pflag"
@@ -31,6 +34,13 @@ const (
 	// maximum number of uploads that
 	// may take place at a time for each push.
 	DefaultMaxConcurrentUploads = 5
+	// DefaultMaxConcurrentDownloadChunks is the default value for
+	// maximum number of chunks of a layer that may be
+	// downloaded at a time, which disables chunked downloads.
+	DefaultMaxConcurrentDownloadChunks = 1
+	// DefaultDownloadChunkSize is the default size of the chunks
+	// of the layers downloaded in chunks.
+	DefaultDownloadChunkSize = "64m"
 	// StockRuntimeName is the reserved name/alias used to represent the
 	// OCI runtime being shipped with the docker daemon package.
 	StockRuntimeName = "runc"
@@ -42,6 +52,18 @@ const (
 	DisableNetworkBridge = "none"
 	// DefaultInitBinary is the name of the default init binary
 	DefaultInitBinary = "docker-init"
+	// DefaultEventsJournalMaxSize is the default maximum size of the events journal
+	DefaultEventsJournalMaxSize = "100m"
+	// DefaultEventsJournalMaxAge is the default maximum age of the events kept in the events journal
+	DefaultEventsJournalMaxAge = "168h"
+	// DefaultImageGCInterval is the default interval between the checks of the image filesystem usage
+	DefaultImageGCInterval = "5m"
+	// DefaultImageGCHighThreshold is the default image filesystem usage percentage triggering image garbage collection
+	DefaultImageGCHighThreshold = 85
+	// DefaultImageGCLowThreshold is the default image filesystem usage percentage image garbage collection frees space down to
+	DefaultImageGCLowThreshold = 80
+	// DefaultImageGCMinAge is the default minimum time since an image was last used before it is garbage collected
+	DefaultImageGCMinAge = "1h"
 )
 
 // flatOptions contains configuration keys
@@ -63,6 +85,103 @@ type LogConfig struct {
 	Config map[string]string `json:"log-opts,omitempty"`
 }
 
+// EventsJournalConfig represents the configuration of the on-disk events journal.
+// It includes json tags to deserialize configuration from a file
+// using the same names that the flags in the command line use.
+type EventsJournalConfig struct {
+	Enabled bool   `json:"events-journal,omitempty"`
+	MaxSize string `json:"events-journal-max-size,omitempty"`
+	MaxAge  string `json:"events-journal-max-age,omitempty"`
+}
+
+// Parse returns the maximum size in bytes and the maximum age of the events
+// journal. A zero value means that the journal is not bounded in that dimension.
+func (c EventsJournalConfig) Parse() (maxSize int64, maxAge time.Duration, err error) {
+	if c.MaxSize != "" {
+		if maxSize, err = units.RAMInBytes(c.MaxSize); err != nil {
+			return 0, 0, fmt.Errorf("invalid events journal max size %q: %v", c.MaxSize, err)
+		}
+		if maxSize < 0 {
+			return 0, 0, fmt.Errorf("invalid events journal max size %q", c.MaxSize)
+		}
+	}
+	if c.MaxAge != "" {
+		if maxAge, err = time.ParseDuration(c.MaxAge); err != nil {
+			return 0, 0, fmt.Errorf("invalid events journal max age %q: %v", c.MaxAge, err)
+		}
+		if maxAge < 0 {
+			return 0, 0, fmt.Errorf("invalid events journal max age %q", c.MaxAge)
+		}
+	}
+	return maxSize, maxAge, nil
+}
+
+// ImageGCConfig represents the configuration of the automatic garbage
+// collection of images.
+// It includes json tags to deserialize configuration from a file
+// using the same names that the flags in the command line use.
+type ImageGCConfig struct {
+	Enabled        bool     `json:"image-gc,omitempty"`
+	Interval       string   `json:"image-gc-interval,omitempty"`
+	HighThreshold  int      `json:"image-gc-high-threshold,omitempty"`
+	LowThreshold   int      `json:"image-gc-low-threshold,omitempty"`
+	MinAge         string   `json:"image-gc-min-age,omitempty"`
+	KeepReferences []string `json:"image-gc-keep-references,omitempty"`
+	KeepLabels     []string `json:"image-gc-keep-labels,omitempty"`
+}
+
+// Parse returns the interval between the checks of the image filesystem
+// usage, and the minimum time since an image was last used before it can be
+// garbage collected. It also validates the usage thresholds.
+func (c ImageGCConfig) Parse() (interval, minAge time.Duration, err error) {
+	if interval, err = time.ParseDuration(c.Interval); err != nil {
+		return 0, 0, fmt.Errorf("invalid image gc interval %q: %v", c.Interval, err)
+	}
+	if interval <= 0 {
+		return 0, 0, fmt.Errorf("invalid image gc interval %q", c.Interval)
+	}
+	if c.MinAge != "" {
+		if minAge, err = time.ParseDuration(c.MinAge); err != nil {
+			return 0, 0, fmt.Errorf("invalid image gc min age %q: %v", c.MinAge, err)
+		}
+		if minAge < 0 {
+			return 0, 0, fmt.Errorf("invalid image gc min age %q", c.MinAge)
+		}
+	}
+	if c.HighThreshold <= 0 || c.HighThreshold > 100 {
+		return 0, 0, fmt.Errorf("invalid image gc high threshold %d: must be a percentage between 1 and 100", c.HighThreshold)
+	}
+	if c.LowThreshold <= 0 || c.LowThreshold >= c.HighThreshold {
+		return 0, 0, fmt.Errorf("invalid image gc low threshold %d: must be a percentage lower than the high threshold", c.LowThreshold)
+	}
+	return interval, minAge, nil
+}
+
+// EventSinkConfig defines an external endpoint daemon events are forwarded to.
+type EventSinkConfig struct {
+	// Type is the type of the sink: webhook, syslog or file.
+	Type string `json:"type"`
+	// Address is the URL of the webhook, the address of the syslog
+	// endpoint or the path of the file events are forwarded to.
+	Address string `json:"address,omitempty"`
+	// Filters selects the forwarded events, using the same filters
+	// as the events API.
+	Filters map[string][]string `json:"filters,omitempty"`
+	// Options holds sink specific options.
+	Options map[string]string `json:"options,omitempty"`
+}
+
+// ImagePolicyRule requires the images pulled from the repositories matching
+// a pattern to be signed by one of a set of public keys.
+type ImagePolicyRule struct {
+	// Repository is a pattern matching full repository names, such as
+	// "docker.io/myorg/*", with the syntax of path.Match.
+	Repository string `json:"repository"`
+	// PublicKeys are the paths of the PEM encoded public keys trusted to
+	// sign the images of the repositories.
+	PublicKeys []string `json:"public-keys"`
+}
+
 // commonBridgeConfig stores all the platform-common bridge driver specific
 // configuration.
 type commonBridgeConfig struct {
@@ -136,6 +255,24 @@ type CommonConfig struct {
 	// may take place at a time for each push.
 	MaxConcurrentUploads *int `json:"max-concurrent-uploads,omitempty"`
 
+	// MaxConcurrentDownloadChunks is the maximum number of chunks of a
+	// layer that may be downloaded at a time, with range requests. Layers
+	// are downloaded with a single request if it is less than 2.
+	MaxConcurrentDownloadChunks int `json:"max-concurrent-download-chunks,omitempty"`
+
+	// DownloadChunkSize is the size of the chunks in which the layers
+	// larger than it are downloaded, if MaxConcurrentDownloadChunks allows
+	// chunked downloads.
+	DownloadChunkSize string `json:"download-chunk-size,omitempty"`
+
+	// LazyPull registers the pulled layers without downloading them, and
+	// only downloads a layer when its content is first needed. A layer is
+	// always downloaded whole: there is no per-file fetch, so the first
+	// container created from an image still waits for all its layers.
+	// Images whose layers were never downloaded are removed on restart.
+	// This is experimental.
+	LazyPull bool `json:"lazy-pull,omitempty"`
+
 	// ShutdownTimeout is the timeout value (in seconds) the daemon will wait for the container
 	// to stop when daemon is being shutdown
 	ShutdownTimeout int `json:"shutdown-timeout,omitempty"`
@@ -157,7 +294,29 @@ type CommonConfig struct {
 	SwarmDefaultAdvertiseAddr string `json:"swarm-default-advertise-addr"`
 	MetricsAddress            string `json:"metrics-addr"`
 
+	// MetricsContainers enables the per-container metrics endpoint on the
+	// metrics address. MetricsContainerLabels lists the container labels
+	// which are exported as metric labels.
+	MetricsContainers      bool     `json:"metrics-containers,omitempty"`
+	MetricsContainerLabels []string `json:"metrics-container-labels,omitempty"`
+
+	// EventSinks are the external endpoints daemon events are forwarded to.
+	EventSinks []EventSinkConfig `json:"event-sinks,omitempty"`
+
+	// ImagePolicy lists the repositories whose images must be signed. The
+	// first rule matching a repository applies.
+	ImagePolicy []ImagePolicyRule `json:"image-policy,omitempty"`
+
+	// TracingExporter selects where the traces of API requests are exported,
+	// either "otlp" or "file". Tracing is disabled if it is empty.
+	// TracingEndpoint is the URL of the OTLP traces endpoint, or the path of
+	// the file spans are written to.
+	TracingExporter string `json:"tracing-exporter,omitempty"`
+	TracingEndpoint string `json:"tracing-endpoint,omitempty"`
+
 	LogConfig
+	EventsJournalConfig
+	ImageGCConfig
 	BridgeConfig // bridgeConfig holds bridge network specific configuration.
 	registry.ServiceOptions
 
@@ -461,6 +620,23 @@ func findConfigurationConflicts(config map[string]interface{}, flags *pflag.Flag
 	return nil
 }
 
+// ParseDownloadChunkSize returns the size in bytes of the chunks of the
+// layers downloaded in chunks. The default size is used if it is not set.
+func (config *Config) ParseDownloadChunkSize() (int64, error) {
+	chunkSize := config.DownloadChunkSize
+	if chunkSize == "" {
+		chunkSize = DefaultDownloadChunkSize
+	}
+	size, err := units.RAMInBytes(chunkSize)
+	if err != nil {
+		return 0, fmt.Errorf("invalid download chunk size %q: %v", chunkSize, err)
+	}
+	if size <= 0 {
+		return 0, fmt.Errorf("invalid download chunk size %q", chunkSize)
+	}
+	return size, nil
+}
+
 // Validate validates some specific configs.
 // such as config.DNS, config.Labels, config.DNSSearch,
 // as well as config.MaxConcurrentDownloads, config.MaxConcurrentUploads.
@@ -493,6 +669,16 @@ func Validate(config *Config) error {
 	if config.MaxConcurrentUploads != nil && *config.MaxConcurrentUploads < 0 {
 		return fmt.Errorf("invalid max concurrent uploads: %d", *config.MaxConcurrentUploads)
 	}
+	// validate MaxConcurrentDownloadChunks and DownloadChunkSize
+	if config.MaxConcurrentDownloadChunks < 0 {
+		return fmt.Errorf("invalid max concurrent download chunks: %d", config.MaxConcurrentDownloadChunks)
+	}
+	if _, err := config.ParseDownloadChunkSize(); err != nil {
+		return err
+	}
+	if config.LazyPull && !config.Experimental {
+		return fmt.Errorf("lazy-pull is only supported when experimental is enabled")
+	}
 
 	// validate that "default" runtime is not reset
 	if runtimes := config.GetAllRuntimes(); len(runtimes) > 0 {
@@ -505,6 +691,52 @@ func Validate(config *Config) error {
 		return err
 	}
 
+	if _, _, err := config.EventsJournalConfig.Parse(); err != nil {
+		return err
+	}
+
+	if config.ImageGCConfig.Enabled {
+		if _, _, err := config.ImageGCConfig.Parse(); err != nil {
+			return err
+		}
+		for _, label := range config.ImageGCConfig.KeepLabels {
+			if strings.HasPrefix(label, "=") {
+				return fmt.Errorf("invalid image gc keep label %q", label)
+			}
+		}
+	}
+
+	// validate event sinks, sink specific options are validated when the sinks are created
+	for _, sink := range config.EventSinks {
+		switch sink.Type {
+		case "webhook", "syslog", "file":
+		default:
+			return fmt.Errorf("invalid event sink type: %q", sink.Type)
+		}
+	}
+
+	for _, rule := range config.ImagePolicy {
+		if rule.Repository == "" {
+			return fmt.Errorf("invalid image policy rule: a repository pattern is required")
+		}
+		if _, err := path.Match(rule.Repository, ""); err != nil {
+			return fmt.Errorf("invalid image policy repository pattern %q: %v", rule.Repository, err)
+		}
+		if len(rule.PublicKeys) == 0 {
+			return fmt.Errorf("invalid image policy rule for %q: at least one public key is required", rule.Repository)
+		}
+	}
+
+	switch config.TracingExporter {
+	case "":
+	case "otlp", "file":
+		if config.TracingEndpoint == "" {
+			return fmt.Errorf("tracing-endpoint is required with the %s tracing exporter", config.TracingExporter)
+		}
+	default:
+		return fmt.Errorf("invalid tracing exporter: %q", config.TracingExporter)
+	}
+
 	if defaultRuntime := config.GetDefaultRuntimeName(); defaultRuntime != "" && defaultRuntime != StockRuntimeName {
 		runtimes := config.GetAllRuntimes()
 		if _, ok := runtimes[defaultRuntime]; !ok {
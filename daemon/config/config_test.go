@@ -263,6 +263,111 @@ func TestValidateConfigurationErrors(t *testing.T) {
 				},
 			},
 		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournalConfig: EventsJournalConfig{
+						MaxSize: "lots",
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					MaxConcurrentDownloadChunks: -1,
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					DownloadChunkSize: "0",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					LazyPull: true,
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournalConfig: EventsJournalConfig{
+						MaxAge: "-1h",
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCConfig: ImageGCConfig{
+						Enabled:       true,
+						Interval:      "0s",
+						HighThreshold: 85,
+						LowThreshold:  80,
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCConfig: ImageGCConfig{
+						Enabled:       true,
+						Interval:      "5m",
+						HighThreshold: 80,
+						LowThreshold:  85,
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCConfig: ImageGCConfig{
+						Enabled:       true,
+						Interval:      "5m",
+						HighThreshold: 85,
+						LowThreshold:  80,
+						KeepLabels:    []string{"=value"},
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					TracingExporter: "jaeger",
+					TracingEndpoint: "http://localhost:14268",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					TracingExporter: "otlp",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImagePolicy: []ImagePolicyRule{{Repository: "docker.io/myorg/[", PublicKeys: []string{"/etc/docker/keys/myorg.pub"}}},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImagePolicy: []ImagePolicyRule{{Repository: "docker.io/myorg/*"}},
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		err := Validate(tc.config)
@@ -320,6 +425,72 @@ func TestValidateConfiguration(t *testing.T) {
 				},
 			},
 		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					EventsJournalConfig: EventsJournalConfig{
+						Enabled: true,
+						MaxSize: "100m",
+						MaxAge:  "24h",
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCConfig: ImageGCConfig{
+						Enabled:        true,
+						Interval:       "5m",
+						HighThreshold:  90,
+						LowThreshold:   70,
+						MinAge:         "24h",
+						KeepReferences: []string{"myorg/*"},
+						KeepLabels:     []string{"keep", "tier=base"},
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImageGCConfig: ImageGCConfig{
+						Interval: "invalid",
+					},
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					MaxConcurrentDownloadChunks: 4,
+					DownloadChunkSize:           "32m",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					Experimental: true,
+					LazyPull:     true,
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					TracingExporter: "otlp",
+					TracingEndpoint: "http://localhost:4318/v1/traces",
+				},
+			},
+		},
+		{
+			config: &Config{
+				CommonConfig: CommonConfig{
+					ImagePolicy: []ImagePolicyRule{{Repository: "docker.io/myorg/*", PublicKeys: []string{"/etc/docker/keys/myorg.pub"}}},
+				},
+			},
+		},
 	}
 	for _, tc := range testCases {
 		err := Validate(tc.config)
@@ -1,6 +1,9 @@
 package config
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/docker/docker/api/types/swarm"
 	"github.com/docker/docker/daemon/cluster/convert"
 	"github.com/docker/swarmkit/api/genericresource"
@@ -20,3 +23,138 @@ func ParseGenericResources(value string) ([]swarm.GenericResource, error) {
 	obj := convert.GenericResourcesFromGRPC(resources)
 	return obj, nil
 }
+
+// EventSinksOpt is a flag value which adds an event sink to a list of sinks.
+// Its format is a comma separated list of key=value pairs, e.g.
+// "type=webhook,address=https://example.com/hook,filter=type=container,secret=s3cr3t".
+// The type, address and filter keys set the corresponding fields of the
+// sink, filter may be repeated. Any other key is a sink specific option.
+type EventSinksOpt struct {
+	name   string
+	values *[]EventSinkConfig
+}
+
+// NewNamedEventSinksOpt creates a new EventSinksOpt
+func NewNamedEventSinksOpt(name string, ref *[]EventSinkConfig) *EventSinksOpt {
+	if ref == nil {
+		ref = &[]EventSinkConfig{}
+	}
+	return &EventSinksOpt{name: name, values: ref}
+}
+
+// Name returns the name of the EventSinksOpt in the configuration.
+func (o *EventSinksOpt) Name() string {
+	return o.name
+}
+
+// Set parses an event sink definition and adds it to the list of sinks.
+func (o *EventSinksOpt) Set(val string) error {
+	var sink EventSinkConfig
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid event sink field %q: must be a key=value pair", field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch key {
+		case "type":
+			sink.Type = value
+		case "address":
+			sink.Address = value
+		case "filter":
+			kv := strings.SplitN(value, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("invalid event sink filter %q: must be a key=value pair", value)
+			}
+			if sink.Filters == nil {
+				sink.Filters = make(map[string][]string)
+			}
+			sink.Filters[kv[0]] = append(sink.Filters[kv[0]], kv[1])
+		default:
+			if sink.Options == nil {
+				sink.Options = make(map[string]string)
+			}
+			sink.Options[key] = value
+		}
+	}
+	if sink.Type == "" {
+		return fmt.Errorf("invalid event sink %q: type is required", val)
+	}
+	*o.values = append(*o.values, sink)
+	return nil
+}
+
+// String returns the types of the event sinks as a string.
+func (o *EventSinksOpt) String() string {
+	var out []string
+	for _, sink := range *o.values {
+		out = append(out, sink.Type)
+	}
+	return fmt.Sprintf("%v", out)
+}
+
+// Type returns the type of the option
+func (o *EventSinksOpt) Type() string {
+	return "event-sink"
+}
+
+// ImagePolicyOpt is a flag value which adds a rule to an image policy. Its
+// format is a comma separated list of key=value pairs, e.g.
+// "repository=docker.io/myorg/*,key=/etc/docker/keys/myorg.pub". key may be
+// repeated.
+type ImagePolicyOpt struct {
+	name   string
+	values *[]ImagePolicyRule
+}
+
+// NewNamedImagePolicyOpt creates a new ImagePolicyOpt
+func NewNamedImagePolicyOpt(name string, ref *[]ImagePolicyRule) *ImagePolicyOpt {
+	if ref == nil {
+		ref = &[]ImagePolicyRule{}
+	}
+	return &ImagePolicyOpt{name: name, values: ref}
+}
+
+// Name returns the name of the ImagePolicyOpt in the configuration.
+func (o *ImagePolicyOpt) Name() string {
+	return o.name
+}
+
+// Set parses an image policy rule and adds it to the policy.
+func (o *ImagePolicyOpt) Set(val string) error {
+	var rule ImagePolicyRule
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid image policy field %q: must be a key=value pair", field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch key {
+		case "repository":
+			rule.Repository = value
+		case "key":
+			rule.PublicKeys = append(rule.PublicKeys, value)
+		default:
+			return fmt.Errorf("invalid image policy field %q: unknown key %q", field, key)
+		}
+	}
+	if rule.Repository == "" || len(rule.PublicKeys) == 0 {
+		return fmt.Errorf("invalid image policy rule %q: repository and key are required", val)
+	}
+	*o.values = append(*o.values, rule)
+	return nil
+}
+
+// String returns the repository patterns of the rules as a string.
+func (o *ImagePolicyOpt) String() string {
+	var out []string
+	for _, rule := range *o.values {
+		out = append(out, rule.Repository)
+	}
+	return fmt.Sprintf("%v", out)
+}
+
+// Type returns the type of the option
+func (o *ImagePolicyOpt) Type() string {
+	return "image-policy"
+}
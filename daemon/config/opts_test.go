@@ -0,0 +1,67 @@
+package config
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestEventSinksOpt(t *testing.T) {
+	var sinks []EventSinkConfig
+	o := NewNamedEventSinksOpt("event-sinks", &sinks)
+
+	if err := o.Set("type=webhook,address=https://example.com/hook,filter=type=container,filter=event=die,secret=s3cr3t"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("type=file,address=/var/log/docker-events.log,max-size=10m"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []EventSinkConfig{
+		{
+			Type:    "webhook",
+			Address: "https://example.com/hook",
+			Filters: map[string][]string{"type": {"container"}, "event": {"die"}},
+			Options: map[string]string{"secret": "s3cr3t"},
+		},
+		{
+			Type:    "file",
+			Address: "/var/log/docker-events.log",
+			Options: map[string]string{"max-size": "10m"},
+		},
+	}
+	if !reflect.DeepEqual(sinks, expected) {
+		t.Fatalf("expected %v, got %v", expected, sinks)
+	}
+
+	for _, invalid := range []string{"address=/tmp/events.log", "type=file,address", "type=file,filter=type"} {
+		if err := o.Set(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+}
+
+func TestImagePolicyOpt(t *testing.T) {
+	var rules []ImagePolicyRule
+	o := NewNamedImagePolicyOpt("image-policy", &rules)
+
+	if err := o.Set("repository=docker.io/myorg/*,key=/etc/docker/keys/a.pub,key=/etc/docker/keys/b.pub"); err != nil {
+		t.Fatal(err)
+	}
+	if err := o.Set("repository=registry.example.com/*/*,key=/etc/docker/keys/c.pub"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []ImagePolicyRule{
+		{Repository: "docker.io/myorg/*", PublicKeys: []string{"/etc/docker/keys/a.pub", "/etc/docker/keys/b.pub"}},
+		{Repository: "registry.example.com/*/*", PublicKeys: []string{"/etc/docker/keys/c.pub"}},
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("expected %v, got %v", expected, rules)
+	}
+
+	for _, invalid := range []string{"repository=docker.io/myorg/*", "key=/etc/docker/keys/a.pub", "repository=docker.io/*,key", "repository=docker.io/*,key=/a.pub,type=cosign"} {
+		if err := o.Set(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+}
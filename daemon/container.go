@@ -258,6 +258,19 @@ func (daemon *Daemon) verifyContainerSettings(platform string, hostConfig *conta
 			}
 		}
 
+		for _, stage := range config.StopEscalation {
+			if _, err := signal.ParseSignal(stage.Signal); err != nil {
+				return nil, err
+			}
+			if stage.Delay < 0 {
+				return nil, errors.Errorf("Delay in StopEscalation cannot be negative")
+			}
+		}
+
+		if config.StopHook != nil && config.StopHook.Timeout != 0 && config.StopHook.Timeout < containertypes.MinimumDuration {
+			return nil, errors.Errorf("Timeout in StopHook cannot be less than %s", containertypes.MinimumDuration)
+		}
+
 		// Validate if Env contains empty variable or not (e.g., ``, `=foo`)
 		for _, env := range config.Env {
 			if _, err := opts.ValidateEnv(env); err != nil {
@@ -282,6 +295,10 @@ func (daemon *Daemon) verifyContainerSettings(platform string, hostConfig *conta
 			if config.Healthcheck.StartPeriod != 0 && config.Healthcheck.StartPeriod < containertypes.MinimumDuration {
 				return nil, errors.Errorf("StartPeriod in Healthcheck cannot be less than %s", containertypes.MinimumDuration)
 			}
+
+			if err := validateHealthcheckTest(config.Healthcheck.Test); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -319,7 +336,7 @@ func (daemon *Daemon) verifyContainerSettings(platform string, hostConfig *conta
 		if p.MaximumRetryCount != 0 {
 			return nil, errors.Errorf("maximum retry count cannot be used with restart policy '%s'", p.Name)
 		}
-	case "on-failure":
+	case "on-failure", "on-unhealthy":
 		if p.MaximumRetryCount < 0 {
 			return nil, errors.Errorf("maximum retry count cannot be negative")
 		}
@@ -329,6 +346,14 @@ func (daemon *Daemon) verifyContainerSettings(platform string, hostConfig *conta
 		return nil, errors.Errorf("invalid restart policy '%s'", p.Name)
 	}
 
+	if p.InitialDelay < 0 || p.MaxDelay < 0 || p.ResetWindow < 0 {
+		return nil, errors.Errorf("restart policy delays cannot be negative")
+	}
+
+	if p.InitialDelay != 0 && p.MaxDelay != 0 && p.InitialDelay > p.MaxDelay {
+		return nil, errors.Errorf("restart policy initial delay cannot be greater than its maximum delay")
+	}
+
 	// Now do platform-specific verification
 	return verifyPlatformContainerSettings(daemon, hostConfig, config, update)
 }
@@ -18,23 +18,31 @@ import (
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/stringid"
 	"github.com/docker/docker/pkg/system"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/docker/docker/runconfig"
 	"github.com/opencontainers/selinux/go-selinux/label"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 // CreateManagedContainer creates a container that is managed by a Service
 func (daemon *Daemon) CreateManagedContainer(params types.ContainerCreateConfig) (containertypes.ContainerCreateCreatedBody, error) {
-	return daemon.containerCreate(params, true)
+	return daemon.containerCreate(context.Background(), params, true)
 }
 
 // ContainerCreate creates a regular container
-func (daemon *Daemon) ContainerCreate(params types.ContainerCreateConfig) (containertypes.ContainerCreateCreatedBody, error) {
-	return daemon.containerCreate(params, false)
+func (daemon *Daemon) ContainerCreate(ctx context.Context, params types.ContainerCreateConfig) (containertypes.ContainerCreateCreatedBody, error) {
+	return daemon.containerCreate(ctx, params, false)
 }
 
-func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, managed bool) (containertypes.ContainerCreateCreatedBody, error) {
+func (daemon *Daemon) containerCreate(ctx context.Context, params types.ContainerCreateConfig, managed bool) (_ containertypes.ContainerCreateCreatedBody, retErr error) {
 	start := time.Now()
+	span, ctx := tracing.StartSpan(ctx, "daemon.ContainerCreate")
+	defer func() {
+		span.SetError(retErr)
+		span.Finish()
+	}()
+
 	if params.Config == nil {
 		return containertypes.ContainerCreateCreatedBody{}, validationError{errors.New("Config cannot be empty in order to create a container")}
 	}
@@ -59,6 +67,10 @@ func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, manage
 		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, validationError{err}
 	}
 
+	if err := daemon.verifyDependencies(params.Name, params.HostConfig); err != nil {
+		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, validationError{err}
+	}
+
 	if params.HostConfig == nil {
 		params.HostConfig = &containertypes.HostConfig{}
 	}
@@ -67,7 +79,7 @@ func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, manage
 		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, validationError{err}
 	}
 
-	container, err := daemon.create(params, managed)
+	container, err := daemon.create(ctx, params, managed)
 	if err != nil {
 		return containertypes.ContainerCreateCreatedBody{Warnings: warnings}, err
 	}
@@ -77,7 +89,7 @@ func (daemon *Daemon) containerCreate(params types.ContainerCreateConfig, manage
 }
 
 // Create creates a new container from the given configuration with a given name.
-func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (retC *container.Container, retErr error) {
+func (daemon *Daemon) create(ctx context.Context, params types.ContainerCreateConfig, managed bool) (retC *container.Container, retErr error) {
 	var (
 		container *container.Container
 		img       *image.Image
@@ -96,6 +108,11 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 		}
 		imgID = img.ID()
 
+		if err := daemon.checkImagePolicy(params.Config.Image, img); err != nil {
+			return nil, err
+		}
+		daemon.markImageUsed(img)
+
 		if runtime.GOOS == "windows" && img.OS == "linux" && !system.LCOWSupported() {
 			return nil, errors.New("platform on which parent image was created is not Windows")
 		}
@@ -154,7 +171,7 @@ func (daemon *Daemon) create(params types.ContainerCreateConfig, managed bool) (
 	}
 
 	// Set RWLayer for container after mount labels have been set
-	if err := daemon.setRWLayer(container); err != nil {
+	if err := daemon.setRWLayer(ctx, container); err != nil {
 		return nil, systemError{err}
 	}
 
@@ -250,7 +267,13 @@ func (daemon *Daemon) generateSecurityOpt(hostConfig *containertypes.HostConfig)
 	return nil, nil
 }
 
-func (daemon *Daemon) setRWLayer(container *container.Container) error {
+func (daemon *Daemon) setRWLayer(ctx context.Context, container *container.Container) (retErr error) {
+	span, _ := tracing.StartSpan(ctx, "layer.create")
+	defer func() {
+		span.SetError(retErr)
+		span.Finish()
+	}()
+
 	var layerID layer.ChainID
 	if container.ImageID != "" {
 		img, err := daemon.stores[container.Platform].imageStore.Get(container.ImageID)
@@ -26,6 +26,7 @@ import (
 	"github.com/docker/docker/daemon/config"
 	"github.com/docker/docker/daemon/discovery"
 	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/events/sinks"
 	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/daemon/logger"
 	"github.com/docker/docker/daemon/network"
@@ -94,6 +95,8 @@ type Daemon struct {
 	defaultLogConfig      containertypes.LogConfig
 	RegistryService       registry.Service
 	EventsService         *events.Events
+	eventsJournal         *events.Journal
+	eventForwarders       []*sinks.Forwarder
 	netController         libnetwork.NetworkController
 	volumes               *store.VolumeStore
 	discoveryWatcher      discovery.Reloader
@@ -104,6 +107,8 @@ type Daemon struct {
 	idMappings            *idtools.IDMappings
 	stores                map[string]daemonStore // By container target platform
 	referenceStore        refstore.Store
+	imagePolicy           *imagePolicy
+	imageGC               *imageGC
 	PluginStore           *plugin.Store // todo: remove
 	pluginManager         *plugin.Manager
 	linkIndex             *linkIndex
@@ -223,6 +228,9 @@ func (daemon *Daemon) restore() error {
 			daemon.setStateCounter(c)
 			if c.IsRunning() || c.IsPaused() {
 				c.RestartManager().Cancel() // manually start containers because some need to wait for swarm networking
+				c.Lock()
+				c.CaptureStderr()
+				c.Unlock()
 				if err := daemon.containerd.Restore(c.ID, c.InitializeStdio); err != nil {
 					logrus.Errorf("Failed to restore %s with containerd: %s", c.ID, err)
 					return
@@ -332,7 +340,9 @@ func (daemon *Daemon) restore() error {
 
 			// Make sure networks are available before starting
 			daemon.waitForNetworks(c)
-			if err := daemon.containerStart(c, "", "", true); err != nil {
+			if err := daemon.waitForRestoredDependencies(c, restartContainers); err != nil {
+				logrus.Errorf("Failed to wait for the dependencies of container %s: %s", c.ID, err)
+			} else if err := daemon.containerStart(context.Background(), c, "", "", true); err != nil {
 				logrus.Errorf("Failed to start container %s: %s", c.ID, err)
 			}
 			close(chNotify)
@@ -399,7 +409,7 @@ func (daemon *Daemon) RestartSwarmContainers() {
 				group.Add(1)
 				go func(c *container.Container) {
 					defer group.Done()
-					if err := daemon.containerStart(c, "", "", true); err != nil {
+					if err := daemon.containerStart(context.Background(), c, "", "", true); err != nil {
 						logrus.Error(err)
 					}
 				}(c)
@@ -694,7 +704,11 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 	for platform, ds := range d.stores {
 		lsMap[platform] = ds.layerStore
 	}
-	d.downloadManager = xfer.NewLayerDownloadManager(lsMap, *config.MaxConcurrentDownloads)
+	var downloadOptions []func(*xfer.LayerDownloadManager)
+	if config.Experimental && config.LazyPull {
+		downloadOptions = append(downloadOptions, xfer.WithLazyPull)
+	}
+	d.downloadManager = xfer.NewLayerDownloadManager(lsMap, *config.MaxConcurrentDownloads, downloadOptions...)
 	logrus.Debugf("Max Concurrent Uploads: %d", *config.MaxConcurrentUploads)
 	d.uploadManager = xfer.NewLayerUploadManager(*config.MaxConcurrentUploads)
 	for platform, ds := range d.stores {
@@ -732,6 +746,21 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 	}
 
 	eventsService := events.New()
+	if config.EventsJournalConfig.Enabled {
+		maxSize, maxAge, err := config.EventsJournalConfig.Parse()
+		if err != nil {
+			return nil, err
+		}
+		journal, err := events.NewJournal(filepath.Join(config.Root, "events"), events.JournalOptions{
+			MaxSize: maxSize,
+			MaxAge:  maxAge,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't open events journal: %v", err)
+		}
+		eventsService.SetJournal(journal)
+		d.eventsJournal = journal
+	}
 
 	// We have a single tag/reference store for the daemon globally. However, it's
 	// stored under the graphdriver. On host platforms which only support a single
@@ -797,6 +826,12 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 		Config: config.LogConfig.Config,
 	}
 	d.EventsService = eventsService
+	if err := d.setEventSinks(config.EventSinks); err != nil {
+		return nil, err
+	}
+	if d.imagePolicy, err = newImagePolicy(config.ImagePolicy); err != nil {
+		return nil, err
+	}
 	d.volumes = volStore
 	d.root = config.Root
 	d.idMappings = idMappings
@@ -808,6 +843,13 @@ func NewDaemon(config *config.Config, registryService registry.Service, containe
 
 	go d.execCommandGC()
 
+	if d.imageGC, err = newImageGC(d, config.ImageGCConfig); err != nil {
+		return nil, err
+	}
+	if d.imageGC != nil {
+		go d.imageGC.run()
+	}
+
 	d.containerd, err = containerdRemote.Client(d)
 	if err != nil {
 		return nil, err
@@ -861,7 +903,7 @@ func (daemon *Daemon) shutdownContainer(c *container.Container) error {
 	stopTimeout := c.StopTimeout()
 
 	// If container failed to exit in stopTimeout seconds of SIGTERM, then using the force
-	if err := daemon.containerStop(c, stopTimeout); err != nil {
+	if err := daemon.containerStop(c, stopTimeout, types.TerminationShutdown); err != nil {
 		return fmt.Errorf("Failed to stop container %s with error: %v", c.ID, err)
 	}
 
@@ -885,6 +927,11 @@ func (daemon *Daemon) ShutdownTimeout() int {
 				if stopTimeout < 0 {
 					shutdownTimeout = -1
 				} else {
+					// The stop hook and the stop escalation run before the
+					// stop timeout starts.
+					if c.Config != nil {
+						stopTimeout += int((stopHookAndEscalationTimeout(c.Config) + time.Second - 1) / time.Second)
+					}
 					if stopTimeout+graceTimeout > shutdownTimeout {
 						shutdownTimeout = stopTimeout + graceTimeout
 					}
@@ -898,6 +945,9 @@ func (daemon *Daemon) ShutdownTimeout() int {
 // Shutdown stops the daemon.
 func (daemon *Daemon) Shutdown() error {
 	daemon.shutdown = true
+	if daemon.imageGC != nil {
+		daemon.imageGC.Stop()
+	}
 	// Keep mounts and networking running on daemon shutdown if
 	// we are to keep containers running and restore them.
 
@@ -950,6 +1000,14 @@ func (daemon *Daemon) Shutdown() error {
 
 	daemon.cleanupMetricsPlugins()
 
+	daemon.closeEventSinks()
+
+	if daemon.eventsJournal != nil {
+		if err := daemon.eventsJournal.Close(); err != nil {
+			logrus.Errorf("Error closing events journal: %v", err)
+		}
+	}
+
 	// Shutdown plugins after containers and layerstore. Don't change the order.
 	daemon.pluginShutdown()
 
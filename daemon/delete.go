@@ -43,6 +43,11 @@ func (daemon *Daemon) ContainerRm(name string, config *types.ContainerRmConfig)
 		return daemon.rmLink(container, name)
 	}
 
+	if dependents := daemon.dependents(container); len(dependents) > 0 {
+		err := errors.Errorf("cannot remove container %s: it is a dependency of %s", strings.TrimPrefix(container.Name, "/"), strings.Join(dependents, ", "))
+		return stateConflictError{err}
+	}
+
 	err = daemon.cleanupContainer(container, config.ForceRemove, config.RemoveVolume)
 	containerActions.WithValues("delete").UpdateSince(start)
 
@@ -88,7 +93,7 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 			err := fmt.Errorf("You cannot remove a %s container %s. %s", state, container.ID, procedure)
 			return stateConflictError{err}
 		}
-		if err := daemon.Kill(container); err != nil {
+		if err := daemon.Kill(container, types.TerminationRemove); err != nil {
 			return fmt.Errorf("Could not kill running container %s, cannot remove - %v", container.ID, err)
 		}
 	}
@@ -97,7 +102,7 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 	// if stats are currently getting collected.
 	daemon.statsCollector.StopCollection(container)
 
-	if err = daemon.containerStop(container, 3); err != nil {
+	if err = daemon.containerStop(container, 3, types.TerminationRemove); err != nil {
 		return err
 	}
 
@@ -140,6 +145,7 @@ func (daemon *Daemon) cleanupContainer(container *container.Container, forceRemo
 	}
 	container.SetRemoved()
 	stateCtr.del(container.ID)
+	healthCtr.del(container.ID)
 	daemon.LogContainerEvent(container, "destroy")
 	return nil
 }
@@ -0,0 +1,234 @@
+package daemon
+
+import (
+	"strings"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// dependencyPollInterval is how often the health state of a dependency is
+// checked while waiting for it to become healthy.
+var dependencyPollInterval = 100 * time.Millisecond
+
+// restoreDependencyTimeout bounds how long a container restarted on daemon
+// boot waits for its dependencies, so that a dependency which never becomes
+// healthy does not block the daemon start.
+var restoreDependencyTimeout = 2 * time.Minute
+
+// verifyDependencies checks the dependencies of a container that is about
+// to be created with the given name. All the dependencies must exist, and
+// they must not (directly or indirectly) depend on the new container.
+func (daemon *Daemon) verifyDependencies(name string, hostConfig *containertypes.HostConfig) error {
+	if hostConfig == nil {
+		return nil
+	}
+	if name != "" {
+		name = fullContainerName(name)
+	}
+
+	for _, dep := range hostConfig.DependsOn {
+		switch dep.Condition {
+		case "", containertypes.DependencyConditionStarted, containertypes.DependencyConditionHealthy:
+		default:
+			return errors.Errorf("invalid dependency condition '%s'", dep.Condition)
+		}
+		if dep.Container == "" {
+			return errors.New("dependency container cannot be empty")
+		}
+		if name != "" && fullContainerName(dep.Container) == name {
+			return errors.Errorf("container %s cannot depend on itself", strings.TrimPrefix(name, "/"))
+		}
+
+		c, err := daemon.GetContainer(dep.Container)
+		if err != nil {
+			return errors.Wrapf(err, "invalid dependency %s", dep.Container)
+		}
+		if dep.IsHealthy() && getProbe(c) == nil {
+			return errors.Errorf("dependency %s cannot be waited for to be healthy: it has no health check", dep.Container)
+		}
+		if name == "" {
+			// An unnamed container cannot be depended on yet.
+			continue
+		}
+		if cycle := daemon.dependencyCycle(c, name, make(map[string]bool)); cycle != nil {
+			cycle = append([]string{name}, cycle...)
+			for i := range cycle {
+				cycle[i] = strings.TrimPrefix(cycle[i], "/")
+			}
+			return errors.Errorf("dependency cycle detected: %s", strings.Join(cycle, " -> "))
+		}
+	}
+	return nil
+}
+
+// dependencyCycle returns the names of the containers on a dependency path
+// from c to the container with the given full name, or nil if c does not
+// depend on it.
+func (daemon *Daemon) dependencyCycle(c *container.Container, name string, seen map[string]bool) []string {
+	if seen[c.ID] {
+		return nil
+	}
+	seen[c.ID] = true
+
+	for _, dep := range c.HostConfig.DependsOn {
+		if fullContainerName(dep.Container) == name {
+			return []string{c.Name, name}
+		}
+		next, err := daemon.GetContainer(dep.Container)
+		if err != nil {
+			continue
+		}
+		if path := daemon.dependencyCycle(next, name, seen); path != nil {
+			return append([]string{c.Name}, path...)
+		}
+	}
+	return nil
+}
+
+// startDependencies starts the containers c depends on that are not running
+// yet, and waits for each of them to reach the condition required by c.
+func (daemon *Daemon) startDependencies(ctx context.Context, c *container.Container) error {
+	return daemon.startDependenciesOf(ctx, c, map[string]bool{c.ID: true})
+}
+
+func (daemon *Daemon) startDependenciesOf(ctx context.Context, c *container.Container, starting map[string]bool) error {
+	for _, dep := range c.HostConfig.DependsOn {
+		d, err := daemon.GetContainer(dep.Container)
+		if err != nil {
+			return errors.Wrapf(err, "cannot start dependency %s of container %s", dep.Container, strings.TrimPrefix(c.Name, "/"))
+		}
+		if starting[d.ID] {
+			return stateConflictError{errors.Errorf("dependency cycle detected between containers %s and %s", strings.TrimPrefix(c.Name, "/"), strings.TrimPrefix(d.Name, "/"))}
+		}
+
+		if !d.IsRunning() {
+			starting[d.ID] = true
+			if err := daemon.startDependenciesOf(ctx, d, starting); err != nil {
+				return err
+			}
+			delete(starting, d.ID)
+
+			if err := daemon.containerStart(ctx, d, "", "", true); err != nil {
+				return errors.Wrapf(err, "cannot start dependency %s of container %s", strings.TrimPrefix(d.Name, "/"), strings.TrimPrefix(c.Name, "/"))
+			}
+		}
+
+		if dep.IsHealthy() {
+			if err := waitForHealthy(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// waitForRestoredDependencies waits for the dependencies of c to reach the
+// condition required by c when the containers are restored on daemon boot.
+// Unlike startDependencies, it does not start any container: it only waits
+// for the dependencies restarted along with c, given by restartContainers,
+// and fails if a dependency is not running.
+func (daemon *Daemon) waitForRestoredDependencies(c *container.Container, restartContainers map[*container.Container]chan struct{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), restoreDependencyTimeout)
+	defer cancel()
+
+	for _, dep := range c.HostConfig.DependsOn {
+		d, err := daemon.GetContainer(dep.Container)
+		if err != nil {
+			return errors.Wrapf(err, "invalid dependency %s of container %s", dep.Container, strings.TrimPrefix(c.Name, "/"))
+		}
+		if notifier, exists := restartContainers[d]; exists {
+			select {
+			case <-notifier:
+			case <-ctx.Done():
+				return errors.Wrapf(ctx.Err(), "waiting for dependency %s to start", strings.TrimPrefix(d.Name, "/"))
+			}
+		}
+		if !d.IsRunning() {
+			return stateConflictError{errors.Errorf("dependency %s is not running", strings.TrimPrefix(d.Name, "/"))}
+		}
+		if dep.IsHealthy() {
+			if err := waitForHealthy(ctx, d); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// dependents returns the names of the containers which depend on c.
+func (daemon *Daemon) dependents(c *container.Container) []string {
+	var names []string
+	for _, other := range daemon.List() {
+		if other.ID == c.ID {
+			continue
+		}
+		for _, dep := range other.HostConfig.DependsOn {
+			if d, err := daemon.GetContainer(dep.Container); err == nil && d.ID == c.ID {
+				names = append(names, strings.TrimPrefix(other.Name, "/"))
+				break
+			}
+		}
+	}
+	return names
+}
+
+// dependentsOfName returns the names of the containers which depend on a
+// container with the given full name.
+func (daemon *Daemon) dependentsOfName(name string) []string {
+	var names []string
+	for _, other := range daemon.List() {
+		for _, dep := range other.HostConfig.DependsOn {
+			if fullContainerName(dep.Container) == name {
+				names = append(names, strings.TrimPrefix(other.Name, "/"))
+				break
+			}
+		}
+	}
+	return names
+}
+
+// waitForHealthy blocks until the health check of c passes. It fails if c
+// stops, becomes unhealthy, or has no health check.
+func waitForHealthy(ctx context.Context, c *container.Container) error {
+	name := strings.TrimPrefix(c.Name, "/")
+	for {
+		c.Lock()
+		running, health := c.Running, c.State.Health
+		var status string
+		if health != nil {
+			status = health.Status
+		}
+		c.Unlock()
+
+		switch {
+		case !running:
+			return stateConflictError{errors.Errorf("dependency %s is not running", name)}
+		case health == nil:
+			return stateConflictError{errors.Errorf("dependency %s has no health check", name)}
+		case status == types.Healthy:
+			return nil
+		case status == types.Unhealthy:
+			return stateConflictError{errors.Errorf("dependency %s is unhealthy", name)}
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(dependencyPollInterval):
+		}
+	}
+}
+
+// fullContainerName returns name with the leading slash container names are
+// stored with.
+func fullContainerName(name string) string {
+	if strings.HasPrefix(name, "/") {
+		return name
+	}
+	return "/" + name
+}
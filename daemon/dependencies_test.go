@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/network"
+	"github.com/docker/docker/pkg/truncindex"
+	"golang.org/x/net/context"
+)
+
+func newDependencyTestDaemon(t *testing.T, containers ...*container.Container) *Daemon {
+	store := container.NewMemoryStore()
+	index := truncindex.NewTruncIndex([]string{})
+	containersReplica, err := container.NewViewDB()
+	if err != nil {
+		t.Fatalf("could not create ViewDB: %v", err)
+	}
+	daemon := &Daemon{
+		containers:        store,
+		containersReplica: containersReplica,
+		idIndex:           index,
+	}
+	for _, c := range containers {
+		store.Add(c.ID, c)
+		index.Add(c.ID)
+		daemon.reserveName(c.ID, c.Name)
+	}
+	return daemon
+}
+
+func newDependencyTestContainer(id, name string, deps ...containertypes.Dependency) *container.Container {
+	return &container.Container{
+		ID:         id,
+		Name:       name,
+		State:      container.NewState(),
+		Config:     &containertypes.Config{},
+		HostConfig: &containertypes.HostConfig{DependsOn: deps},
+	}
+}
+
+func TestVerifyDependencies(t *testing.T) {
+	db := newDependencyTestContainer("8a0c2a3d5e8b", "/db")
+	db.Config.Healthcheck = &containertypes.HealthConfig{Test: []string{"CMD", "true"}}
+	cache := newDependencyTestContainer("1f3e5a7c9b2d", "/cache")
+	// web depends on a container named "app" that does not exist anymore
+	web := newDependencyTestContainer("4b6d8f0a2c4e", "/web", containertypes.Dependency{Container: "app"})
+	proxy := newDependencyTestContainer("7c9e1a3b5d7f", "/proxy", containertypes.Dependency{Container: "web"})
+	daemon := newDependencyTestDaemon(t, db, cache, web, proxy)
+
+	tests := []struct {
+		name    string
+		deps    []containertypes.Dependency
+		wantErr string
+	}{
+		{name: "app"},
+		{name: "app", deps: []containertypes.Dependency{{Container: "db", Condition: containertypes.DependencyConditionHealthy}, {Container: "cache"}}},
+		{name: "", deps: []containertypes.Dependency{{Container: "/db", Condition: containertypes.DependencyConditionStarted}}},
+		{name: "app", deps: []containertypes.Dependency{{Container: "db", Condition: "ready"}}, wantErr: "invalid dependency condition 'ready'"},
+		{name: "app", deps: []containertypes.Dependency{{Container: ""}}, wantErr: "dependency container cannot be empty"},
+		{name: "app", deps: []containertypes.Dependency{{Container: "app"}}, wantErr: "container app cannot depend on itself"},
+		{name: "app", deps: []containertypes.Dependency{{Container: "nothing"}}, wantErr: "invalid dependency nothing"},
+		{name: "app", deps: []containertypes.Dependency{{Container: "cache", Condition: containertypes.DependencyConditionHealthy}}, wantErr: "it has no health check"},
+		{name: "app", deps: []containertypes.Dependency{{Container: "proxy"}}, wantErr: "dependency cycle detected: app -> proxy -> web -> app"},
+		{name: "/app", deps: []containertypes.Dependency{{Container: "web"}}, wantErr: "dependency cycle detected: app -> web -> app"},
+	}
+
+	for _, tc := range tests {
+		err := daemon.verifyDependencies(tc.name, &containertypes.HostConfig{DependsOn: tc.deps})
+		if tc.wantErr == "" {
+			if err != nil {
+				t.Errorf("%s %v: unexpected error: %v", tc.name, tc.deps, err)
+			}
+			continue
+		}
+		if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+			t.Errorf("%s %v: expected error containing %q, got %v", tc.name, tc.deps, tc.wantErr, err)
+		}
+	}
+}
+
+func TestStartDependenciesCycle(t *testing.T) {
+	a := newDependencyTestContainer("2e4a6c8e0b2d", "/alpha", containertypes.Dependency{Container: "beta"})
+	b := newDependencyTestContainer("5f7b9d1f3a5c", "/beta", containertypes.Dependency{Container: "alpha"})
+	daemon := newDependencyTestDaemon(t, a, b)
+
+	err := daemon.startDependencies(context.Background(), a)
+	if err == nil || !strings.Contains(err.Error(), "dependency cycle detected between containers beta and alpha") {
+		t.Fatalf("expected a dependency cycle error, got %v", err)
+	}
+}
+
+func TestWaitForHealthy(t *testing.T) {
+	defer func(interval time.Duration) { dependencyPollInterval = interval }(dependencyPollInterval)
+	dependencyPollInterval = time.Millisecond
+
+	c := newDependencyTestContainer("6a8c0e2b4d6f", "/db")
+	if err := waitForHealthy(context.Background(), c); err == nil || !strings.Contains(err.Error(), "dependency db is not running") {
+		t.Fatalf("expected a not running error, got %v", err)
+	}
+
+	c.Running = true
+	if err := waitForHealthy(context.Background(), c); err == nil || !strings.Contains(err.Error(), "dependency db has no health check") {
+		t.Fatalf("expected a no health check error, got %v", err)
+	}
+
+	c.State.Health = &container.Health{}
+	c.State.Health.Status = types.Unhealthy
+	if err := waitForHealthy(context.Background(), c); err == nil || !strings.Contains(err.Error(), "dependency db is unhealthy") {
+		t.Fatalf("expected an unhealthy error, got %v", err)
+	}
+
+	c.State.Health.Status = types.Starting
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := waitForHealthy(ctx, c); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(10 * time.Millisecond)
+		c.Lock()
+		c.State.Health.Status = types.Healthy
+		c.Unlock()
+	}()
+	if err := waitForHealthy(context.Background(), c); err != nil {
+		t.Fatalf("unexpected error waiting for the container to be healthy: %v", err)
+	}
+}
+
+func TestWaitForRestoredDependencies(t *testing.T) {
+	defer func(interval, timeout time.Duration) {
+		dependencyPollInterval, restoreDependencyTimeout = interval, timeout
+	}(dependencyPollInterval, restoreDependencyTimeout)
+	dependencyPollInterval = time.Millisecond
+	restoreDependencyTimeout = 50 * time.Millisecond
+
+	db := newDependencyTestContainer("3c5e7a9b1d3f", "/db")
+	stopped := newDependencyTestContainer("9d1f3b5c7e9a", "/stopped")
+	web := newDependencyTestContainer("0e2a4c6d8f0b", "/web", containertypes.Dependency{Container: "db", Condition: containertypes.DependencyConditionHealthy})
+	app := newDependencyTestContainer("6b8d0f2a4c6e", "/app", containertypes.Dependency{Container: "stopped"})
+	daemon := newDependencyTestDaemon(t, db, stopped, web, app)
+
+	// A dependency which is not restarted is not started either.
+	if err := daemon.waitForRestoredDependencies(app, map[*container.Container]chan struct{}{}); err == nil || !strings.Contains(err.Error(), "dependency stopped is not running") {
+		t.Fatalf("expected a not running error, got %v", err)
+	}
+	if stopped.IsRunning() {
+		t.Fatal("expected the dependency not to be started")
+	}
+
+	notifier := make(chan struct{})
+	restartContainers := map[*container.Container]chan struct{}{db: notifier, web: make(chan struct{})}
+	go func() {
+		db.Lock()
+		db.Running = true
+		db.State.Health = &container.Health{}
+		db.State.Health.Status = types.Starting
+		db.Unlock()
+		close(notifier)
+	}()
+	// A dependency which never becomes healthy does not block the restore.
+	if err := daemon.waitForRestoredDependencies(web, restartContainers); err != context.DeadlineExceeded {
+		t.Fatalf("expected the wait to time out, got %v", err)
+	}
+
+	db.Lock()
+	db.State.Health.Status = types.Healthy
+	db.Unlock()
+	if err := daemon.waitForRestoredDependencies(web, restartContainers); err != nil {
+		t.Fatalf("unexpected error waiting for the dependencies: %v", err)
+	}
+}
+
+func TestDependencyRemoveAndRename(t *testing.T) {
+	db := newDependencyTestContainer("8e0a2c4e6b8d", "/db")
+	db.NetworkSettings = &network.Settings{}
+	web := newDependencyTestContainer("2b4d6f8a0c2e", "/web", containertypes.Dependency{Container: "db"})
+	web.NetworkSettings = &network.Settings{}
+	daemon := newDependencyTestDaemon(t, db, web)
+
+	if err := daemon.ContainerRm("db", &types.ContainerRmConfig{ForceRemove: true}); err == nil || !strings.Contains(err.Error(), "cannot remove container db: it is a dependency of web") {
+		t.Fatalf("expected a dependency error, got %v", err)
+	}
+	if err := daemon.ContainerRename("db", "database"); err == nil || !strings.Contains(err.Error(), "cannot rename container db: it is a dependency of web") {
+		t.Fatalf("expected a dependency error, got %v", err)
+	}
+
+	// web depends on db by name, renaming another container to db is rejected
+	// as well.
+	db.HostConfig.DependsOn = nil
+	web.HostConfig.DependsOn = []containertypes.Dependency{{Container: "app"}}
+	if err := daemon.ContainerRename("db", "app"); err == nil || !strings.Contains(err.Error(), "cannot rename container db to app: the name is a dependency of web") {
+		t.Fatalf("expected a dependency error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package daemon
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"time"
@@ -9,7 +10,9 @@ import (
 	"github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/api/types/filters"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
 	daemonevents "github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/events/sinks"
 	"github.com/docker/libnetwork"
 	swarmapi "github.com/docker/swarmkit/api"
 	gogotypes "github.com/gogo/protobuf/types"
@@ -330,3 +333,49 @@ func eventTimestamp(meta swarmapi.Meta, action swarmapi.WatchActionKind) time.Ti
 	}
 	return eventTime
 }
+
+// setEventSinks starts forwarding events to the sinks defined in sinkConfigs,
+// and stops forwarding them to the sinks previously set. If a sink can not be
+// created, the previous sinks are kept.
+func (daemon *Daemon) setEventSinks(sinkConfigs []config.EventSinkConfig) error {
+	var forwarders []*sinks.Forwarder
+	for _, c := range sinkConfigs {
+		f, err := sinks.Forward(daemon.EventsService, sinks.Config{
+			Type:    c.Type,
+			Address: c.Address,
+			Filters: eventSinkFilters(c.Filters),
+			Options: c.Options,
+		})
+		if err != nil {
+			for _, f := range forwarders {
+				f.Close()
+			}
+			return fmt.Errorf("error setting up %s event sink: %v", c.Type, err)
+		}
+		forwarders = append(forwarders, f)
+	}
+
+	daemon.closeEventSinks()
+	daemon.eventForwarders = forwarders
+	return nil
+}
+
+// closeEventSinks stops forwarding events to the sinks currently set.
+func (daemon *Daemon) closeEventSinks() {
+	for _, f := range daemon.eventForwarders {
+		if err := f.Close(); err != nil {
+			logrus.Warnf("Error closing event sink: %v", err)
+		}
+	}
+	daemon.eventForwarders = nil
+}
+
+func eventSinkFilters(m map[string][]string) filters.Args {
+	args := filters.NewArgs()
+	for k, values := range m {
+		for _, v := range values {
+			args.Add(k, v)
+		}
+	}
+	return args
+}
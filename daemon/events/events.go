@@ -6,6 +6,7 @@ import (
 
 	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/pkg/pubsub"
+	"github.com/sirupsen/logrus"
 )
 
 const (
@@ -15,9 +16,10 @@ const (
 
 // Events is pubsub channel for events generated by the engine.
 type Events struct {
-	mu     sync.Mutex
-	events []eventtypes.Message
-	pub    *pubsub.Publisher
+	mu      sync.Mutex
+	events  []eventtypes.Message
+	pub     *pubsub.Publisher
+	journal *Journal
 }
 
 // New returns new *Events instance
@@ -28,6 +30,14 @@ func New() *Events {
 	}
 }
 
+// SetJournal makes e persist every event it publishes in j, and serve
+// queries for past events from it instead of the in-memory buffer.
+func (e *Events) SetJournal(j *Journal) {
+	e.mu.Lock()
+	e.journal = j
+	e.mu.Unlock()
+}
+
 // Subscribe adds new listener to events, returns slice of 64 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion), and a function to call
@@ -49,6 +59,10 @@ func (e *Events) Subscribe() ([]eventtypes.Message, chan interface{}, func()) {
 // SubscribeTopic adds new listener to events, returns slice of 64 stored
 // last events, a channel in which you can expect new events (in form
 // of interface{}, so you need type assertion).
+// If there is a journal, past events are read from it after e is unlocked,
+// so that publishing events is not blocked by long queries. They are read
+// from a snapshot of the journal taken while subscribing, so that every event
+// is either returned or sent to the channel, exactly once.
 func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtypes.Message, chan interface{}) {
 	eventSubscribers.Inc()
 	e.mu.Lock()
@@ -60,6 +74,12 @@ func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtype
 
 	buffered := e.loadBufferedEvents(since, until, topic)
 
+	journal := e.journal
+	var segments []journalSegment
+	if journal != nil && (!since.IsZero() || !until.IsZero()) {
+		segments = journal.snapshot()
+	}
+
 	var ch chan interface{}
 	if topic != nil {
 		ch = e.pub.SubscribeTopic(topic)
@@ -69,6 +89,15 @@ func (e *Events) SubscribeTopic(since, until time.Time, ef *Filter) ([]eventtype
 	}
 
 	e.mu.Unlock()
+
+	if segments != nil {
+		evs, err := journal.readSegments(segments, since, until, topic)
+		if err != nil {
+			logrus.Warnf("Error reading events journal, falling back to in-memory events: %v", err)
+		} else {
+			buffered = evs
+		}
+	}
 	return buffered, ch
 }
 
@@ -117,6 +146,11 @@ func (e *Events) PublishMessage(jm eventtypes.Message) {
 	} else {
 		e.events = append(e.events, jm)
 	}
+	if e.journal != nil {
+		if err := e.journal.Write(jm); err != nil {
+			logrus.Warnf("Error writing event to journal: %v", err)
+		}
+	}
 	e.mu.Unlock()
 	e.pub.Publish(jm)
 }
@@ -0,0 +1,287 @@
+package events
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	journalSegmentPrefix = "events-"
+	journalSegmentSuffix = ".log"
+	// journalSegments is the number of segments the maximum journal size and
+	// age are split into. Eviction always drops a whole segment, so a higher
+	// value means finer-grained eviction of old events.
+	journalSegments = 8
+)
+
+// JournalOptions defines the retention settings of an events journal.
+type JournalOptions struct {
+	// MaxSize is the maximum size in bytes the journal may use on disk.
+	// Zero means unbounded.
+	MaxSize int64
+	// MaxAge is the maximum age of the events kept in the journal.
+	// Zero means events never expire.
+	MaxAge time.Duration
+}
+
+// Journal persists events on disk as newline-delimited JSON, split across
+// segment files which are rotated and evicted according to its options.
+type Journal struct {
+	mu       sync.Mutex
+	root     string
+	opts     JournalOptions
+	segments []journalSegment // ordered from oldest to newest
+	f        *os.File
+}
+
+type journalSegment struct {
+	seq      uint64
+	size     int64
+	created  time.Time // when the first event was written to the segment
+	modified time.Time // when the last event was written to the segment
+}
+
+// NewJournal opens the events journal stored in root, creating it if needed.
+// Events already present in the journal are kept, subject to the retention
+// settings in opts.
+func NewJournal(root string, opts JournalOptions) (*Journal, error) {
+	if err := os.MkdirAll(root, 0700); err != nil {
+		return nil, err
+	}
+	j := &Journal{
+		root: root,
+		opts: opts,
+	}
+	if err := j.loadSegments(); err != nil {
+		return nil, err
+	}
+	j.evict()
+	if err := j.openSegment(); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// loadSegments lists the segment files present in the journal directory.
+func (j *Journal) loadSegments() error {
+	files, err := ioutil.ReadDir(j.root)
+	if err != nil {
+		return err
+	}
+	for _, fi := range files {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasPrefix(name, journalSegmentPrefix) || !strings.HasSuffix(name, journalSegmentSuffix) {
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(strings.TrimPrefix(name, journalSegmentPrefix), journalSegmentSuffix), 10, 64)
+		if err != nil {
+			logrus.Warnf("Ignoring unexpected file in events journal: %s", name)
+			continue
+		}
+		// The creation time of a file is not portable, so a segment left
+		// over from a previous run is rotated as if it was created when it
+		// was last written.
+		j.segments = append(j.segments, journalSegment{seq: seq, size: fi.Size(), created: fi.ModTime(), modified: fi.ModTime()})
+	}
+	sort.Slice(j.segments, func(a, b int) bool { return j.segments[a].seq < j.segments[b].seq })
+	return nil
+}
+
+func (j *Journal) segmentPath(seq uint64) string {
+	return filepath.Join(j.root, fmt.Sprintf("%s%020d%s", journalSegmentPrefix, seq, journalSegmentSuffix))
+}
+
+// openSegment opens the newest segment for appending, starting a new one
+// if there is none or the newest one is already full.
+func (j *Journal) openSegment() error {
+	if len(j.segments) == 0 || j.segmentFull(j.segments[len(j.segments)-1]) {
+		var seq uint64
+		if len(j.segments) > 0 {
+			seq = j.segments[len(j.segments)-1].seq + 1
+		}
+		now := time.Now()
+		j.segments = append(j.segments, journalSegment{seq: seq, created: now, modified: now})
+	}
+	last := j.segments[len(j.segments)-1]
+	f, err := os.OpenFile(j.segmentPath(last.seq), os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return err
+	}
+	j.f = f
+	return nil
+}
+
+// segmentFull returns whether s has reached its share of the maximum size
+// or of the maximum age of the journal, so that new events must be written
+// to a new segment. Rotating on age as well as size means the segments of a
+// journal without a maximum size still expire.
+func (j *Journal) segmentFull(s journalSegment) bool {
+	if j.opts.MaxSize > 0 && s.size >= j.opts.MaxSize/journalSegments {
+		return true
+	}
+	return j.opts.MaxAge > 0 && time.Since(s.created) >= j.opts.MaxAge/journalSegments
+}
+
+// evict removes the oldest segments until the journal fits in its maximum
+// size, and any segment which was last written before the maximum age.
+// The newest segment is never removed, but it is rotated before it gets
+// older than the maximum age, see segmentFull.
+func (j *Journal) evict() {
+	var total int64
+	for _, s := range j.segments {
+		total += s.size
+	}
+	for len(j.segments) > 1 {
+		oldest := j.segments[0]
+		p := j.segmentPath(oldest.seq)
+		expired := j.opts.MaxAge > 0 && time.Since(oldest.modified) > j.opts.MaxAge
+		if !expired && (j.opts.MaxSize <= 0 || total <= j.opts.MaxSize) {
+			return
+		}
+		if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+			logrus.Warnf("Error removing events journal segment %s: %v", p, err)
+			return
+		}
+		total -= oldest.size
+		j.segments = j.segments[1:]
+	}
+}
+
+// Write appends an event to the journal, rotating segments as needed and
+// evicting the segments which no longer fit in the retention settings.
+func (j *Journal) Write(ev eventtypes.Message) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return os.ErrClosed
+	}
+
+	last := &j.segments[len(j.segments)-1]
+	if j.segmentFull(*last) {
+		if err := j.f.Close(); err != nil {
+			return err
+		}
+		j.f = nil
+		if err := j.openSegment(); err != nil {
+			return err
+		}
+	}
+	j.evict()
+	last = &j.segments[len(j.segments)-1]
+
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	n, err := j.f.Write(append(b, '\n'))
+	last.size += int64(n)
+	last.modified = time.Now()
+	return err
+}
+
+// Read returns the events stored in the journal that were emitted between
+// since and until, oldest first. A zero since or until leaves that side of
+// the range open. Events are filtered with topic if it's not nil.
+func (j *Journal) Read(since, until time.Time, topic func(interface{}) bool) ([]eventtypes.Message, error) {
+	return j.readSegments(j.snapshot(), since, until, topic)
+}
+
+// snapshot returns the current segments of the journal and their sizes.
+// Reading the snapshot returns the events written before it was taken,
+// even if more events are written in the meantime.
+func (j *Journal) snapshot() []journalSegment {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	segments := make([]journalSegment, len(j.segments))
+	copy(segments, j.segments)
+	return segments
+}
+
+// readSegments reads the events of a snapshot of the journal segments, see
+// Read. It doesn't lock the journal, so that events can be written while a
+// long query is served. Segments evicted since the snapshot was taken are
+// skipped. Events older than the maximum age of the journal are never
+// returned, even if their segment is not evicted yet.
+func (j *Journal) readSegments(segments []journalSegment, since, until time.Time, topic func(interface{}) bool) ([]eventtypes.Message, error) {
+	if j.opts.MaxAge > 0 {
+		if oldest := time.Now().Add(-j.opts.MaxAge); since.Before(oldest) {
+			since = oldest
+		}
+	}
+
+	var sinceNanoUnix, untilNanoUnix int64
+	if !since.IsZero() {
+		sinceNanoUnix = since.UnixNano()
+	}
+	if !until.IsZero() {
+		untilNanoUnix = until.UnixNano()
+	}
+
+	var evs []eventtypes.Message
+	for _, s := range segments {
+		f, err := os.Open(j.segmentPath(s.seq))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		if fi, err := f.Stat(); err == nil && fi.ModTime().Before(since) {
+			// all the events of the segment were written before since
+			f.Close()
+			continue
+		}
+		scanner := bufio.NewScanner(io.LimitReader(f, s.size))
+		scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+		for scanner.Scan() {
+			var ev eventtypes.Message
+			if err := json.Unmarshal(scanner.Bytes(), &ev); err != nil {
+				// a partially written line may be left over from a crash
+				continue
+			}
+			if ev.TimeNano < sinceNanoUnix {
+				continue
+			}
+			if untilNanoUnix > 0 && ev.TimeNano > untilNanoUnix {
+				continue
+			}
+			if topic == nil || topic(ev) {
+				evs = append(evs, ev)
+			}
+		}
+		err = scanner.Err()
+		f.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+	return evs, nil
+}
+
+// Close closes the journal. Writing to a closed journal returns an error.
+func (j *Journal) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.f == nil {
+		return nil
+	}
+	err := j.f.Close()
+	j.f = nil
+	return err
+}
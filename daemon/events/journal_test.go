@@ -0,0 +1,266 @@
+package events
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+)
+
+func TestJournalPersistsAcrossRestart(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, JournalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	e := New()
+	e.SetJournal(j)
+	for i := 0; i < eventsLimit*2; i++ {
+		e.Log("start", events.ContainerEventType, events.Actor{ID: "cont"})
+	}
+	e.Log("die", events.ContainerEventType, events.Actor{ID: "other"})
+	if err := j.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	j, err = NewJournal(root, JournalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	e = New()
+	e.SetJournal(j)
+
+	buffered, l := e.SubscribeTopic(time.Unix(0, 0), time.Time{}, nil)
+	defer e.Evict(l)
+	if len(buffered) != eventsLimit*2+1 {
+		t.Fatalf("expected %d events from the journal, got %d", eventsLimit*2+1, len(buffered))
+	}
+	if buffered[len(buffered)-1].Action != "die" {
+		t.Fatalf("expected the last event to be die, got %s", buffered[len(buffered)-1].Action)
+	}
+
+	f := NewFilter(filters.NewArgs(filters.Arg("container", "other")))
+	buffered, l2 := e.SubscribeTopic(time.Unix(0, 0), time.Time{}, f)
+	defer e.Evict(l2)
+	if len(buffered) != 1 || buffered[0].Actor.ID != "other" {
+		t.Fatalf("expected only the event for container other, got %v", buffered)
+	}
+}
+
+func TestJournalUntil(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, JournalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	for i := int64(1); i <= 10; i++ {
+		if err := j.Write(events.Message{Action: "start", TimeNano: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	evs, err := j.Read(time.Unix(0, 3), time.Unix(0, 6), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 4 || evs[0].TimeNano != 3 || evs[3].TimeNano != 6 {
+		t.Fatalf("unexpected events: %v", evs)
+	}
+}
+
+func TestJournalMaxSize(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	maxSize := int64(8 * 1024)
+	j, err := NewJournal(root, JournalOptions{MaxSize: maxSize})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	for i := int64(1); i <= 1000; i++ {
+		if err := j.Write(events.Message{Action: "start", Type: events.ContainerEventType, TimeNano: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var total int64
+	for _, fi := range files {
+		total += fi.Size()
+	}
+	// the newest segment may go over its share by one event
+	if total > maxSize+maxSize/journalSegments {
+		t.Fatalf("journal uses %d bytes, expected at most %d", total, maxSize)
+	}
+
+	evs, err := j.Read(time.Unix(0, 1), time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) == 0 || len(evs) == 1000 {
+		t.Fatalf("expected the oldest events to be evicted, got %d events", len(evs))
+	}
+	if evs[len(evs)-1].TimeNano != 1000 {
+		t.Fatalf("expected the newest event to be kept, got %v", evs[len(evs)-1])
+	}
+}
+
+func TestJournalMaxAge(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, JournalOptions{MaxSize: 1024})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := int64(1); i <= 10; i++ {
+		if err := j.Write(events.Message{Action: "start", TimeNano: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	j.Close()
+
+	if len(j.segments) < 2 {
+		t.Fatalf("expected the journal to be rotated, got %d segments", len(j.segments))
+	}
+	newest := j.segments[len(j.segments)-1].seq
+	old := time.Now().Add(-2 * time.Hour)
+	for _, s := range j.segments[:len(j.segments)-1] {
+		if err := os.Chtimes(j.segmentPath(s.seq), old, old); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	j, err = NewJournal(root, JournalOptions{MaxSize: 1024, MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	if j.segments[0].seq != newest {
+		t.Fatalf("expected expired segments to be removed, oldest segment is %d", j.segments[0].seq)
+	}
+}
+
+func TestJournalRotateMaxAge(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	maxAge := 400 * time.Millisecond
+	j, err := NewJournal(root, JournalOptions{MaxAge: maxAge})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	write := func(ts int64) {
+		if err := j.Write(events.Message{Action: "start", TimeNano: ts}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write(1)
+	time.Sleep(maxAge / journalSegments * 2)
+	write(2)
+	if len(j.segments) != 2 {
+		t.Fatalf("expected the journal to be rotated without a maximum size, got %d segments", len(j.segments))
+	}
+
+	time.Sleep(maxAge + maxAge/journalSegments)
+	write(3)
+	if len(j.segments) != 1 {
+		t.Fatalf("expected expired segments to be evicted on write, got %d segments", len(j.segments))
+	}
+	files, err := ioutil.ReadDir(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) != 1 {
+		t.Fatalf("expected 1 segment file, got %d", len(files))
+	}
+}
+
+func TestJournalReadMaxAge(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	// Segments are evicted based on when they were written, so events with
+	// an older timestamp are only filtered out when the journal is read.
+	j, err := NewJournal(root, JournalOptions{MaxAge: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+
+	now := time.Now()
+	for _, ts := range []time.Time{now.Add(-3 * time.Hour), now.Add(-2 * time.Hour), now.Add(-time.Minute)} {
+		if err := j.Write(events.Message{Action: "start", TimeNano: ts.UnixNano()}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	evs, err := j.Read(time.Unix(0, 0), time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].TimeNano != now.Add(-time.Minute).UnixNano() {
+		t.Fatalf("expected only the event younger than the maximum age, got %v", evs)
+	}
+}
+
+func TestJournalSnapshot(t *testing.T) {
+	root, err := ioutil.TempDir("", "events-journal")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	j, err := NewJournal(root, JournalOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer j.Close()
+	e := New()
+	e.SetJournal(j)
+
+	e.Log("start", events.ContainerEventType, events.Actor{ID: "before"})
+	segments := j.snapshot()
+	e.Log("start", events.ContainerEventType, events.Actor{ID: "after"})
+
+	evs, err := j.readSegments(segments, time.Unix(0, 0), time.Time{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(evs) != 1 || evs[0].Actor.ID != "before" {
+		t.Fatalf("expected only the event written before the snapshot, got %v", evs)
+	}
+}
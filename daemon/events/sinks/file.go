@@ -0,0 +1,84 @@
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/daemon/logger/loggerutils"
+	units "github.com/docker/go-units"
+)
+
+type fileOptions struct {
+	path     string
+	capacity int64
+	maxFiles int
+}
+
+// parseFileOptions validates the configuration of a file sink.
+// Supported options are max-size and max-file, with the same meaning
+// as for the json-file log driver.
+func parseFileOptions(address string, opts map[string]string) (*fileOptions, error) {
+	if !filepath.IsAbs(address) {
+		return nil, fmt.Errorf("invalid file sink address %q: path must be absolute", address)
+	}
+	o := &fileOptions{
+		path:     address,
+		capacity: -1,
+		maxFiles: 1,
+	}
+	for k, v := range opts {
+		switch k {
+		case "max-size":
+			size, err := units.FromHumanSize(v)
+			if err != nil || size <= 0 {
+				return nil, fmt.Errorf("invalid file sink max-size %q", v)
+			}
+			o.capacity = size
+		case "max-file":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid file sink max-file %q", v)
+			}
+			o.maxFiles = n
+		default:
+			return nil, fmt.Errorf("unknown file sink option %q", k)
+		}
+	}
+	if o.maxFiles > 1 && o.capacity == -1 {
+		return nil, fmt.Errorf("file sink max-file can only be set along with max-size")
+	}
+	return o, nil
+}
+
+// fileSink writes events to a file as newline-delimited JSON.
+type fileSink struct {
+	w *loggerutils.RotateFileWriter
+}
+
+func newFileSink(address string, opts map[string]string) (Sink, error) {
+	o, err := parseFileOptions(address, opts)
+	if err != nil {
+		return nil, err
+	}
+	w, err := loggerutils.NewRotateFileWriter(o.path, o.capacity, o.maxFiles)
+	if err != nil {
+		return nil, err
+	}
+	return &fileSink{w: w}, nil
+}
+
+func (s *fileSink) Send(ev eventtypes.Message) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	_, err = s.w.Write(append(b, '\n'))
+	return err
+}
+
+func (s *fileSink) Close() error {
+	return s.w.Close()
+}
@@ -0,0 +1,89 @@
+package sinks
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/daemon/events"
+)
+
+func TestForwardToFileSink(t *testing.T) {
+	dir, err := ioutil.TempDir("", "event-sinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	p := filepath.Join(dir, "events.log")
+
+	e := events.New()
+	f, err := Forward(e, Config{
+		Type:    TypeFile,
+		Address: p,
+		Filters: filters.NewArgs(filters.Arg("event", "die")),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e.Log("start", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+	e.Log("die", eventtypes.ContainerEventType, eventtypes.Actor{ID: "cont"})
+
+	var evs []eventtypes.Message
+	deadline := time.Now().Add(5 * time.Second)
+	for len(evs) == 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+		evs = readEvents(t, p)
+	}
+	if err := f.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if e.SubscribersCount() != 0 {
+		t.Fatalf("expected the forwarder to unsubscribe, got %d subscribers", e.SubscribersCount())
+	}
+
+	evs = readEvents(t, p)
+	if len(evs) != 1 || evs[0].Action != "die" {
+		t.Fatalf("expected only the die event, got %v", evs)
+	}
+}
+
+func readEvents(t *testing.T, p string) []eventtypes.Message {
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var evs []eventtypes.Message
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		var ev eventtypes.Message
+		if err := json.Unmarshal(s.Bytes(), &ev); err != nil {
+			t.Fatal(err)
+		}
+		evs = append(evs, ev)
+	}
+	return evs
+}
+
+func TestValidateFileSink(t *testing.T) {
+	invalid := []Config{
+		{Type: TypeFile, Address: "relative/events.log"},
+		{Type: TypeFile, Address: "/var/log/events.log", Options: map[string]string{"max-file": "3"}},
+		{Type: TypeFile, Address: "/var/log/events.log", Options: map[string]string{"max-size": "big"}},
+	}
+	for _, c := range invalid {
+		if err := Validate(c); err == nil {
+			t.Fatalf("expected an error for %v", c)
+		}
+	}
+	if err := Validate(Config{Type: TypeFile, Address: "/var/log/events.log", Options: map[string]string{"max-size": "10m", "max-file": "3"}}); err != nil {
+		t.Fatal(err)
+	}
+}
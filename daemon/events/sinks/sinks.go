@@ -0,0 +1,147 @@
+// Package sinks provides forwarding of daemon events to external endpoints,
+// such as an HTTP webhook, a syslog server or a local file.
+package sinks
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/api/types/filters"
+	"github.com/docker/docker/daemon/events"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TypeWebhook is the type of the sink posting events to an HTTP endpoint.
+	TypeWebhook = "webhook"
+	// TypeSyslog is the type of the sink sending events to a syslog endpoint.
+	TypeSyslog = "syslog"
+	// TypeFile is the type of the sink writing events to a rotating file.
+	TypeFile = "file"
+)
+
+// Config defines an event sink.
+type Config struct {
+	// Type is the type of the sink, one of TypeWebhook, TypeSyslog or TypeFile.
+	Type string
+	// Address is the destination of the events. It is the URL of the
+	// webhook, the address of the syslog endpoint, or the path of the file.
+	Address string
+	// Filters selects the events forwarded to the sink, using the same
+	// filters as the events API.
+	Filters filters.Args
+	// Options holds sink specific options.
+	Options map[string]string
+}
+
+// Sink is an endpoint events can be forwarded to.
+type Sink interface {
+	// Send delivers an event to the sink.
+	Send(eventtypes.Message) error
+	// Close releases the resources held by the sink, and aborts any
+	// delivery in progress.
+	Close() error
+}
+
+// New creates the sink defined by c.
+func New(c Config) (Sink, error) {
+	switch c.Type {
+	case TypeWebhook:
+		return newWebhookSink(c.Address, c.Options)
+	case TypeSyslog:
+		return newSyslogSink(c.Address, c.Options)
+	case TypeFile:
+		return newFileSink(c.Address, c.Options)
+	default:
+		return nil, fmt.Errorf("unknown event sink type: %q", c.Type)
+	}
+}
+
+// Validate checks that c defines a valid sink, without creating it.
+func Validate(c Config) error {
+	switch c.Type {
+	case TypeWebhook:
+		_, err := parseWebhookOptions(c.Address, c.Options)
+		return err
+	case TypeSyslog:
+		_, err := parseSyslogOptions(c.Address, c.Options)
+		return err
+	case TypeFile:
+		_, err := parseFileOptions(c.Address, c.Options)
+		return err
+	default:
+		return fmt.Errorf("unknown event sink type: %q", c.Type)
+	}
+}
+
+// Forwarder subscribes to an events service and forwards the matching
+// events to a sink.
+type Forwarder struct {
+	events *events.Events
+	sink   Sink
+	typ    string
+	l      chan interface{}
+	once   sync.Once
+	stop   chan struct{}
+	done   chan struct{}
+}
+
+// Forward starts forwarding the events published by e that match c's
+// filters to the sink defined by c.
+func Forward(e *events.Events, c Config) (*Forwarder, error) {
+	s, err := New(c)
+	if err != nil {
+		return nil, err
+	}
+	_, l := e.SubscribeTopic(time.Time{}, time.Time{}, events.NewFilter(c.Filters))
+	f := &Forwarder{
+		events: e,
+		sink:   s,
+		typ:    c.Type,
+		l:      l,
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	go f.run()
+	return f, nil
+}
+
+func (f *Forwarder) run() {
+	defer close(f.done)
+	for {
+		select {
+		case <-f.stop:
+			return
+		case ev, ok := <-f.l:
+			if !ok {
+				return
+			}
+			msg, ok := ev.(eventtypes.Message)
+			if !ok {
+				continue
+			}
+			if err := f.sink.Send(msg); err != nil {
+				select {
+				case <-f.stop:
+				default:
+					logrus.Warnf("Error forwarding event to %s sink: %v", f.typ, err)
+				}
+			}
+		}
+	}
+}
+
+// Close stops forwarding events and closes the sink. Events still queued
+// for the sink are dropped.
+func (f *Forwarder) Close() error {
+	var err error
+	f.once.Do(func() {
+		close(f.stop)
+		err = f.sink.Close()
+		<-f.done
+		f.events.Evict(f.l)
+	})
+	return err
+}
@@ -0,0 +1,77 @@
+package sinks
+
+import (
+	"encoding/json"
+	"fmt"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/daemon/logger"
+	"github.com/docker/docker/daemon/logger/syslog"
+)
+
+const defaultSyslogTag = "docker-events"
+
+// syslogSinkOptions maps the options of a syslog sink to the options of the
+// syslog log driver which is used to deliver the events.
+var syslogSinkOptions = map[string]string{
+	"facility":        "syslog-facility",
+	"format":          "syslog-format",
+	"tag":             "tag",
+	"tls-ca-cert":     "syslog-tls-ca-cert",
+	"tls-cert":        "syslog-tls-cert",
+	"tls-key":         "syslog-tls-key",
+	"tls-skip-verify": "syslog-tls-skip-verify",
+}
+
+// parseSyslogOptions validates the configuration of a syslog sink, and
+// returns the equivalent syslog log driver configuration. An empty address
+// sends events to the local syslog daemon.
+func parseSyslogOptions(address string, opts map[string]string) (map[string]string, error) {
+	cfg := map[string]string{
+		"syslog-address": address,
+		"tag":            defaultSyslogTag,
+	}
+	for k, v := range opts {
+		o, ok := syslogSinkOptions[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown syslog sink option %q", k)
+		}
+		cfg[o] = v
+	}
+	if err := syslog.ValidateLogOpt(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// syslogSink sends each event as a JSON document to a syslog endpoint.
+type syslogSink struct {
+	l logger.Logger
+}
+
+func newSyslogSink(address string, opts map[string]string) (Sink, error) {
+	cfg, err := parseSyslogOptions(address, opts)
+	if err != nil {
+		return nil, err
+	}
+	l, err := syslog.New(logger.Info{Config: cfg})
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{l: l}, nil
+}
+
+func (s *syslogSink) Send(ev eventtypes.Message) error {
+	b, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	msg := logger.NewMessage()
+	msg.Line = b
+	msg.Source = "stdout"
+	return s.l.Log(msg)
+}
+
+func (s *syslogSink) Close() error {
+	return s.l.Close()
+}
@@ -0,0 +1,160 @@
+package sinks
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+	"golang.org/x/net/context"
+)
+
+const (
+	// SignatureHeader is the header holding the HMAC-SHA256 signature of
+	// the body of the requests sent by a webhook sink configured with a secret.
+	SignatureHeader = "X-Docker-Event-Signature"
+
+	defaultWebhookRetries = 5
+	defaultWebhookTimeout = 10 * time.Second
+	webhookInitialBackoff = 100 * time.Millisecond
+	webhookMaxBackoff     = 30 * time.Second
+)
+
+type webhookOptions struct {
+	url        string
+	secret     []byte
+	maxRetries int
+	timeout    time.Duration
+}
+
+// parseWebhookOptions validates the configuration of a webhook sink.
+// Supported options are secret, max-retries and timeout.
+func parseWebhookOptions(address string, opts map[string]string) (*webhookOptions, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook sink address %q: %v", address, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid webhook sink address %q: scheme must be http or https", address)
+	}
+	o := &webhookOptions{
+		url:        address,
+		maxRetries: defaultWebhookRetries,
+		timeout:    defaultWebhookTimeout,
+	}
+	for k, v := range opts {
+		switch k {
+		case "secret":
+			o.secret = []byte(v)
+		case "max-retries":
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("invalid webhook sink max-retries %q", v)
+			}
+			o.maxRetries = n
+		case "timeout":
+			d, err := time.ParseDuration(v)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid webhook sink timeout %q", v)
+			}
+			o.timeout = d
+		default:
+			return nil, fmt.Errorf("unknown webhook sink option %q", k)
+		}
+	}
+	return o, nil
+}
+
+// webhookSink posts each event as a JSON document to an HTTP endpoint,
+// retrying with exponential backoff on network errors, 429 and 5xx responses.
+// Closing the sink cancels the request in flight, if any, and the retries.
+type webhookSink struct {
+	opts   *webhookOptions
+	client *http.Client
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+func newWebhookSink(address string, opts map[string]string) (Sink, error) {
+	o, err := parseWebhookOptions(address, opts)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &webhookSink{
+		opts:   o,
+		client: &http.Client{Timeout: o.timeout},
+		ctx:    ctx,
+		cancel: cancel,
+	}, nil
+}
+
+// Sign returns the value of the SignatureHeader for body signed with secret.
+func Sign(secret, body []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+func (s *webhookSink) Send(ev eventtypes.Message) error {
+	body, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+
+	backoff := webhookInitialBackoff
+	for attempt := 0; ; attempt++ {
+		retry, err := s.post(body)
+		if err == nil {
+			return nil
+		}
+		if !retry || attempt >= s.opts.maxRetries {
+			return err
+		}
+		select {
+		case <-s.ctx.Done():
+			return err
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > webhookMaxBackoff {
+			backoff = webhookMaxBackoff
+		}
+	}
+}
+
+// post sends body to the webhook, and returns whether the request should
+// be retried if it failed.
+func (s *webhookSink) post(body []byte) (bool, error) {
+	req, err := http.NewRequest("POST", s.opts.url, bytes.NewReader(body))
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(s.opts.secret) > 0 {
+		req.Header.Set(SignatureHeader, Sign(s.opts.secret, body))
+	}
+	resp, err := s.client.Do(req.WithContext(s.ctx))
+	if err != nil {
+		// don't retry requests canceled by Close
+		return s.ctx.Err() == nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("webhook %s responded with status %s", s.opts.url, resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
+
+func (s *webhookSink) Close() error {
+	s.cancel()
+	return nil
+}
@@ -0,0 +1,124 @@
+package sinks
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	eventtypes "github.com/docker/docker/api/types/events"
+)
+
+func TestWebhookSinkSignsAndRetries(t *testing.T) {
+	var attempts int32
+	received := make(chan eventtypes.Message, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		body, err := ioutil.ReadAll(r.Body)
+		if err != nil {
+			t.Error(err)
+		}
+		if sig := r.Header.Get(SignatureHeader); sig != Sign([]byte("s3cr3t"), body) {
+			t.Errorf("unexpected signature %q", sig)
+		}
+		var ev eventtypes.Message
+		if err := json.Unmarshal(body, &ev); err != nil {
+			t.Error(err)
+		}
+		received <- ev
+	}))
+	defer srv.Close()
+
+	s, err := New(Config{Type: TypeWebhook, Address: srv.URL, Options: map[string]string{"secret": "s3cr3t"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Send(eventtypes.Message{Action: "die", Type: eventtypes.ContainerEventType}); err != nil {
+		t.Fatal(err)
+	}
+	ev := <-received
+	if ev.Action != "die" {
+		t.Fatalf("expected die event, got %v", ev)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Fatalf("expected 3 attempts, got %d", n)
+	}
+}
+
+func TestWebhookSinkDoesNotRetryClientErrors(t *testing.T) {
+	var attempts int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&attempts, 1)
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	s, err := New(Config{Type: TypeWebhook, Address: srv.URL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer s.Close()
+
+	if err := s.Send(eventtypes.Message{Action: "die"}); err == nil {
+		t.Fatal("expected an error")
+	}
+	if n := atomic.LoadInt32(&attempts); n != 1 {
+		t.Fatalf("expected 1 attempt, got %d", n)
+	}
+}
+
+func TestWebhookSinkCloseCancelsRequest(t *testing.T) {
+	hung := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-hung
+	}))
+	defer srv.Close()
+	defer close(hung)
+
+	s, err := New(Config{Type: TypeWebhook, Address: srv.URL, Options: map[string]string{"timeout": "1m"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	sent := make(chan error, 1)
+	go func() {
+		sent <- s.Send(eventtypes.Message{Action: "die"})
+	}()
+	time.Sleep(100 * time.Millisecond)
+	if err := s.Close(); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case err := <-sent:
+		if err == nil {
+			t.Fatal("expected the canceled request to fail")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("closing the sink did not cancel the request in flight")
+	}
+}
+
+func TestValidateWebhookSink(t *testing.T) {
+	invalid := []Config{
+		{Type: TypeWebhook, Address: "ftp://example.com"},
+		{Type: TypeWebhook, Address: "http://example.com", Options: map[string]string{"max-retries": "-1"}},
+		{Type: TypeWebhook, Address: "http://example.com", Options: map[string]string{"unknown": "1"}},
+		{Type: "carrier-pigeon", Address: "http://example.com"},
+	}
+	for _, c := range invalid {
+		if err := Validate(c); err == nil {
+			t.Fatalf("expected an error for %v", c)
+		}
+	}
+	if err := Validate(Config{Type: TypeWebhook, Address: "https://example.com/hook", Options: map[string]string{"timeout": "5s"}}); err != nil {
+		t.Fatal(err)
+	}
+}
@@ -3,7 +3,11 @@ package daemon
 import (
 	"fmt"
 	"io"
+	"runtime"
+	"sort"
+	"strconv"
 	"strings"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/context"
@@ -102,6 +106,10 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	cmd := strslice.StrSlice(config.Cmd)
 	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmd)
 
+	if config.Timeout < 0 {
+		return "", validationError{errors.New("exec timeout cannot be negative")}
+	}
+
 	keys := []byte{}
 	if config.DetachKeys != "" {
 		keys, err = term.ToBytes(config.DetachKeys)
@@ -122,6 +130,7 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	execConfig.Tty = config.Tty
 	execConfig.Privileged = config.Privileged
 	execConfig.User = config.User
+	execConfig.Timeout = time.Duration(config.Timeout) * time.Second
 
 	linkedEnv, err := d.setupLinkedContainers(cntr)
 	if err != nil {
@@ -139,6 +148,33 @@ func (d *Daemon) ContainerExecCreate(name string, config *types.ExecConfig) (str
 	return execConfig.ID, nil
 }
 
+// newInternalExecConfig sets up an exec instance the daemon runs in the
+// container on its own behalf, such as a health check probe.
+func (d *Daemon) newInternalExecConfig(c *container.Container, cmd strslice.StrSlice) (*exec.Config, error) {
+	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmd)
+	execConfig := exec.NewConfig()
+	execConfig.OpenStdin = false
+	execConfig.OpenStdout = true
+	execConfig.OpenStderr = true
+	execConfig.ContainerID = c.ID
+	execConfig.DetachKeys = []byte{}
+	execConfig.Entrypoint = entrypoint
+	execConfig.Args = args
+	execConfig.Tty = false
+	execConfig.Privileged = false
+	execConfig.User = c.Config.User
+
+	linkedEnv, err := d.setupLinkedContainers(c)
+	if err != nil {
+		return nil, err
+	}
+	execConfig.Env = container.ReplaceOrAppendEnvValues(c.CreateDaemonEnvironment(execConfig.Tty, linkedEnv), execConfig.Env)
+
+	d.registerExecCommand(c, execConfig)
+	d.LogContainerEvent(c, "exec_create: "+execConfig.Entrypoint+" "+strings.Join(execConfig.Args, " "))
+	return execConfig, nil
+}
+
 // ContainerExecStart starts a previously set up exec instance. The
 // std streams are set up.
 // If ctx is cancelled, the process is terminated.
@@ -237,6 +273,12 @@ func (d *Daemon) ContainerExecStart(ctx context.Context, name string, stdin io.R
 	}
 	ec.Lock()
 	ec.Pid = systemPid
+	// the timeout is stopped when the process exits, unless it already did
+	if ec.Timeout > 0 && ec.Running {
+		ec.StartTimeout(func() {
+			d.execTimeout(c, ec)
+		})
+	}
 	ec.Unlock()
 
 	select {
@@ -262,6 +304,90 @@ func (d *Daemon) ContainerExecStart(ctx context.Context, name string, stdin io.R
 	return nil
 }
 
+// execTimeout kills an exec process that ran for longer than its timeout.
+func (d *Daemon) execTimeout(c *container.Container, ec *exec.Config) {
+	ec.Lock()
+	running := ec.Running
+	ec.Unlock()
+	if !running {
+		return
+	}
+	logrus.Infof("Container %v, process %v exceeded its timeout of %s - killing it", c.ID, ec.ID, ec.Timeout)
+	if err := d.containerd.SignalProcess(c.ID, ec.ID, int(signal.SignalMap["KILL"])); err != nil {
+		logrus.Warnf("Failed to kill exec %s in container %s: %v", ec.ID, c.ID, err)
+		return
+	}
+	d.LogContainerEventWithAttributes(c, "exec_kill", map[string]string{
+		"execID": ec.ID,
+		"signal": strconv.Itoa(int(signal.SignalMap["KILL"])),
+		"reason": "timeout",
+	})
+}
+
+// ContainerExecKill sends a signal to a running exec process. If no signal
+// is given (sig 0), the process is killed with SIGKILL.
+func (d *Daemon) ContainerExecKill(name string, sig uint64) error {
+	ec, err := d.getExecConfig(name)
+	if err != nil {
+		return err
+	}
+	if sig == 0 {
+		sig = uint64(signal.SignalMap["KILL"])
+	}
+	if !signal.ValidSignalForPlatform(syscall.Signal(sig)) {
+		return validationError{fmt.Errorf("The %s daemon does not support signal %d", runtime.GOOS, sig)}
+	}
+
+	ec.Lock()
+	running := ec.Running
+	ec.Unlock()
+	if !running {
+		return stateConflictError{fmt.Errorf("Exec %s is not running", ec.ID)}
+	}
+
+	c := d.containers.Get(ec.ContainerID)
+	if c == nil {
+		return errExecNotFound(name)
+	}
+	if err := d.containerd.SignalProcess(c.ID, ec.ID, int(sig)); err != nil {
+		return errors.Wrapf(systemError{err}, "Cannot kill exec %s", ec.ID)
+	}
+	d.LogContainerEventWithAttributes(c, "exec_kill", map[string]string{
+		"execID": ec.ID,
+		"signal": strconv.FormatUint(sig, 10),
+	})
+	return nil
+}
+
+// ContainerExecList returns the exec instances of a container, including the
+// ones which have exited but have not been cleaned up yet.
+func (d *Daemon) ContainerExecList(name string) ([]types.ContainerExecInspect, error) {
+	c, err := d.GetContainer(name)
+	if err != nil {
+		return nil, err
+	}
+	execs := []types.ContainerExecInspect{}
+	for _, e := range d.execCommands.Commands() {
+		if e.ContainerID != c.ID {
+			continue
+		}
+		e.Lock()
+		ei := types.ContainerExecInspect{
+			ExecID:      e.ID,
+			ContainerID: e.ContainerID,
+			Running:     e.Running,
+			Pid:         e.Pid,
+		}
+		if e.ExitCode != nil {
+			ei.ExitCode = *e.ExitCode
+		}
+		e.Unlock()
+		execs = append(execs, ei)
+	}
+	sort.Slice(execs, func(i, j int) bool { return execs[i].ExecID < execs[j].ExecID })
+	return execs, nil
+}
+
 // execCommandGC runs a ticker to clean up the daemon references
 // of exec configs that are no longer part of the container.
 func (d *Daemon) execCommandGC() {
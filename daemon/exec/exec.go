@@ -3,6 +3,7 @@ package exec
 import (
 	"runtime"
 	"sync"
+	"time"
 
 	"github.com/docker/docker/container/stream"
 	"github.com/docker/docker/libcontainerd"
@@ -32,6 +33,8 @@ type Config struct {
 	User         string
 	Env          []string
 	Pid          int
+	Timeout      time.Duration
+	timer        *time.Timer
 }
 
 // NewConfig initializes the a new exec configuration
@@ -42,6 +45,21 @@ func NewConfig() *Config {
 	}
 }
 
+// StartTimeout calls f once Timeout has elapsed, unless StopTimeout is
+// called first. It must be called with c locked.
+func (c *Config) StartTimeout(f func()) {
+	c.timer = time.AfterFunc(c.Timeout, f)
+}
+
+// StopTimeout stops the timeout started by StartTimeout, if any. It must be
+// called with c locked.
+func (c *Config) StopTimeout() {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+}
+
 // InitializeStdio is called by libcontainerd to connect the stdio.
 func (c *Config) InitializeStdio(iop libcontainerd.IOPipe) error {
 	c.StreamConfig.CopyToPipe(iop)
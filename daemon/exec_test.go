@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"syscall"
+	"testing"
+	"time"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	eventtypes "github.com/docker/docker/api/types/events"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/daemon/exec"
+	"github.com/docker/docker/libcontainerd"
+)
+
+type processSignal struct {
+	processID string
+	sig       int
+}
+
+type processSignalRecorder struct {
+	libcontainerd.Client
+	signals chan processSignal
+}
+
+func (r *processSignalRecorder) SignalProcess(containerID string, processFriendlyName string, sig int) error {
+	r.signals <- processSignal{processID: processFriendlyName, sig: sig}
+	return nil
+}
+
+func newExecTestDaemon() (*Daemon, *container.Container, *processSignalRecorder) {
+	c := &container.Container{
+		ID:           "exec_container",
+		Name:         "/exec_container",
+		Config:       &containertypes.Config{},
+		State:        container.NewState(),
+		ExecCommands: exec.NewStore(),
+	}
+	c.State.Running = true
+	client := &processSignalRecorder{signals: make(chan processSignal, 1)}
+	d := &Daemon{
+		containers:    container.NewMemoryStore(),
+		execCommands:  exec.NewStore(),
+		EventsService: events.New(),
+		containerd:    client,
+	}
+	d.containers.Add(c.ID, c)
+	return d, c, client
+}
+
+func newTestExec(d *Daemon, c *container.Container, id string, running bool) *exec.Config {
+	ec := exec.NewConfig()
+	ec.ID = id
+	ec.ContainerID = c.ID
+	ec.Running = running
+	d.registerExecCommand(c, ec)
+	return ec
+}
+
+func TestContainerExecList(t *testing.T) {
+	d, c, _ := newExecTestDaemon()
+	other := &container.Container{ID: "other_container", State: container.NewState(), ExecCommands: exec.NewStore()}
+	d.containers.Add(other.ID, other)
+
+	newTestExec(d, c, "exec_b", true)
+	newTestExec(d, c, "exec_a", false)
+	newTestExec(d, other, "exec_c", true)
+
+	execs, err := d.ContainerExecList(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(execs) != 2 || execs[0].ExecID != "exec_a" || execs[1].ExecID != "exec_b" {
+		t.Fatalf("unexpected execs: %+v", execs)
+	}
+	if execs[0].Running || !execs[1].Running {
+		t.Fatalf("unexpected exec states: %+v", execs)
+	}
+}
+
+func TestContainerExecKill(t *testing.T) {
+	d, c, client := newExecTestDaemon()
+	_, l, _ := d.EventsService.Subscribe()
+	defer d.EventsService.Evict(l)
+
+	newTestExec(d, c, "running_exec", true)
+	newTestExec(d, c, "exited_exec", false)
+
+	if err := d.ContainerExecKill("running_exec", uint64(syscall.SIGTERM)); err != nil {
+		t.Fatal(err)
+	}
+	if s := <-client.signals; s.processID != "running_exec" || s.sig != int(syscall.SIGTERM) {
+		t.Fatalf("unexpected signal: %+v", s)
+	}
+	ev := (<-l).(eventtypes.Message)
+	if ev.Action != "exec_kill" || ev.Actor.Attributes["execID"] != "running_exec" || ev.Actor.Attributes["signal"] != "15" {
+		t.Fatalf("unexpected event: %+v", ev)
+	}
+
+	if err := d.ContainerExecKill("exited_exec", 0); err == nil {
+		t.Fatal("expected an error killing an exec which is not running")
+	}
+	if err := d.ContainerExecKill("missing_exec", 0); err == nil {
+		t.Fatal("expected an error killing a missing exec")
+	}
+}
+
+func TestExecTimeout(t *testing.T) {
+	d, c, client := newExecTestDaemon()
+
+	ec := newTestExec(d, c, "slow_exec", true)
+	ec.Timeout = time.Second
+	d.execTimeout(c, ec)
+	if s := <-client.signals; s.processID != "slow_exec" || s.sig != int(syscall.SIGKILL) {
+		t.Fatalf("unexpected signal: %+v", s)
+	}
+
+	ec.Running = false
+	d.execTimeout(c, ec)
+	select {
+	case s := <-client.signals:
+		t.Fatalf("expected an exited exec not to be signaled, got %+v", s)
+	default:
+	}
+
+	// a stopped timeout never fires
+	fired := make(chan struct{})
+	ec.Timeout = 10 * time.Millisecond
+	ec.StartTimeout(func() { close(fired) })
+	ec.StopTimeout()
+	select {
+	case <-fired:
+		t.Fatal("expected the stopped timeout not to fire")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"fmt"
 	"runtime"
+	"strconv"
 	"strings"
 	"sync"
+	"syscall"
 	"time"
 
 	"golang.org/x/net/context"
@@ -14,7 +16,6 @@ import (
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/api/types/strslice"
 	"github.com/docker/docker/container"
-	"github.com/docker/docker/daemon/exec"
 	"github.com/sirupsen/logrus"
 )
 
@@ -40,6 +41,9 @@ const (
 
 	// Maximum number of entries to record
 	maxLogEntries = 5
+
+	// Longest probe output included in health_status events. Longer outputs will be truncated.
+	maxEventOutputLen = 256
 )
 
 const (
@@ -68,27 +72,10 @@ func (p *cmdProbe) run(ctx context.Context, d *Daemon, cntr *container.Container
 	if p.shell {
 		cmdSlice = append(getShell(cntr.Config), cmdSlice...)
 	}
-	entrypoint, args := d.getEntrypointAndArgs(strslice.StrSlice{}, cmdSlice)
-	execConfig := exec.NewConfig()
-	execConfig.OpenStdin = false
-	execConfig.OpenStdout = true
-	execConfig.OpenStderr = true
-	execConfig.ContainerID = cntr.ID
-	execConfig.DetachKeys = []byte{}
-	execConfig.Entrypoint = entrypoint
-	execConfig.Args = args
-	execConfig.Tty = false
-	execConfig.Privileged = false
-	execConfig.User = cntr.Config.User
-
-	linkedEnv, err := d.setupLinkedContainers(cntr)
+	execConfig, err := d.newInternalExecConfig(cntr, cmdSlice)
 	if err != nil {
 		return nil, err
 	}
-	execConfig.Env = container.ReplaceOrAppendEnvValues(cntr.CreateDaemonEnvironment(execConfig.Tty, linkedEnv), execConfig.Env)
-
-	d.registerExecCommand(cntr, execConfig)
-	d.LogContainerEvent(cntr, "exec_create: "+execConfig.Entrypoint+" "+strings.Join(execConfig.Args, " "))
 
 	output := &limitedBuffer{}
 	err = d.ContainerExecStart(ctx, execConfig.ID, nil, output, output)
@@ -173,11 +160,47 @@ func handleProbeResult(d *Daemon, c *container.Container, result *types.Healthch
 		logrus.Errorf("Error replicating health state for container %s: %v", c.ID, err)
 	}
 
+	healthCtr.set(c.ID, healthSample{
+		name:          strings.TrimPrefix(c.Name, "/"),
+		status:        h.Status,
+		failingStreak: h.FailingStreak,
+		duration:      result.End.Sub(result.Start),
+	})
+
 	if oldStatus != h.Status {
-		d.LogContainerEvent(c, "health_status: "+h.Status)
+		d.LogContainerEventWithAttributes(c, "health_status: "+h.Status, map[string]string{
+			"exitCode": strconv.Itoa(result.ExitCode),
+			"output":   truncateOutput(result.Output, maxEventOutputLen),
+		})
+
+		if h.Status == types.Unhealthy && c.HostConfig != nil && c.HostConfig.RestartPolicy.IsOnUnhealthy() {
+			go d.restartUnhealthy(c)
+		}
+	}
+}
+
+// restartUnhealthy kills a container marked unhealthy by its health check.
+// Unlike a kill requested by the user, the restart manager is left active,
+// so that it restarts the container when it exits.
+func (d *Daemon) restartUnhealthy(c *container.Container) {
+	logrus.Infof("Container %s is unhealthy, restarting it", c.ID)
+	c.Lock()
+	c.SetTerminationRequest(types.TerminationHealth, int(syscall.SIGKILL))
+	c.Unlock()
+	if err := d.kill(c, int(syscall.SIGKILL)); err != nil {
+		logrus.Warnf("Failed to kill unhealthy container %s: %v", c.ID, err)
 	}
 }
 
+// truncateOutput returns the first maxLen bytes of out, with "..." appended
+// if it was truncated.
+func truncateOutput(out string, maxLen int) string {
+	if len(out) <= maxLen {
+		return out
+	}
+	return out[:maxLen] + "..."
+}
+
 // Run the container's monitoring thread until notified via "stop".
 // There is never more than one monitor thread running per container at a time.
 func monitor(d *Daemon, c *container.Container, stop chan struct{}, probe probe) {
@@ -253,8 +276,12 @@ func getProbe(c *container.Container) probe {
 		return &cmdProbe{shell: false}
 	case "CMD-SHELL":
 		return &cmdProbe{shell: true}
+	case "HTTP":
+		return &httpProbe{}
+	case "TCP":
+		return &tcpProbe{}
 	default:
-		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD') in container %s", config.Test[0], c.ID)
+		logrus.Warnf("Unknown healthcheck type '%s' (expected 'CMD', 'HTTP' or 'TCP') in container %s", config.Test[0], c.ID)
 		return nil
 	}
 }
@@ -304,13 +331,28 @@ func (d *Daemon) initHealthMonitor(c *container.Container) {
 	d.updateHealthMonitor(c)
 }
 
+// Restart the health monitor of a running container after its healthcheck
+// configuration was updated, or remove its health state if the healthcheck
+// was disabled.
+// Called with c locked.
+func (d *Daemon) resetHealthMonitor(c *container.Container) {
+	if getProbe(c) == nil {
+		d.stopHealthchecks(c)
+		c.State.Health = nil
+		return
+	}
+	d.initHealthMonitor(c)
+}
+
 // Called when the container is being stopped (whether because the health check is
-// failing or for any other reason).
+// failing or for any other reason). The health metrics of the container are
+// removed until the next probe result.
 func (d *Daemon) stopHealthchecks(c *container.Container) {
 	h := c.State.Health
 	if h != nil {
 		h.CloseMonitorChannel()
 	}
+	healthCtr.del(c.ID)
 }
 
 // Buffer up to maxOutputLen bytes. Further data is discarded.
@@ -0,0 +1,196 @@
+package daemon
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+)
+
+const (
+	// Exit code reported for a failed HTTP or TCP probe.
+	exitStatusUnhealthy = 1
+
+	// Status codes accepted by an HTTP probe that does not specify a range.
+	defaultHTTPProbeStatus = "200-399"
+)
+
+// httpCheck is the parsed configuration of an HTTP probe. Its Test is of the
+// form ["HTTP", url, status range, body match], with the last two optional.
+type httpCheck struct {
+	url       string
+	minStatus int
+	maxStatus int
+	body      string
+}
+
+func parseHTTPCheck(test []string) (*httpCheck, error) {
+	if len(test) < 2 || len(test) > 4 {
+		return nil, errors.New("HTTP healthcheck takes a URL, and optionally a status range and a body match")
+	}
+	u, err := url.Parse(test[1])
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid HTTP healthcheck URL %q", test[1])
+	}
+	if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, errors.Errorf("invalid HTTP healthcheck URL %q: must be an absolute http or https URL", test[1])
+	}
+	// Host names would be resolved with the resolver configuration of the
+	// host, not of the container.
+	if host := u.Hostname(); host != "localhost" && net.ParseIP(host) == nil {
+		return nil, errors.Errorf("invalid HTTP healthcheck URL %q: the host must be localhost or an IP address", test[1])
+	}
+	check := &httpCheck{url: test[1]}
+	status := defaultHTTPProbeStatus
+	if len(test) > 2 && test[2] != "" {
+		status = test[2]
+	}
+	if check.minStatus, check.maxStatus, err = parseStatusRange(status); err != nil {
+		return nil, err
+	}
+	if len(test) > 3 {
+		check.body = test[3]
+	}
+	return check, nil
+}
+
+// parseStatusRange parses a status code, or an inclusive range of status
+// codes such as "200-299".
+func parseStatusRange(s string) (int, int, error) {
+	parts := strings.SplitN(s, "-", 2)
+	bounds := make([]int, len(parts))
+	for i, p := range parts {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 100 || n > 599 {
+			return 0, 0, errors.Errorf("invalid HTTP healthcheck status range %q", s)
+		}
+		bounds[i] = n
+	}
+	if len(bounds) == 1 {
+		return bounds[0], bounds[0], nil
+	}
+	if bounds[0] > bounds[1] {
+		return 0, 0, errors.Errorf("invalid HTTP healthcheck status range %q", s)
+	}
+	return bounds[0], bounds[1], nil
+}
+
+// parseTCPCheck returns the port checked by a TCP probe. Its Test is of the
+// form ["TCP", port].
+func parseTCPCheck(test []string) (string, error) {
+	if len(test) != 2 {
+		return "", errors.New("TCP healthcheck takes a single port")
+	}
+	port, err := strconv.Atoi(test[1])
+	if err != nil || port < 1 || port > 65535 {
+		return "", errors.Errorf("invalid TCP healthcheck port %q", test[1])
+	}
+	return test[1], nil
+}
+
+// validateHealthcheckTest checks the test of a healthcheck configuration.
+// Only the HTTP and TCP probes are checked, the other probes being validated
+// when they run.
+func validateHealthcheckTest(test []string) error {
+	if len(test) == 0 {
+		return nil
+	}
+	switch test[0] {
+	case "HTTP":
+		_, err := parseHTTPCheck(test)
+		return err
+	case "TCP":
+		_, err := parseTCPCheck(test)
+		return err
+	}
+	return nil
+}
+
+// httpProbe implements the "HTTP" probe type. It sends a GET request from
+// the container's network namespace, which passes if the response status is
+// in the expected range and its body contains the expected string, if any.
+type httpProbe struct{}
+
+func (p *httpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	check, err := parseHTTPCheck(cntr.Config.Healthcheck.Test)
+	if err != nil {
+		return nil, err
+	}
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext:       containerDialer(cntr),
+			DisableKeepAlives: true,
+		},
+		// redirects are checked against the expected status range
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+	req, err := http.NewRequest("GET", check.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		return probeFailure(err.Error()), nil
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxOutputLen))
+	if err != nil {
+		return probeFailure(err.Error()), nil
+	}
+
+	out := fmt.Sprintf("GET %s: %s", check.url, resp.Status)
+	if resp.StatusCode < check.minStatus || resp.StatusCode > check.maxStatus {
+		return probeFailure(fmt.Sprintf("%s, expected status in range %d-%d", out, check.minStatus, check.maxStatus)), nil
+	}
+	if check.body != "" && !strings.Contains(string(body), check.body) {
+		return probeFailure(fmt.Sprintf("%s, response body does not contain %q", out, check.body)), nil
+	}
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitStatusHealthy,
+		Output:   out,
+	}, nil
+}
+
+// tcpProbe implements the "TCP" probe type. It passes if a connection to the
+// port can be opened from the container's network namespace.
+type tcpProbe struct{}
+
+func (p *tcpProbe) run(ctx context.Context, d *Daemon, cntr *container.Container) (*types.HealthcheckResult, error) {
+	port, err := parseTCPCheck(cntr.Config.Healthcheck.Test)
+	if err != nil {
+		return nil, err
+	}
+	addr := net.JoinHostPort("127.0.0.1", port)
+	conn, err := containerDialer(cntr)(ctx, "tcp", addr)
+	if err != nil {
+		return probeFailure(err.Error()), nil
+	}
+	conn.Close()
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitStatusHealthy,
+		Output:   "connected to " + addr,
+	}, nil
+}
+
+func probeFailure(out string) *types.HealthcheckResult {
+	return &types.HealthcheckResult{
+		End:      time.Now(),
+		ExitCode: exitStatusUnhealthy,
+		Output:   out,
+	}
+}
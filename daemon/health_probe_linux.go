@@ -0,0 +1,81 @@
+package daemon
+
+import (
+	"context"
+	"net"
+	"runtime"
+
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/vishvananda/netns"
+)
+
+// containerDialer returns a function opening connections from the network
+// namespace of the container. The socket is created in the namespace of the
+// container's process, after which the connection no longer depends on the
+// thread it was created from.
+func containerDialer(cntr *container.Container) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		pid := cntr.GetPID()
+		if pid == 0 {
+			return nil, errors.Errorf("container %s is not running", cntr.ID)
+		}
+		// Only IP addresses are dialed: names would be resolved by resolver
+		// goroutines outside of the namespace of the container, using the
+		// configuration of the host.
+		host, port, err := net.SplitHostPort(addr)
+		if err != nil {
+			return nil, err
+		}
+		if host == "localhost" {
+			addr = net.JoinHostPort("127.0.0.1", port)
+		} else if net.ParseIP(host) == nil {
+			return nil, errors.Errorf("cannot resolve %s in the network namespace of container %s: only IP addresses and localhost are supported", host, cntr.ID)
+		}
+
+		type result struct {
+			conn net.Conn
+			err  error
+		}
+		ch := make(chan result, 1)
+		go func() {
+			// The thread is not unlocked if switching back to the original
+			// namespace fails, so that it is discarded when the goroutine exits.
+			runtime.LockOSThread()
+
+			origns, err := netns.Get()
+			if err != nil {
+				ch <- result{err: errors.Wrap(err, "failed to get current network namespace")}
+				runtime.UnlockOSThread()
+				return
+			}
+			defer origns.Close()
+
+			ns, err := netns.GetFromPid(pid)
+			if err != nil {
+				ch <- result{err: errors.Wrapf(err, "failed to get network namespace of container %s", cntr.ID)}
+				runtime.UnlockOSThread()
+				return
+			}
+			defer ns.Close()
+
+			if err := netns.Set(ns); err != nil {
+				ch <- result{err: errors.Wrapf(err, "failed to enter network namespace of container %s", cntr.ID)}
+				runtime.UnlockOSThread()
+				return
+			}
+			// fast fallback between IPv4 and IPv6 would dial from other threads
+			d := net.Dialer{FallbackDelay: -1}
+			conn, err := d.DialContext(ctx, network, addr)
+			if err := netns.Set(origns); err != nil {
+				logrus.Errorf("Failed to restore network namespace after health check of container %s: %v", cntr.ID, err)
+			} else {
+				runtime.UnlockOSThread()
+			}
+			ch <- result{conn: conn, err: err}
+		}()
+		r := <-ch
+		return r.conn, r.err
+	}
+}
@@ -0,0 +1,102 @@
+package daemon
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"golang.org/x/net/context"
+)
+
+// newProbeContainer returns a container running in the network namespace
+// of the test, so that its probes can reach the test servers.
+func newProbeContainer(test ...string) *container.Container {
+	c := &container.Container{
+		ID: "container_id",
+		Config: &containertypes.Config{
+			Healthcheck: &containertypes.HealthConfig{Test: test},
+		},
+		State: &container.State{},
+	}
+	c.State.Pid = os.Getpid()
+	return c
+}
+
+func TestHTTPProbe(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("entering a network namespace requires root")
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "status: ok")
+	}))
+	defer srv.Close()
+
+	cases := []struct {
+		test     []string
+		exitCode int
+	}{
+		{test: []string{"HTTP", srv.URL + "/"}, exitCode: exitStatusHealthy},
+		{test: []string{"HTTP", srv.URL + "/", "200", "ok"}, exitCode: exitStatusHealthy},
+		{test: []string{"HTTP", srv.URL + "/", "", "ready"}, exitCode: exitStatusUnhealthy},
+		{test: []string{"HTTP", srv.URL + "/missing"}, exitCode: exitStatusUnhealthy},
+		{test: []string{"HTTP", srv.URL + "/missing", "404"}, exitCode: exitStatusHealthy},
+	}
+	for _, c := range cases {
+		result, err := (&httpProbe{}).run(context.Background(), nil, newProbeContainer(c.test...))
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", c.test, err)
+		}
+		if result.ExitCode != c.exitCode {
+			t.Fatalf("expected exit code %d for %v, got %d (%s)", c.exitCode, c.test, result.ExitCode, result.Output)
+		}
+	}
+}
+
+func TestTCPProbe(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("entering a network namespace requires root")
+	}
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, port, _ := net.SplitHostPort(l.Addr().String())
+
+	result, err := (&tcpProbe{}).run(context.Background(), nil, newProbeContainer("TCP", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != exitStatusHealthy {
+		t.Fatalf("expected the probe to pass, got %d (%s)", result.ExitCode, result.Output)
+	}
+
+	l.Close()
+	result, err = (&tcpProbe{}).run(context.Background(), nil, newProbeContainer("TCP", port))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != exitStatusUnhealthy {
+		t.Fatalf("expected the probe to fail, got %d (%s)", result.ExitCode, result.Output)
+	}
+}
+
+func TestProbeNotRunning(t *testing.T) {
+	c := newProbeContainer("TCP", "80")
+	c.State.Pid = 0
+	result, err := (&tcpProbe{}).run(context.Background(), nil, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ExitCode != exitStatusUnhealthy {
+		t.Fatalf("expected the probe of a stopped container to fail, got %d (%s)", result.ExitCode, result.Output)
+	}
+}
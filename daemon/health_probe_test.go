@@ -0,0 +1,76 @@
+package daemon
+
+import (
+	"testing"
+)
+
+func TestParseHTTPCheck(t *testing.T) {
+	cases := []struct {
+		test     []string
+		expected httpCheck
+	}{
+		{
+			test:     []string{"HTTP", "http://localhost/health"},
+			expected: httpCheck{url: "http://localhost/health", minStatus: 200, maxStatus: 399},
+		},
+		{
+			test:     []string{"HTTP", "https://localhost/", "204"},
+			expected: httpCheck{url: "https://localhost/", minStatus: 204, maxStatus: 204},
+		},
+		{
+			test:     []string{"HTTP", "http://localhost/", "", "ok"},
+			expected: httpCheck{url: "http://localhost/", minStatus: 200, maxStatus: 399, body: "ok"},
+		},
+	}
+	for _, c := range cases {
+		check, err := parseHTTPCheck(c.test)
+		if err != nil {
+			t.Fatalf("unexpected error for %v: %v", c.test, err)
+		}
+		if *check != c.expected {
+			t.Fatalf("expected %+v for %v, got %+v", c.expected, c.test, *check)
+		}
+	}
+
+	for _, test := range [][]string{
+		{"HTTP"},
+		{"HTTP", "localhost:8080"},
+		{"HTTP", "ftp://localhost/"},
+		{"HTTP", "http://db:5432/"},
+		{"HTTP", "http://example.com/health"},
+		{"HTTP", "http://localhost/", "299-200"},
+		{"HTTP", "http://localhost/", "2xx"},
+		{"HTTP", "http://localhost/", "200", "ok", "extra"},
+	} {
+		if _, err := parseHTTPCheck(test); err == nil {
+			t.Fatalf("expected an error for %v", test)
+		}
+	}
+}
+
+func TestValidateHealthcheckTest(t *testing.T) {
+	for _, test := range [][]string{
+		nil,
+		{"NONE"},
+		{"CMD-SHELL", "true"},
+		{"TCP", "6379"},
+		{"HTTP", "http://localhost/"},
+		{"HTTP", "http://127.0.0.1:8080/"},
+		{"HTTP", "http://[::1]:8080/"},
+	} {
+		if err := validateHealthcheckTest(test); err != nil {
+			t.Fatalf("unexpected error for %v: %v", test, err)
+		}
+	}
+	for _, test := range [][]string{
+		{"TCP"},
+		{"TCP", "0"},
+		{"TCP", "http"},
+		{"HTTP", "/health"},
+		{"HTTP", "http://db/"},
+	} {
+		if err := validateHealthcheckTest(test); err == nil {
+			t.Fatalf("expected an error for %v", test)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+// +build !linux
+
+package daemon
+
+import (
+	"context"
+	"net"
+
+	"github.com/docker/docker/container"
+	"github.com/pkg/errors"
+)
+
+// containerDialer returns a function opening connections from the network
+// namespace of the container, which is not supported on this platform.
+func containerDialer(cntr *container.Container) func(ctx context.Context, network, addr string) (net.Conn, error) {
+	return func(ctx context.Context, network, addr string) (net.Conn, error) {
+		return nil, errors.New("HTTP and TCP health checks are not supported on this platform")
+	}
+}
@@ -1,6 +1,8 @@
 package daemon
 
 import (
+	"strings"
+	"syscall"
 	"testing"
 	"time"
 
@@ -9,6 +11,7 @@ import (
 	eventtypes "github.com/docker/docker/api/types/events"
 	"github.com/docker/docker/container"
 	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/libcontainerd"
 )
 
 func reset(c *container.Container) {
@@ -152,3 +155,146 @@ func TestHealthStates(t *testing.T) {
 		t.Errorf("Expecting FailingStreak=0, but got %d\n", c.State.Health.FailingStreak)
 	}
 }
+
+func TestHealthStatusEventAndMetrics(t *testing.T) {
+	e := events.New()
+	_, l, _ := e.Subscribe()
+	defer e.Evict(l)
+
+	c := &container.Container{
+		ID:   "container_health_metrics",
+		Name: "/container_name",
+		Config: &containertypes.Config{
+			Image: "image_name",
+			Healthcheck: &containertypes.HealthConfig{
+				Retries: 2,
+			},
+		},
+	}
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon := &Daemon{
+		EventsService:     e,
+		containersReplica: store,
+	}
+	defer healthCtr.del(c.ID)
+
+	reset(c)
+	start := c.State.StartedAt.Add(time.Second)
+	output := strings.Repeat("x", maxEventOutputLen+10)
+	for i := 0; i < 2; i++ {
+		handleProbeResult(daemon, c, &types.HealthcheckResult{
+			Start:    start,
+			End:      start.Add(250 * time.Millisecond),
+			ExitCode: 7,
+			Output:   output,
+		}, nil)
+	}
+
+	select {
+	case event := <-l:
+		ev := event.(eventtypes.Message)
+		if ev.Status != "health_status: unhealthy" {
+			t.Fatalf("Expecting health_status: unhealthy, but got %#v", ev.Status)
+		}
+		if ev.Actor.Attributes["exitCode"] != "7" {
+			t.Fatalf("Expecting exitCode 7, but got %#v", ev.Actor.Attributes["exitCode"])
+		}
+		if expected := output[:maxEventOutputLen] + "..."; ev.Actor.Attributes["output"] != expected {
+			t.Fatalf("Expecting truncated output, but got %#v", ev.Actor.Attributes["output"])
+		}
+	case <-time.After(1 * time.Second):
+		t.Fatal("Expecting health_status event, but got nothing")
+	}
+
+	healthCtr.mu.Lock()
+	sample := healthCtr.samples[c.ID]
+	healthCtr.mu.Unlock()
+	expected := healthSample{
+		name:          "container_name",
+		status:        types.Unhealthy,
+		failingStreak: 2,
+		duration:      250 * time.Millisecond,
+	}
+	if sample != expected {
+		t.Fatalf("Expecting health sample %+v, but got %+v", expected, sample)
+	}
+
+	// The sample is removed when the health checks stop.
+	daemon.stopHealthchecks(c)
+	healthCtr.mu.Lock()
+	_, ok := healthCtr.samples[c.ID]
+	healthCtr.mu.Unlock()
+	if ok {
+		t.Fatal("Expecting the health sample to be removed when the health checks stop")
+	}
+}
+
+type signalRecorder struct {
+	libcontainerd.Client
+	signals chan int
+}
+
+func (r *signalRecorder) Signal(containerID string, sig int) error {
+	r.signals <- sig
+	return nil
+}
+
+func TestUnhealthyRestart(t *testing.T) {
+	c := &container.Container{
+		ID:   "container_unhealthy_restart",
+		Name: "/container_name",
+		Config: &containertypes.Config{
+			Image: "image_name",
+			Healthcheck: &containertypes.HealthConfig{
+				Retries: 1,
+			},
+		},
+		HostConfig: &containertypes.HostConfig{
+			RestartPolicy: containertypes.RestartPolicy{Name: "on-unhealthy"},
+		},
+	}
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	client := &signalRecorder{signals: make(chan int, 1)}
+	daemon := &Daemon{
+		EventsService:     events.New(),
+		containersReplica: store,
+		containerd:        client,
+	}
+	defer healthCtr.del(c.ID)
+
+	reset(c)
+	start := c.State.StartedAt.Add(time.Second)
+	handleProbeResult(daemon, c, &types.HealthcheckResult{Start: start, End: start, ExitCode: 1}, nil)
+
+	select {
+	case sig := <-client.signals:
+		if sig != int(syscall.SIGKILL) {
+			t.Fatalf("expected the unhealthy container to be killed, got signal %d", sig)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("expected the unhealthy container to be killed")
+	}
+
+	// the restart manager must still restart the container once it exits
+	restart, _, err := c.RestartManager().ShouldRestart(137, c.HasBeenManuallyStopped, time.Second)
+	if err != nil || !restart {
+		t.Fatalf("expected the container to be restarted, got %v, %v", restart, err)
+	}
+	c.RestartManager().Cancel()
+
+	// other policies do not act on unhealthy containers
+	c.HostConfig.RestartPolicy = containertypes.RestartPolicy{Name: "always"}
+	reset(c)
+	handleProbeResult(daemon, c, &types.HealthcheckResult{Start: start, End: start, ExitCode: 1}, nil)
+	select {
+	case sig := <-client.signals:
+		t.Fatalf("expected the container not to be killed, got signal %d", sig)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
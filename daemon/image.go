@@ -3,10 +3,12 @@ package daemon
 import (
 	"fmt"
 	"runtime"
+	"time"
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/pkg/stringid"
+	"github.com/sirupsen/logrus"
 )
 
 // errImageDoesNotExist is error returned when no image can be found for a reference.
@@ -92,3 +94,24 @@ func (daemon *Daemon) GetImage(refOrID string) (*image.Image, error) {
 	}
 	return daemon.stores[platform].imageStore.Get(imgID)
 }
+
+// markImageUsed records that img was used now to create a container or to
+// build an image.
+func (daemon *Daemon) markImageUsed(img *image.Image) {
+	if err := daemon.stores[img.Platform()].imageStore.SetLastUsed(img.ID()); err != nil {
+		logrus.Warnf("Error recording the last use of image %s: %v", img.ID(), err)
+	}
+}
+
+// imageLastUsed returns the last time img was used. Images which were never
+// used were last used when they were last tagged or pulled, or else when they
+// were created.
+func imageLastUsed(imageStore image.Store, img *image.Image) time.Time {
+	if lastUsed, err := imageStore.GetLastUsed(img.ID()); err == nil && !lastUsed.IsZero() {
+		return lastUsed
+	}
+	if lastUpdated, err := imageStore.GetLastUpdated(img.ID()); err == nil && !lastUpdated.IsZero() {
+		return lastUpdated
+	}
+	return img.Created
+}
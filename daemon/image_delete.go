@@ -170,7 +170,7 @@ func (daemon *Daemon) ImageDelete(imageRef string, force, prune bool) ([]types.I
 		}
 	}
 
-	if err := daemon.imageDeleteHelper(imgID, platform, &records, force, prune, removedRepositoryRef); err != nil {
+	if err := daemon.imageDeleteHelper(imgID, platform, &records, force, prune, removedRepositoryRef, nil); err != nil {
 		return nil, err
 	}
 
@@ -245,9 +245,9 @@ func (daemon *Daemon) removeImageRef(platform string, ref reference.Named) (refe
 // removeAllReferencesToImageID attempts to remove every reference to the given
 // imgID from this daemon's store of repository tag/digest references. Returns
 // on the first encountered error. Removed references are logged to this
-// daemon's event service. An "Untagged" types.ImageDeleteResponseItem is added to the
-// given list of records.
-func (daemon *Daemon) removeAllReferencesToImageID(imgID image.ID, platform string, records *[]types.ImageDeleteResponseItem) error {
+// daemon's event service, with the given event attributes. An "Untagged"
+// types.ImageDeleteResponseItem is added to the given list of records.
+func (daemon *Daemon) removeAllReferencesToImageID(imgID image.ID, platform string, records *[]types.ImageDeleteResponseItem, eventAttributes map[string]string) error {
 	imageRefs := daemon.referenceStore.References(imgID.Digest())
 
 	for _, imageRef := range imageRefs {
@@ -258,7 +258,7 @@ func (daemon *Daemon) removeAllReferencesToImageID(imgID image.ID, platform stri
 
 		untaggedRecord := types.ImageDeleteResponseItem{Untagged: reference.FamiliarString(parsedRef)}
 
-		daemon.LogImageEvent(imgID.String(), imgID.String(), "untag")
+		daemon.logImageDeleteEvent(imgID, "untag", eventAttributes)
 		*records = append(*records, untaggedRecord)
 	}
 
@@ -297,8 +297,9 @@ func (idc *imageDeleteConflict) Conflict() {}
 // and untagged references are appended to the given records. If any error or
 // conflict is encountered, it will be returned immediately without deleting
 // the image. If quiet is true, any encountered conflicts will be ignored and
-// the function will return nil immediately without deleting the image.
-func (daemon *Daemon) imageDeleteHelper(imgID image.ID, platform string, records *[]types.ImageDeleteResponseItem, force, prune, quiet bool) error {
+// the function will return nil immediately without deleting the image. The
+// untag and delete events are logged with the given event attributes.
+func (daemon *Daemon) imageDeleteHelper(imgID image.ID, platform string, records *[]types.ImageDeleteResponseItem, force, prune, quiet bool, eventAttributes map[string]string) error {
 	// First, determine if this image has any conflicts. Ignore soft conflicts
 	// if force is true.
 	c := conflictHard
@@ -324,7 +325,7 @@ func (daemon *Daemon) imageDeleteHelper(imgID image.ID, platform string, records
 	}
 
 	// Delete all repository tag/digest references to this image.
-	if err := daemon.removeAllReferencesToImageID(imgID, platform, records); err != nil {
+	if err := daemon.removeAllReferencesToImageID(imgID, platform, records, eventAttributes); err != nil {
 		return err
 	}
 
@@ -333,7 +334,7 @@ func (daemon *Daemon) imageDeleteHelper(imgID image.ID, platform string, records
 		return err
 	}
 
-	daemon.LogImageEvent(imgID.String(), imgID.String(), "delete")
+	daemon.logImageDeleteEvent(imgID, "delete", eventAttributes)
 	*records = append(*records, types.ImageDeleteResponseItem{Deleted: imgID.String()})
 	for _, removedLayer := range removedLayers {
 		*records = append(*records, types.ImageDeleteResponseItem{Deleted: removedLayer.ChainID.String()})
@@ -348,7 +349,17 @@ func (daemon *Daemon) imageDeleteHelper(imgID image.ID, platform string, records
 	// either running or stopped).
 	// Do not force prunings, but do so quietly (stopping on any encountered
 	// conflicts).
-	return daemon.imageDeleteHelper(parent, platform, records, false, true, true)
+	return daemon.imageDeleteHelper(parent, platform, records, false, true, true, eventAttributes)
+}
+
+// logImageDeleteEvent logs an untag or delete event of the image imgID, with
+// the given attributes.
+func (daemon *Daemon) logImageDeleteEvent(imgID image.ID, action string, attributes map[string]string) {
+	eventAttributes := make(map[string]string, len(attributes))
+	for k, v := range attributes {
+		eventAttributes[k] = v
+	}
+	daemon.LogImageEventWithAttributes(imgID.String(), imgID.String(), action, eventAttributes)
 }
 
 // checkImageDeleteConflict determines whether there are any conflicts
@@ -4,34 +4,56 @@ import (
 	"io"
 	"runtime"
 
+	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/image/tarexport"
 	"github.com/docker/docker/pkg/system"
+	"github.com/pkg/errors"
 )
 
 // ExportImage exports a list of images to the given output stream. The
 // exported images are archived into a tar when written to the output
 // stream. All images with the given tag and all versions containing
 // the same tag are exported. names is the set of tags to export, and
-// outStream is the writer which the images are written to.
-func (daemon *Daemon) ExportImage(names []string, outStream io.Writer) error {
+// outStream is the writer which the images are written to. format is
+// either "docker" (the default) or "oci" for the OCI image layout. compression
+// is the compression of the layers of the OCI image layout, either "gzip"
+// (the default) or "zstd"; the layers of the docker format are not
+// compressed.
+func (daemon *Daemon) ExportImage(names []string, format, compression string, outStream io.Writer) error {
+	layerCompression, err := parseLayerCompression(compression, format == "oci")
+	if err != nil {
+		return err
+	}
+
 	// TODO @jhowardmsft LCOW. This will need revisiting later.
 	platform := runtime.GOOS
 	if system.LCOWSupported() {
 		platform = "linux"
 	}
-	imageExporter := tarexport.NewTarExporter(daemon.stores[platform].imageStore, daemon.stores[platform].layerStore, daemon.referenceStore, daemon)
-	return imageExporter.Save(names, outStream)
+	v2MetadataService := metadata.NewV2MetadataService(daemon.stores[platform].distributionMetadataStore)
+	imageExporter := tarexport.NewTarExporter(daemon.stores[platform].imageStore, daemon.stores[platform].layerStore, daemon.referenceStore, v2MetadataService, daemon)
+	switch format {
+	case "", "docker":
+		if compression != "" {
+			return validationError{errors.New("layer compression is only supported with the \"oci\" format")}
+		}
+		return imageExporter.Save(names, outStream)
+	case "oci":
+		return imageExporter.SaveOCILayout(names, layerCompression, outStream)
+	}
+	return validationError{errors.Errorf("invalid image format %q: must be \"docker\" or \"oci\"", format)}
 }
 
 // LoadImage uploads a set of images into the repository. This is the
 // complement of ImageExport.  The input stream is an uncompressed tar
-// ball containing images and metadata.
+// ball containing images and metadata, in the docker or the OCI image
+// layout format.
 func (daemon *Daemon) LoadImage(inTar io.ReadCloser, outStream io.Writer, quiet bool) error {
 	// TODO @jhowardmsft LCOW. This will need revisiting later.
 	platform := runtime.GOOS
 	if system.LCOWSupported() {
 		platform = "linux"
 	}
-	imageExporter := tarexport.NewTarExporter(daemon.stores[platform].imageStore, daemon.stores[platform].layerStore, daemon.referenceStore, daemon)
+	imageExporter := tarexport.NewTarExporter(daemon.stores[platform].imageStore, daemon.stores[platform].layerStore, daemon.referenceStore, nil, daemon)
 	return imageExporter.Load(inTar, outStream, quiet)
 }
@@ -0,0 +1,186 @@
+package daemon
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/image"
+	"github.com/sirupsen/logrus"
+)
+
+// imageGCEventAttributes are the attributes of the untag and delete events of
+// the images deleted by the image garbage collector.
+var imageGCEventAttributes = map[string]string{"reason": "gc"}
+
+// imageGC periodically deletes the least recently used images when the usage
+// of the image filesystem is above the high threshold, until it is below the
+// low threshold.
+type imageGC struct {
+	daemon         *Daemon
+	interval       time.Duration
+	minAge         time.Duration
+	highThreshold  int
+	lowThreshold   int
+	keepReferences []string
+	keepLabels     []string
+	// usage returns the usage percentage of the image filesystem.
+	usage func() (int, error)
+	stop  chan struct{}
+}
+
+// newImageGC creates the image garbage collector configured by c. It returns
+// nil if the image garbage collection is disabled.
+func newImageGC(daemon *Daemon, c config.ImageGCConfig) (*imageGC, error) {
+	if !c.Enabled {
+		return nil, nil
+	}
+	interval, minAge, err := c.Parse()
+	if err != nil {
+		return nil, err
+	}
+	root := daemon.root
+	return &imageGC{
+		daemon:         daemon,
+		interval:       interval,
+		minAge:         minAge,
+		highThreshold:  c.HighThreshold,
+		lowThreshold:   c.LowThreshold,
+		keepReferences: c.KeepReferences,
+		keepLabels:     c.KeepLabels,
+		usage: func() (int, error) {
+			return filesystemUsage(root)
+		},
+		stop: make(chan struct{}),
+	}, nil
+}
+
+// run checks the usage of the image filesystem at each interval until the
+// garbage collector is stopped.
+func (gc *imageGC) run() {
+	ticker := time.NewTicker(gc.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-gc.stop:
+			return
+		case <-ticker.C:
+			gc.collect()
+		}
+	}
+}
+
+// Stop stops the garbage collector.
+func (gc *imageGC) Stop() {
+	close(gc.stop)
+}
+
+// collect deletes images, least recently used first, if the usage of the
+// image filesystem is above the high threshold, until it is below the low
+// threshold. It does nothing if a prune operation is running.
+func (gc *imageGC) collect() {
+	usage, err := gc.usage()
+	if err != nil {
+		logrus.Errorf("Error getting the image filesystem usage: %v", err)
+		return
+	}
+	if usage < gc.highThreshold {
+		return
+	}
+	if !atomic.CompareAndSwapInt32(&gc.daemon.pruneRunning, 0, 1) {
+		logrus.Debug("Skipping image garbage collection: a prune operation is running")
+		return
+	}
+	defer atomic.StoreInt32(&gc.daemon.pruneRunning, 0)
+
+	logrus.Infof("Image filesystem usage is %d%%, above the %d%% high threshold: deleting unused images", usage, gc.highThreshold)
+	var deleted int
+	for platform := range gc.daemon.stores {
+		for _, id := range gc.candidates(platform, time.Now()) {
+			if usage <= gc.lowThreshold {
+				break
+			}
+			if conflict := gc.daemon.checkImageDeleteConflict(id, platform, conflictHard|conflictStoppedContainer); conflict != nil {
+				continue
+			}
+			var records []types.ImageDeleteResponseItem
+			if err := gc.daemon.imageDeleteHelper(id, platform, &records, true, true, false, imageGCEventAttributes); err != nil {
+				logrus.Warnf("Image garbage collection could not delete image %s: %v", id, err)
+				continue
+			}
+			deleted++
+			if usage, err = gc.usage(); err != nil {
+				logrus.Errorf("Error getting the image filesystem usage: %v", err)
+				return
+			}
+		}
+	}
+	logrus.Infof("Image garbage collection deleted %d images, image filesystem usage is %d%%", deleted, usage)
+}
+
+// candidates returns the images of the platform which can be garbage
+// collected at now, least recently used first.
+func (gc *imageGC) candidates(platform string, now time.Time) []image.ID {
+	imageStore := gc.daemon.stores[platform].imageStore
+
+	used := make(map[image.ID]bool)
+	for _, c := range gc.daemon.List() {
+		used[c.ImageID] = true
+	}
+
+	type candidate struct {
+		id       image.ID
+		lastUsed time.Time
+	}
+	var candidates []candidate
+	for id, img := range imageStore.Map() {
+		if used[id] || len(imageStore.Children(id)) > 0 || gc.keep(id, img) {
+			continue
+		}
+		lastUsed := imageLastUsed(imageStore, img)
+		if now.Sub(lastUsed) < gc.minAge {
+			continue
+		}
+		candidates = append(candidates, candidate{id: id, lastUsed: lastUsed})
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].lastUsed.Before(candidates[j].lastUsed)
+	})
+
+	ids := make([]image.ID, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.id)
+	}
+	return ids
+}
+
+// keep returns whether the image img is kept by a reference pattern or a
+// label of the garbage collector.
+func (gc *imageGC) keep(id image.ID, img *image.Image) bool {
+	for _, ref := range gc.daemon.referenceStore.References(id.Digest()) {
+		for _, pattern := range gc.keepReferences {
+			if matched, _ := reference.FamiliarMatch(pattern, ref); matched {
+				return true
+			}
+		}
+	}
+	if img.Config == nil {
+		return false
+	}
+	for _, label := range gc.keepLabels {
+		key, value := label, ""
+		hasValue := strings.Contains(label, "=")
+		if hasValue {
+			parts := strings.SplitN(label, "=", 2)
+			key, value = parts[0], parts[1]
+		}
+		if v, ok := img.Config.Labels[key]; ok && (!hasValue || v == value) {
+			return true
+		}
+	}
+	return false
+}
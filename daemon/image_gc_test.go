@@ -0,0 +1,111 @@
+package daemon
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	refstore "github.com/docker/docker/reference"
+)
+
+type mockLayerGetReleaser struct{}
+
+func (ls *mockLayerGetReleaser) Get(layer.ChainID) (layer.Layer, error) {
+	return nil, nil
+}
+
+func (ls *mockLayerGetReleaser) Release(layer.Layer) ([]layer.Metadata, error) {
+	return nil, nil
+}
+
+func TestImageGCCandidates(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-gc-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	fs, err := image.NewFSStoreBackend(filepath.Join(dir, "images"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	imageStore, err := image.NewImageStore(fs, runtime.GOOS, &mockLayerGetReleaser{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	referenceStore, err := refstore.NewReferenceStore(filepath.Join(dir, "repositories.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	d := &Daemon{
+		containers:     container.NewMemoryStore(),
+		stores:         map[string]daemonStore{runtime.GOOS: {imageStore: imageStore}},
+		referenceStore: referenceStore,
+	}
+
+	now := time.Now()
+	create := func(name string, age time.Duration, labels string) image.ID {
+		config := fmt.Sprintf(`{"comment":%q,"created":%q,"config":{"Labels":{%s}},"rootfs":{"type":"layers"}}`, name, now.Add(-age).Format(time.RFC3339Nano), labels)
+		id, err := imageStore.Create([]byte(config))
+		if err != nil {
+			t.Fatal(err)
+		}
+		ref, err := reference.ParseNormalizedNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := referenceStore.AddTag(reference.TagNameOnly(ref), id.Digest(), false); err != nil {
+			t.Fatal(err)
+		}
+		return id
+	}
+	oldest := create("oldest", 72*time.Hour, "")
+	old := create("old", 48*time.Hour, "")
+	recent := create("recent", 2*time.Hour, "")
+	create("young", 10*time.Minute, "")
+	create("myorg/app", 96*time.Hour, "")
+	create("labeled", 96*time.Hour, `"keep":"true"`)
+	used := create("used", 96*time.Hour, "")
+	parent := create("parent", 96*time.Hour, "")
+	child := create("child", 96*time.Hour, "")
+	if err := imageStore.SetParent(child, parent); err != nil {
+		t.Fatal(err)
+	}
+	// An image used recently is collected after the images which were
+	// created later but not used since.
+	usedRecently := create("usedrecently", 96*time.Hour, "")
+	if err := imageStore.SetLastUsed(usedRecently); err != nil {
+		t.Fatal(err)
+	}
+	d.containers.Add("c1", &container.Container{ID: "c1", ImageID: used})
+
+	gc := &imageGC{
+		daemon:         d,
+		minAge:         time.Hour,
+		keepReferences: []string{"myorg/*"},
+		keepLabels:     []string{"keep=true", "other"},
+	}
+	expected := []image.ID{child, oldest, old, recent}
+	if candidates := gc.candidates(runtime.GOOS, now); !reflect.DeepEqual(candidates, expected) {
+		t.Fatalf("expected candidates %v, got %v", expected, candidates)
+	}
+
+	// A label without a value keeps the images with the label, whatever its
+	// value is.
+	gc.keepLabels = []string{"keep"}
+	gc.keepReferences = nil
+	gc.minAge = 0
+	candidates := gc.candidates(runtime.GOOS, now.Add(2*time.Hour))
+	if len(candidates) != 7 || candidates[len(candidates)-1] != usedRecently {
+		t.Fatalf("expected 7 candidates, the recently used image last, got %v", candidates)
+	}
+}
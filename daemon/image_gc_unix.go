@@ -0,0 +1,22 @@
+// +build linux freebsd
+
+package daemon
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// filesystemUsage returns the usage percentage of the filesystem of path,
+// counting the blocks reserved for the super-user as unavailable.
+func filesystemUsage(path string) (int, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return 0, err
+	}
+	used := uint64(st.Blocks) - uint64(st.Bfree)
+	total := used + uint64(st.Bavail)
+	if total == 0 {
+		return 0, nil
+	}
+	return int(used * 100 / total), nil
+}
@@ -0,0 +1,13 @@
+// +build !linux,!freebsd
+
+package daemon
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// filesystemUsage is not supported on this platform.
+func filesystemUsage(path string) (int, error) {
+	return 0, fmt.Errorf("image garbage collection is not supported on %s", runtime.GOOS)
+}
@@ -65,6 +65,10 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 	if err != nil {
 		return nil, err
 	}
+	lastUsed, err := daemon.stores[platform].imageStore.GetLastUsed(img.ID())
+	if err != nil {
+		return nil, err
+	}
 
 	imageInspect := &types.ImageInspect{
 		ID:              img.ID().String(),
@@ -85,7 +89,8 @@ func (daemon *Daemon) LookupImage(name string) (*types.ImageInspect, error) {
 		VirtualSize:     size, // TODO: field unused, deprecate
 		RootFS:          rootFSToAPIType(img.RootFS),
 		Metadata: types.ImageMetadata{
-			LastTagTime: lastUpdated,
+			LastTagTime:  lastUpdated,
+			LastUsedTime: lastUsed,
 		},
 	}
 
@@ -0,0 +1,139 @@
+package daemon
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"path"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/image"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// imagePolicyRule requires the images of the repositories matching pattern
+// to be signed by one of keys.
+type imagePolicyRule struct {
+	pattern string
+	keys    []crypto.PublicKey
+}
+
+// imagePolicy lists the repositories whose images must be signed.
+type imagePolicy struct {
+	rules []imagePolicyRule
+}
+
+// newImagePolicy creates the image policy defined by ruleConfigs, loading
+// the public keys of the rules. It returns nil if there are no rules.
+func newImagePolicy(ruleConfigs []config.ImagePolicyRule) (*imagePolicy, error) {
+	if len(ruleConfigs) == 0 {
+		return nil, nil
+	}
+	p := &imagePolicy{}
+	for _, c := range ruleConfigs {
+		rule := imagePolicyRule{pattern: c.Repository}
+		for _, keyPath := range c.PublicKeys {
+			key, err := loadPublicKey(keyPath)
+			if err != nil {
+				return nil, errors.Wrapf(err, "error loading the image policy public key for %s", c.Repository)
+			}
+			rule.keys = append(rule.keys, key)
+		}
+		p.rules = append(p.rules, rule)
+	}
+	return p, nil
+}
+
+// loadPublicKey reads the PEM encoded ECDSA or RSA public key at keyPath.
+func loadPublicKey(keyPath string) (crypto.PublicKey, error) {
+	b, err := ioutil.ReadFile(keyPath)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, fmt.Errorf("no PEM encoded public key found in %s", keyPath)
+	}
+	key, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, errors.Wrapf(err, "invalid public key in %s", keyPath)
+	}
+	switch key.(type) {
+	case *ecdsa.PublicKey, *rsa.PublicKey:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T in %s", key, keyPath)
+	}
+}
+
+// PublicKeys returns the public keys of the first rule matching the
+// repository name, or nil if no rule matches it.
+func (p *imagePolicy) PublicKeys(name reference.Named) []crypto.PublicKey {
+	for _, rule := range p.rules {
+		if matched, _ := path.Match(rule.pattern, name.Name()); matched {
+			return rule.keys
+		}
+	}
+	return nil
+}
+
+// pullSignaturePolicy applies the image policy to the images pulled to an
+// image store.
+type pullSignaturePolicy struct {
+	*imagePolicy
+	daemon     *Daemon
+	imageStore image.Store
+}
+
+func (p *pullSignaturePolicy) Verified(name reference.Named, manifestDigest, id digest.Digest) {
+	if err := p.imageStore.AddSignedRepository(image.ID(id), name.Name()); err != nil {
+		logrus.Errorf("Error recording the verified signature of %s: %v", id, err)
+	}
+	p.daemon.LogImageEventWithAttributes(id.String(), reference.FamiliarName(name), "verify", map[string]string{
+		"digest": manifestDigest.String(),
+	})
+}
+
+func (p *pullSignaturePolicy) Rejected(name reference.Named, manifestDigest digest.Digest, err error) {
+	p.daemon.LogImageEventWithAttributes(reference.FamiliarName(name)+"@"+manifestDigest.String(), reference.FamiliarName(name), "verify_failed", map[string]string{
+		"digest": manifestDigest.String(),
+		"error":  err.Error(),
+	})
+}
+
+// checkImagePolicy checks that the signature of img was verified when it was
+// pulled from each of the repositories it is referenced in whose images must
+// be signed.
+func (daemon *Daemon) checkImagePolicy(refOrID string, img *image.Image) error {
+	if daemon.imagePolicy == nil {
+		return nil
+	}
+	signed, err := daemon.stores[img.Platform()].imageStore.GetSignedRepositories(img.ID())
+	if err != nil {
+		return err
+	}
+	for _, ref := range daemon.referenceStore.References(img.ID().Digest()) {
+		if len(daemon.imagePolicy.PublicKeys(ref)) == 0 {
+			continue
+		}
+		var verified bool
+		for _, repository := range signed {
+			verified = verified || repository == ref.Name()
+		}
+		if !verified {
+			err := fmt.Errorf("image %s must be signed: its signature was not verified when it was pulled from %s, pull it again to verify its signature", refOrID, reference.FamiliarName(ref))
+			daemon.LogImageEventWithAttributes(img.ID().String(), reference.FamiliarName(ref), "verify_failed", map[string]string{
+				"error": err.Error(),
+			})
+			return notAllowedError{err}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package daemon
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/daemon/config"
+)
+
+func writePublicKey(t *testing.T, dir, name string) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyPath := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return keyPath
+}
+
+func TestImagePolicy(t *testing.T) {
+	dir, err := ioutil.TempDir("", "image-policy-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	myorg := writePublicKey(t, dir, "myorg.pub")
+	other := writePublicKey(t, dir, "other.pub")
+
+	policy, err := newImagePolicy([]config.ImagePolicyRule{
+		{Repository: "docker.io/myorg/*", PublicKeys: []string{myorg}},
+		{Repository: "docker.io/*/*", PublicKeys: []string{myorg, other}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, expected := range map[string]int{
+		"myorg/app":                      1,
+		"otherorg/app":                   2,
+		"busybox":                        2,
+		"registry.example.com/myorg/app": 0,
+	} {
+		ref, err := reference.ParseNormalizedNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys := policy.PublicKeys(ref); len(keys) != expected {
+			t.Errorf("expected %d keys for %s, got %d", expected, name, len(keys))
+		}
+	}
+
+	if policy, err := newImagePolicy(nil); err != nil || policy != nil {
+		t.Fatalf("expected no policy without rules, got %v, %v", policy, err)
+	}
+
+	invalid := filepath.Join(dir, "invalid.pub")
+	if err := ioutil.WriteFile(invalid, []byte("not a key"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	for _, keyPath := range []string{invalid, filepath.Join(dir, "missing.pub")} {
+		if _, err := newImagePolicy([]config.ImagePolicyRule{{Repository: "docker.io/*", PublicKeys: []string{keyPath}}}); err == nil {
+			t.Fatalf("expected an error loading %s", keyPath)
+		}
+	}
+}
@@ -47,6 +47,11 @@ func (daemon *Daemon) PullImage(ctx context.Context, image, tag, platform string
 }
 
 func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.Named, platform string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+	chunkSize, err := daemon.configStore.ParseDownloadChunkSize()
+	if err != nil {
+		return err
+	}
+
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -76,12 +81,22 @@ func (daemon *Daemon) pullImageWithReference(ctx context.Context, ref reference.
 			ImageStore:       distribution.NewImageConfigStoreFromStore(daemon.stores[platform].imageStore),
 			ReferenceStore:   daemon.referenceStore,
 		},
-		DownloadManager: daemon.downloadManager,
-		Schema2Types:    distribution.ImageTypes,
-		Platform:        platform,
+		DownloadManager:             daemon.downloadManager,
+		Schema2Types:                distribution.ImageTypes,
+		Platform:                    platform,
+		DownloadChunkSize:           chunkSize,
+		MaxConcurrentDownloadChunks: daemon.configStore.MaxConcurrentDownloadChunks,
+		LazyPull:                    daemon.configStore.Experimental && daemon.configStore.LazyPull,
+	}
+	if daemon.imagePolicy != nil {
+		imagePullConfig.SignaturePolicy = &pullSignaturePolicy{
+			imagePolicy: daemon.imagePolicy,
+			daemon:      daemon,
+			imageStore:  daemon.stores[platform].imageStore,
+		}
 	}
 
-	err := distribution.Pull(ctx, ref, imagePullConfig)
+	err = distribution.Pull(ctx, ref, imagePullConfig)
 	close(progressChan)
 	<-writesDone
 	return err
@@ -100,7 +115,7 @@ func (daemon *Daemon) GetRepository(ctx context.Context, ref reference.Named, au
 	}
 
 	// get endpoints
-	endpoints, err := daemon.RegistryService.LookupPullEndpoints(reference.Domain(repoInfo.Name))
+	endpoints, err := daemon.RegistryService.LookupPullEndpoints(repoInfo.Name)
 	if err != nil {
 		return nil, false, err
 	}
@@ -2,20 +2,39 @@ package daemon
 
 import (
 	"io"
+	"os/exec"
 	"runtime"
 
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
 	"github.com/docker/docker/distribution"
 	progressutils "github.com/docker/docker/distribution/utils"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/go-digest"
+	"github.com/pkg/errors"
 	"golang.org/x/net/context"
 )
 
 // PushImage initiates a push operation on the repository named localName.
-func (daemon *Daemon) PushImage(ctx context.Context, image, tag string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+// format is either "docker" (the default) to push schema2 manifests, or
+// "oci" to push OCI image manifests. compression is the compression of the
+// uploaded layers, either "gzip" (the default) or "zstd", which is only
+// supported with the "oci" format.
+func (daemon *Daemon) PushImage(ctx context.Context, image, tag, format, compression string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+	ociManifest, err := isOCIManifestFormat(format)
+	if err != nil {
+		return err
+	}
+	layerCompression, err := parseLayerCompression(compression, ociManifest)
+	if err != nil {
+		return err
+	}
+
 	ref, err := reference.ParseNormalizedNamed(image)
 	if err != nil {
 		return err
@@ -28,6 +47,108 @@ func (daemon *Daemon) PushImage(ctx context.Context, image, tag string, metaHead
 		}
 	}
 
+	return daemon.push(ctx, metaHeaders, authConfig, ociManifest, layerCompression, outStream, func(ctx context.Context, imagePushConfig *distribution.ImagePushConfig) error {
+		return distribution.Push(ctx, ref, imagePushConfig)
+	})
+}
+
+// PushManifestList pushes the given local images to the repository of
+// image, and tags a manifest list which references them with the tag of
+// image. The platform of each image in the list is the one of its
+// configuration, overridden by the fields of the platform of its entry
+// which are set. With the "oci" format, OCI image manifests and an OCI image
+// index are pushed. compression is the compression of the uploaded layers,
+// as for PushImage.
+func (daemon *Daemon) PushManifestList(ctx context.Context, image string, manifests []registrytypes.ManifestListEntry, format, compression string, metaHeaders map[string][]string, authConfig *types.AuthConfig, outStream io.Writer) error {
+	ociManifest, err := isOCIManifestFormat(format)
+	if err != nil {
+		return err
+	}
+	layerCompression, err := parseLayerCompression(compression, ociManifest)
+	if err != nil {
+		return err
+	}
+
+	ref, err := reference.ParseNormalizedNamed(image)
+	if err != nil {
+		return err
+	}
+	if _, isCanonical := ref.(reference.Canonical); isCanonical {
+		return validationError{errors.New("cannot push a manifest list to a digest reference")}
+	}
+	taggedRef, ok := reference.TagNameOnly(ref).(reference.NamedTagged)
+	if !ok {
+		return validationError{errors.Errorf("invalid manifest list reference %s", image)}
+	}
+
+	// TODO @jhowardmsft LCOW Support. This will require revisiting. For now, hard-code.
+	platform := runtime.GOOS
+	if system.LCOWSupported() {
+		platform = "linux"
+	}
+	imageStore := distribution.NewImageConfigStoreFromStore(daemon.stores[platform].imageStore)
+
+	var entries []distribution.ManifestListEntry
+	for _, m := range manifests {
+		img, err := daemon.GetImage(m.Image)
+		if err != nil {
+			return err
+		}
+		entry, err := distribution.NewManifestListEntry(imageStore, digest.Digest(img.ID()))
+		if err != nil {
+			return err
+		}
+		entry.Annotate(manifestlist.PlatformSpec{
+			Architecture: m.Platform.Architecture,
+			OS:           m.Platform.OS,
+			OSVersion:    m.Platform.OSVersion,
+			OSFeatures:   m.Platform.OSFeatures,
+			Variant:      m.Platform.Variant,
+		})
+		entries = append(entries, entry)
+	}
+
+	return daemon.push(ctx, metaHeaders, authConfig, ociManifest, layerCompression, outStream, func(ctx context.Context, imagePushConfig *distribution.ImagePushConfig) error {
+		return distribution.PushManifestList(ctx, taggedRef, entries, imagePushConfig)
+	})
+}
+
+// isOCIManifestFormat returns whether the manifest format format, either
+// "docker" (the default) or "oci", is the OCI format.
+func isOCIManifestFormat(format string) (bool, error) {
+	switch format {
+	case "", "docker":
+		return false, nil
+	case "oci":
+		return true, nil
+	}
+	return false, validationError{errors.Errorf("invalid manifest format %q: must be \"docker\" or \"oci\"", format)}
+}
+
+// parseLayerCompression returns the compression of layers named compression,
+// either "gzip" (the default) or "zstd". Schema2 manifests have no standard
+// media type for zstd compressed layers, which older clients couldn't pull,
+// so zstd is only supported with OCI image manifests. Layers are compressed
+// with zstd by the zstd command, which must be installed.
+func parseLayerCompression(compression string, ociManifest bool) (archive.Compression, error) {
+	switch compression {
+	case "", "gzip":
+		return archive.Gzip, nil
+	case "zstd":
+		if !ociManifest {
+			return archive.Uncompressed, validationError{errors.New("zstd layer compression is only supported with the \"oci\" format")}
+		}
+		if _, err := exec.LookPath("zstd"); err != nil {
+			return archive.Uncompressed, validationError{errors.Wrap(err, "zstd layer compression is not supported: the zstd command is not installed on the daemon host")}
+		}
+		return archive.Zstd, nil
+	}
+	return archive.Uncompressed, validationError{errors.Errorf("invalid layer compression %q: must be \"gzip\" or \"zstd\"", compression)}
+}
+
+// push runs pushFunc with the push configuration of the daemon, writing its
+// progress to outStream.
+func (daemon *Daemon) push(ctx context.Context, metaHeaders map[string][]string, authConfig *types.AuthConfig, ociManifest bool, compression archive.Compression, outStream io.Writer, pushFunc func(context.Context, *distribution.ImagePushConfig) error) error {
 	// Include a buffer so that slow client connections don't affect
 	// transfer performance.
 	progressChan := make(chan progress.Progress, 100)
@@ -59,12 +180,14 @@ func (daemon *Daemon) PushImage(ctx context.Context, image, tag string, metaHead
 			ReferenceStore:   daemon.referenceStore,
 		},
 		ConfigMediaType: schema2.MediaTypeImageConfig,
+		OCIManifest:     ociManifest,
+		ZstdCompression: compression == archive.Zstd,
 		LayerStore:      distribution.NewLayerProviderFromStore(daemon.stores[platform].layerStore),
 		TrustKey:        daemon.trustKey,
 		UploadManager:   daemon.uploadManager,
 	}
 
-	err = distribution.Push(ctx, ref, imagePushConfig)
+	err := pushFunc(ctx, imagePushConfig)
 	close(progressChan)
 	<-writesDone
 	return err
@@ -0,0 +1,35 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/docker/docker/pkg/archive"
+)
+
+func TestParseLayerCompression(t *testing.T) {
+	for _, compression := range []string{"", "gzip"} {
+		if c, err := parseLayerCompression(compression, false); err != nil || c != archive.Gzip {
+			t.Fatalf("%q: expected gzip, got %v: %v", compression, c, err)
+		}
+	}
+	if _, err := parseLayerCompression("bzip2", true); err == nil || !strings.Contains(err.Error(), "invalid layer compression") {
+		t.Fatalf("expected an invalid compression error, got %v", err)
+	}
+	if _, err := parseLayerCompression("zstd", false); err == nil || !strings.Contains(err.Error(), "only supported with the \"oci\" format") {
+		t.Fatalf("expected zstd to be rejected with schema2 manifests, got %v", err)
+	}
+
+	dir, err := ioutil.TempDir("", "docker-layer-compression-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	defer os.Setenv("PATH", os.Getenv("PATH"))
+	os.Setenv("PATH", dir)
+	if _, err := parseLayerCompression("zstd", true); err == nil || !strings.Contains(err.Error(), "the zstd command is not installed") {
+		t.Fatalf("expected a missing zstd error, got %v", err)
+	}
+}
@@ -148,6 +148,9 @@ func (daemon *Daemon) Images(imageFilters filters.Args, all bool, withExtraAttrs
 		}
 
 		newImage := newImage(img, size)
+		if lastUsed, err := daemon.stores[platform].imageStore.GetLastUsed(id); err == nil && !lastUsed.IsZero() {
+			newImage.LastUsed = lastUsed.Unix()
+		}
 
 		for _, ref := range daemon.referenceStore.References(id.Digest()) {
 			if imageFilters.Include("reference") {
@@ -10,6 +10,7 @@ import (
 	"github.com/docker/docker/api/types/versions"
 	"github.com/docker/docker/api/types/versions/v1p20"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/exec"
 	"github.com/docker/docker/daemon/network"
 	volumestore "github.com/docker/docker/volume/store"
 	"github.com/docker/go-connections/nat"
@@ -145,6 +146,13 @@ func (daemon *Daemon) getInspectData(container *container.Container) (*types.Con
 		}
 	}
 
+	var terminationReason *types.TerminationReason
+	if container.State.TerminationReason != nil {
+		reason := *container.State.TerminationReason
+		reason.Stderr = append([]string(nil), reason.Stderr...)
+		terminationReason = &reason
+	}
+
 	containerState := &types.ContainerState{
 		Status:     container.State.StateString(),
 		Running:    container.State.Running,
@@ -158,6 +166,8 @@ func (daemon *Daemon) getInspectData(container *container.Container) (*types.Con
 		StartedAt:  container.State.StartedAt.Format(time.RFC3339Nano),
 		FinishedAt: container.State.FinishedAt.Format(time.RFC3339Nano),
 		Health:     containerHealth,
+
+		TerminationReason: terminationReason,
 	}
 
 	contJSONBase := &types.ContainerJSONBase{
@@ -207,6 +217,10 @@ func (daemon *Daemon) ContainerExecInspect(id string) (*backend.ExecInspect, err
 		return nil, errExecNotFound(id)
 	}
 
+	return execInspect(e), nil
+}
+
+func execInspect(e *exec.Config) *backend.ExecInspect {
 	pc := inspectExecProcessConfig(e)
 
 	return &backend.ExecInspect{
@@ -221,7 +235,7 @@ func (daemon *Daemon) ContainerExecInspect(id string) (*backend.ExecInspect, err
 		ContainerID:   e.ContainerID,
 		DetachKeys:    e.DetachKeys,
 		Pid:           e.Pid,
-	}, nil
+	}
 }
 
 // VolumeInspect looks up a volume by name. An error is returned if
@@ -8,6 +8,7 @@ import (
 	"syscall"
 	"time"
 
+	"github.com/docker/docker/api/types"
 	containerpkg "github.com/docker/docker/container"
 	"github.com/docker/docker/pkg/signal"
 	"github.com/pkg/errors"
@@ -48,17 +49,20 @@ func (daemon *Daemon) ContainerKill(name string, sig uint64) error {
 
 	// If no signal is passed, or SIGKILL, perform regular Kill (SIGKILL + wait())
 	if sig == 0 || syscall.Signal(sig) == syscall.SIGKILL {
-		return daemon.Kill(container)
+		return daemon.Kill(container, types.TerminationKill)
 	}
-	return daemon.killWithSignal(container, int(sig))
+	return daemon.killWithSignal(container, int(sig), types.TerminationKill, false)
 }
 
 // killWithSignal sends the container the given signal. This wrapper for the
 // host specific kill command prepares the container before attempting
 // to send the signal. An error is returned if the container is paused
 // or not running, or if there is a problem returned from the
-// underlying kill command.
-func (daemon *Daemon) killWithSignal(container *containerpkg.Container, sig int) error {
+// underlying kill command. If the signal is expected to terminate the
+// container, initiator is recorded as the cause of its termination. stopping
+// is set when the signal is sent by containerStop, any signal then being
+// expected to terminate the container.
+func (daemon *Daemon) killWithSignal(container *containerpkg.Container, sig int, initiator types.TerminationInitiator, stopping bool) error {
 	logrus.Debugf("Sending kill signal %d to container %s", sig, container.ID)
 	container.Lock()
 	defer container.Unlock()
@@ -73,12 +77,14 @@ func (daemon *Daemon) killWithSignal(container *containerpkg.Container, sig int)
 		if err != nil {
 			return err
 		}
-		if containerStopSignal == syscall.Signal(sig) {
+		if stopping || containerStopSignal == syscall.Signal(sig) {
 			container.ExitOnNext()
+			container.SetTerminationRequest(initiator, sig)
 			unpause = container.Paused
 		}
 	} else {
 		container.ExitOnNext()
+		container.SetTerminationRequest(initiator, sig)
 		unpause = container.Paused
 	}
 
@@ -120,14 +126,14 @@ func (daemon *Daemon) killWithSignal(container *containerpkg.Container, sig int)
 	return nil
 }
 
-// Kill forcefully terminates a container.
-func (daemon *Daemon) Kill(container *containerpkg.Container) error {
+// Kill forcefully terminates a container on behalf of initiator.
+func (daemon *Daemon) Kill(container *containerpkg.Container, initiator types.TerminationInitiator) error {
 	if !container.IsRunning() {
 		return errNotRunning(container.ID)
 	}
 
 	// 1. Send SIGKILL
-	if err := daemon.killPossiblyDeadProcess(container, int(syscall.SIGKILL)); err != nil {
+	if err := daemon.killPossiblyDeadProcess(container, int(syscall.SIGKILL), initiator, false); err != nil {
 		// While normally we might "return err" here we're not going to
 		// because if we can't stop the container by this point then
 		// it's probably because it's already stopped. Meaning, between
@@ -166,8 +172,8 @@ func (daemon *Daemon) Kill(container *containerpkg.Container) error {
 }
 
 // killPossibleDeadProcess is a wrapper around killSig() suppressing "no such process" error.
-func (daemon *Daemon) killPossiblyDeadProcess(container *containerpkg.Container, sig int) error {
-	err := daemon.killWithSignal(container, sig)
+func (daemon *Daemon) killPossiblyDeadProcess(container *containerpkg.Container, sig int, initiator types.TerminationInitiator, stopping bool) error {
+	err := daemon.killWithSignal(container, sig, initiator, stopping)
 	if err == syscall.ESRCH {
 		e := errNoSuchProcess{container.GetPID(), sig}
 		logrus.Debug(e)
@@ -45,24 +45,9 @@ func init() {
 // New creates new JSONFileLogger which writes to filename passed in
 // on given context.
 func New(info logger.Info) (logger.Logger, error) {
-	var capval int64 = -1
-	if capacity, ok := info.Config["max-size"]; ok {
-		var err error
-		capval, err = units.FromHumanSize(capacity)
-		if err != nil {
-			return nil, err
-		}
-	}
-	var maxFiles = 1
-	if maxFileString, ok := info.Config["max-file"]; ok {
-		var err error
-		maxFiles, err = strconv.Atoi(maxFileString)
-		if err != nil {
-			return nil, err
-		}
-		if maxFiles < 1 {
-			return nil, fmt.Errorf("max-file cannot be less than 1")
-		}
+	capval, maxFiles, err := parseRotateOptions(info.Config)
+	if err != nil {
+		return nil, err
 	}
 
 	writer, err := loggerutils.NewRotateFileWriter(info.LogPath, capval, maxFiles)
@@ -91,6 +76,45 @@ func New(info logger.Info) (logger.Logger, error) {
 	}, nil
 }
 
+// parseRotateOptions returns the maximum size of a log file and the maximum
+// number of log files set by the max-size and max-file options.
+func parseRotateOptions(cfg map[string]string) (int64, int, error) {
+	var capval int64 = -1
+	if capacity, ok := cfg["max-size"]; ok {
+		var err error
+		capval, err = units.FromHumanSize(capacity)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+	var maxFiles = 1
+	if maxFileString, ok := cfg["max-file"]; ok {
+		var err error
+		maxFiles, err = strconv.Atoi(maxFileString)
+		if err != nil {
+			return 0, 0, err
+		}
+		if maxFiles < 1 {
+			return 0, 0, fmt.Errorf("max-file cannot be less than 1")
+		}
+	}
+	return capval, maxFiles, nil
+}
+
+// UpdateConfig applies the max-size and max-file options in cfg to the
+// log file of a running logger. Other options are ignored.
+func (l *JSONFileLogger) UpdateConfig(cfg map[string]string) error {
+	capval, maxFiles, err := parseRotateOptions(cfg)
+	if err != nil {
+		return err
+	}
+	// Readers hold l.mu while they open the rotated files, which are
+	// removed if the maximum number of files is lowered.
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.writer.SetLimits(capval, maxFiles)
+}
+
 // Log converts logger.Message to jsonlog.JSONLog and serializes it to file.
 func (l *JSONFileLogger) Log(msg *logger.Message) error {
 	l.mu.Lock()
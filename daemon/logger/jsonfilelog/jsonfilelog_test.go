@@ -205,3 +205,50 @@ func TestJSONFileLoggerWithLabelsEnv(t *testing.T) {
 		t.Fatalf("Wrong log attrs: %q, expected %q", extra, expected)
 	}
 }
+
+func TestJSONFileLoggerUpdateConfig(t *testing.T) {
+	cid := "a7317399f3f857173c6179d44823594f8294678dea9999662e5c625b5a1c7657"
+	tmp, err := ioutil.TempDir("", "docker-logger-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	filename := filepath.Join(tmp, "container.log")
+	l, err := New(logger.Info{
+		ContainerID: cid,
+		LogPath:     filename,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+
+	if err := l.(logger.ConfigUpdater).UpdateConfig(map[string]string{"max-file": "0"}); err == nil {
+		t.Fatal("expected an error for an invalid max-file")
+	}
+	if err := l.(logger.ConfigUpdater).UpdateConfig(map[string]string{"max-file": "2", "max-size": "1k"}); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 20; i++ {
+		if err := l.Log(&logger.Message{Line: []byte("line" + strconv.Itoa(i)), Source: "src1"}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := os.Stat(filename + ".1"); err != nil {
+		t.Fatalf("expected the log file to be rotated: %v", err)
+	}
+	if _, err := os.Stat(filename + ".2"); !os.IsNotExist(err) {
+		t.Fatalf("expected at most 2 log files, got %v", err)
+	}
+
+	// Lowering max-file removes the rotated files above the new limit
+	if err := l.(logger.ConfigUpdater).UpdateConfig(map[string]string{"max-file": "1", "max-size": "1k"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filename + ".1"); !os.IsNotExist(err) {
+		t.Fatalf("expected the rotated log file to be removed, got %v", err)
+	}
+	if _, err := os.Stat(filename); err != nil {
+		t.Fatalf("expected the log file to be kept: %v", err)
+	}
+}
@@ -24,6 +24,16 @@ func (ErrReadLogsNotSupported) Error() string {
 // NotImplemented makes this error implement the `NotImplemented` interface from api/errdefs
 func (ErrReadLogsNotSupported) NotImplemented() {}
 
+// ErrUpdateConfigNotSupported is returned when the underlying log driver does not support updating its options
+type ErrUpdateConfigNotSupported struct{}
+
+func (ErrUpdateConfigNotSupported) Error() string {
+	return "configured logging driver does not support updating its options"
+}
+
+// NotImplemented makes this error implement the `NotImplemented` interface from api/errdefs
+func (ErrUpdateConfigNotSupported) NotImplemented() {}
+
 const (
 	logWatcherBufferSize = 4096
 )
@@ -78,6 +88,14 @@ type Logger interface {
 	Close() error
 }
 
+// ConfigUpdater is the interface for loggers whose options can be changed
+// while they are in use.
+type ConfigUpdater interface {
+	// UpdateConfig applies the log options in cfg, which holds all the
+	// options of the logger, not only the changed ones.
+	UpdateConfig(cfg map[string]string) error
+}
+
 // ReadConfig is the configuration passed into ReadLogs.
 type ReadConfig struct {
 	Since  time.Time
@@ -61,6 +61,25 @@ func (w *RotateFileWriter) Write(message []byte) (int, error) {
 	return n, err
 }
 
+// SetLimits changes the maximum size of each file and the maximum number of
+// files. The new limits apply from the next write. Lowering the maximum
+// number of files removes the rotated files above it.
+func (w *RotateFileWriter) SetLimits(capacity int64, maxFiles int) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.capacity = capacity
+	oldMaxFiles := w.maxFiles
+	w.maxFiles = maxFiles
+
+	name := w.f.Name()
+	for i := maxFiles; i < oldMaxFiles; i++ {
+		if err := os.Remove(name + "." + strconv.Itoa(i)); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
 func (w *RotateFileWriter) checkCapacityAndRotate() error {
 	if w.capacity == -1 {
 		return nil
@@ -113,6 +132,8 @@ func (w *RotateFileWriter) LogPath() string {
 
 // MaxFiles return maximum number of files
 func (w *RotateFileWriter) MaxFiles() int {
+	w.mu.Lock()
+	defer w.mu.Unlock()
 	return w.maxFiles
 }
 
@@ -70,6 +70,15 @@ func (r *RingLogger) Name() string {
 	return r.l.Name()
 }
 
+// UpdateConfig applies cfg to the underlying logger
+func (r *RingLogger) UpdateConfig(cfg map[string]string) error {
+	u, ok := r.l.(ConfigUpdater)
+	if !ok {
+		return ErrUpdateConfigNotSupported{}
+	}
+	return u.UpdateConfig(cfg)
+}
+
 func (r *RingLogger) closed() bool {
 	return atomic.LoadInt32(&r.closeFlag) == 1
 }
@@ -3,7 +3,9 @@ package daemon
 import (
 	"path/filepath"
 	"sync"
+	"time"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/pkg/mount"
 	"github.com/docker/docker/pkg/plugingetter"
 	metrics "github.com/docker/go-metrics"
@@ -24,7 +26,8 @@ var (
 	healthChecksCounter       metrics.Counter
 	healthChecksFailedCounter metrics.Counter
 
-	stateCtr *stateCounter
+	stateCtr  *stateCounter
+	healthCtr *healthCounter
 )
 
 func init() {
@@ -59,6 +62,13 @@ func init() {
 	stateCtr = newStateCounter(ns.NewDesc("container_states", "The count of containers in various states", metrics.Unit("containers"), "state"))
 	ns.Add(stateCtr)
 
+	healthCtr = newHealthCounter(
+		ns.NewDesc("container_health_status", "The current health status of each container with a health check, 1 for the current status", metrics.Unit("info"), "id", "name", "status"),
+		ns.NewDesc("container_health_failing_streak", "The number of consecutive failed health checks of each container", metrics.Unit("checks"), "id", "name"),
+		ns.NewDesc("container_health_probe_duration", "The duration of the last health check probe of each container", metrics.Seconds, "id", "name"),
+	)
+	ns.Add(healthCtr)
+
 	metrics.Register(ns)
 }
 
@@ -113,6 +123,70 @@ func (ctr *stateCounter) Collect(ch chan<- prometheus.Metric) {
 	ch <- prometheus.MustNewConstMetric(ctr.desc, prometheus.GaugeValue, float64(stopped), "stopped")
 }
 
+// healthSample holds the health state of a container at its last probe.
+type healthSample struct {
+	name          string
+	status        string
+	failingStreak int
+	duration      time.Duration
+}
+
+// healthCounter exports the health state of each container which has
+// a health check.
+type healthCounter struct {
+	mu             sync.Mutex
+	samples        map[string]healthSample
+	statusDesc     *prometheus.Desc
+	streakDesc     *prometheus.Desc
+	durationDesc   *prometheus.Desc
+	healthStatuses []string
+}
+
+func newHealthCounter(statusDesc, streakDesc, durationDesc *prometheus.Desc) *healthCounter {
+	return &healthCounter{
+		samples:        make(map[string]healthSample),
+		statusDesc:     statusDesc,
+		streakDesc:     streakDesc,
+		durationDesc:   durationDesc,
+		healthStatuses: []string{types.Starting, types.Healthy, types.Unhealthy},
+	}
+}
+
+func (ctr *healthCounter) set(id string, sample healthSample) {
+	ctr.mu.Lock()
+	ctr.samples[id] = sample
+	ctr.mu.Unlock()
+}
+
+func (ctr *healthCounter) del(id string) {
+	ctr.mu.Lock()
+	delete(ctr.samples, id)
+	ctr.mu.Unlock()
+}
+
+func (ctr *healthCounter) Describe(ch chan<- *prometheus.Desc) {
+	ch <- ctr.statusDesc
+	ch <- ctr.streakDesc
+	ch <- ctr.durationDesc
+}
+
+func (ctr *healthCounter) Collect(ch chan<- prometheus.Metric) {
+	ctr.mu.Lock()
+	defer ctr.mu.Unlock()
+
+	for id, s := range ctr.samples {
+		for _, status := range ctr.healthStatuses {
+			var v float64
+			if s.status == status {
+				v = 1
+			}
+			ch <- prometheus.MustNewConstMetric(ctr.statusDesc, prometheus.GaugeValue, v, id, s.name, status)
+		}
+		ch <- prometheus.MustNewConstMetric(ctr.streakDesc, prometheus.GaugeValue, float64(s.failingStreak), id, s.name)
+		ch <- prometheus.MustNewConstMetric(ctr.durationDesc, prometheus.GaugeValue, s.duration.Seconds(), id, s.name)
+	}
+}
+
 func (d *Daemon) cleanupMetricsPlugins() {
 	ls := d.PluginStore.GetAllManagedPluginsByCap(metricsPluginType)
 	var wg sync.WaitGroup
@@ -0,0 +1,168 @@
+package daemon
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/container"
+	"github.com/golang/protobuf/proto"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	"github.com/sirupsen/logrus"
+)
+
+const containerMetricsPrefix = "engine_container_"
+
+// containerMetricsHandler serves the resource usage of the running
+// containers in the prometheus exposition format. Samples are taken from
+// the stats collector, so they hold the same data as the stats API.
+type containerMetricsHandler struct {
+	daemon *Daemon
+	// labels holds the keys of the container labels which are exported
+	// as metric labels, on top of the container name and image.
+	labels []string
+}
+
+// ContainerMetricsHandler returns an http.Handler exporting per-container
+// CPU, memory, block IO, network and pids metrics. Each sample is labeled
+// by container name and image, and by the container labels listed in labels.
+func (daemon *Daemon) ContainerMetricsHandler(labels []string) http.Handler {
+	return &containerMetricsHandler{daemon: daemon, labels: labels}
+}
+
+func (h *containerMetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	families := newMetricFamilies()
+	for _, c := range h.daemon.containers.List() {
+		if !c.IsRunning() {
+			continue
+		}
+		stats, err := h.daemon.statsCollector.Snapshot(c)
+		if err != nil {
+			logrus.Debugf("collecting metrics for container %s: %v", c.ID, err)
+			continue
+		}
+		families.addContainerStats(stats, h.containerLabels(c))
+	}
+
+	format := expfmt.Negotiate(r.Header)
+	w.Header().Set("Content-Type", string(format))
+	enc := expfmt.NewEncoder(w, format)
+	for _, name := range families.order {
+		if err := enc.Encode(families.families[name]); err != nil {
+			logrus.Errorf("encoding container metrics: %v", err)
+			return
+		}
+	}
+}
+
+func (h *containerMetricsHandler) containerLabels(c *container.Container) []*dto.LabelPair {
+	labels := []*dto.LabelPair{
+		labelPair("name", strings.TrimPrefix(c.Name, "/")),
+		labelPair("image", c.Config.Image),
+	}
+	for _, key := range h.labels {
+		labels = append(labels, labelPair("container_label_"+sanitizeMetricLabel(key), c.Config.Labels[key]))
+	}
+	return labels
+}
+
+// sanitizeMetricLabel replaces the characters of a container label key
+// which are not allowed in a prometheus label name.
+func sanitizeMetricLabel(key string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '_' {
+			return r
+		}
+		return '_'
+	}, key)
+}
+
+func labelPair(name, value string) *dto.LabelPair {
+	return &dto.LabelPair{Name: proto.String(name), Value: proto.String(value)}
+}
+
+// metricFamilies accumulates samples per metric, keeping the metrics in
+// the order they were first added.
+type metricFamilies struct {
+	order    []string
+	families map[string]*dto.MetricFamily
+}
+
+func newMetricFamilies() *metricFamilies {
+	return &metricFamilies{families: make(map[string]*dto.MetricFamily)}
+}
+
+func (f *metricFamilies) add(name, help string, typ dto.MetricType, value float64, labels []*dto.LabelPair, extra ...*dto.LabelPair) {
+	name = containerMetricsPrefix + name
+	mf, ok := f.families[name]
+	if !ok {
+		mf = &dto.MetricFamily{
+			Name: proto.String(name),
+			Help: proto.String(help),
+			Type: typ.Enum(),
+		}
+		f.families[name] = mf
+		f.order = append(f.order, name)
+	}
+
+	m := &dto.Metric{Label: append(append([]*dto.LabelPair{}, labels...), extra...)}
+	if typ == dto.MetricType_COUNTER {
+		m.Counter = &dto.Counter{Value: proto.Float64(value)}
+	} else {
+		m.Gauge = &dto.Gauge{Value: proto.Float64(value)}
+	}
+	mf.Metric = append(mf.Metric, m)
+}
+
+func (f *metricFamilies) counter(name, help string, value uint64, labels []*dto.LabelPair, extra ...*dto.LabelPair) {
+	f.add(name, help, dto.MetricType_COUNTER, float64(value), labels, extra...)
+}
+
+func (f *metricFamilies) gauge(name, help string, value uint64, labels []*dto.LabelPair, extra ...*dto.LabelPair) {
+	f.add(name, help, dto.MetricType_GAUGE, float64(value), labels, extra...)
+}
+
+func (f *metricFamilies) addContainerStats(s *types.StatsJSON, labels []*dto.LabelPair) {
+	cpu := s.CPUStats
+	f.add("cpu_usage_seconds_total", "The total CPU time consumed by the container", dto.MetricType_COUNTER, float64(cpu.CPUUsage.TotalUsage)/1e9, labels)
+	f.add("cpu_user_seconds_total", "The CPU time consumed by the container in user mode", dto.MetricType_COUNTER, float64(cpu.CPUUsage.UsageInUsermode)/1e9, labels)
+	f.add("cpu_system_seconds_total", "The CPU time consumed by the container in kernel mode", dto.MetricType_COUNTER, float64(cpu.CPUUsage.UsageInKernelmode)/1e9, labels)
+	f.counter("cpu_throttled_periods_total", "The number of periods the container was throttled", cpu.ThrottlingData.ThrottledPeriods, labels)
+
+	mem := s.MemoryStats
+	f.gauge("memory_usage_bytes", "The memory used by the container", mem.Usage, labels)
+	f.gauge("memory_max_usage_bytes", "The maximum memory used by the container", mem.MaxUsage, labels)
+	f.gauge("memory_limit_bytes", "The memory limit of the container", mem.Limit, labels)
+	f.counter("memory_failcnt_total", "The number of times the container hit its memory limit", mem.Failcnt, labels)
+
+	for _, e := range s.BlkioStats.IoServiceBytesRecursive {
+		f.counter("blkio_service_bytes_total", "The number of bytes transferred to and from block devices by the container", e.Value, labels, blkioLabels(e)...)
+	}
+	for _, e := range s.BlkioStats.IoServicedRecursive {
+		f.counter("blkio_serviced_total", "The number of IO operations performed on block devices by the container", e.Value, labels, blkioLabels(e)...)
+	}
+
+	for iface, n := range s.Networks {
+		l := labelPair("interface", iface)
+		f.counter("network_receive_bytes_total", "The number of bytes received by the container", n.RxBytes, labels, l)
+		f.counter("network_receive_packets_total", "The number of packets received by the container", n.RxPackets, labels, l)
+		f.counter("network_receive_errors_total", "The number of errors while receiving", n.RxErrors, labels, l)
+		f.counter("network_receive_dropped_total", "The number of received packets dropped", n.RxDropped, labels, l)
+		f.counter("network_transmit_bytes_total", "The number of bytes transmitted by the container", n.TxBytes, labels, l)
+		f.counter("network_transmit_packets_total", "The number of packets transmitted by the container", n.TxPackets, labels, l)
+		f.counter("network_transmit_errors_total", "The number of errors while transmitting", n.TxErrors, labels, l)
+		f.counter("network_transmit_dropped_total", "The number of transmitted packets dropped", n.TxDropped, labels, l)
+	}
+
+	f.gauge("pids_current", "The number of processes running in the container", s.PidsStats.Current, labels)
+	f.gauge("pids_limit", "The maximum number of processes allowed in the container", s.PidsStats.Limit, labels)
+}
+
+func blkioLabels(e types.BlkioStatEntry) []*dto.LabelPair {
+	return []*dto.LabelPair{
+		labelPair("device", fmt.Sprintf("%d:%d", e.Major, e.Minor)),
+		labelPair("op", strings.ToLower(e.Op)),
+	}
+}
@@ -0,0 +1,84 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/stats"
+)
+
+type fakeStatsSupervisor struct {
+	stats map[string]*types.StatsJSON
+}
+
+func (s *fakeStatsSupervisor) GetContainerStats(c *container.Container) (*types.StatsJSON, error) {
+	return s.stats[c.ID], nil
+}
+
+func TestContainerMetricsHandler(t *testing.T) {
+	running := &container.Container{
+		ID:   "running",
+		Name: "/web",
+		Config: &containertypes.Config{
+			Image:  "nginx:latest",
+			Labels: map[string]string{"com.example.team": "frontend", "ignored": "label"},
+		},
+		State: &container.State{Running: true},
+	}
+	stopped := &container.Container{
+		ID:     "stopped",
+		Name:   "/db",
+		Config: &containertypes.Config{Image: "postgres"},
+		State:  &container.State{},
+	}
+
+	s := &types.StatsJSON{}
+	s.CPUStats.CPUUsage.TotalUsage = 2500000000
+	s.MemoryStats.Usage = 1024
+	s.MemoryStats.Limit = 4096
+	s.PidsStats.Current = 3
+	s.BlkioStats.IoServiceBytesRecursive = []types.BlkioStatEntry{{Major: 8, Minor: 0, Op: "Read", Value: 512}}
+	s.Networks = map[string]types.NetworkStats{"eth0": {RxBytes: 100, TxBytes: 200}}
+
+	d := &Daemon{
+		containers: container.NewMemoryStore(),
+		statsCollector: stats.NewCollector(&fakeStatsSupervisor{
+			stats: map[string]*types.StatsJSON{"running": s, "stopped": {}},
+		}, time.Second),
+	}
+	d.containers.Add(running.ID, running)
+	d.containers.Add(stopped.ID, stopped)
+
+	rec := httptest.NewRecorder()
+	d.ContainerMetricsHandler([]string{"com.example.team"}).ServeHTTP(rec, httptest.NewRequest("GET", "/metrics/containers", nil))
+	b, err := ioutil.ReadAll(rec.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	out := string(b)
+
+	labels := `name="web",image="nginx:latest",container_label_com_example_team="frontend"`
+	for _, expected := range []string{
+		"# TYPE engine_container_cpu_usage_seconds_total counter",
+		"engine_container_cpu_usage_seconds_total{" + labels + "} 2.5",
+		"engine_container_memory_usage_bytes{" + labels + "} 1024",
+		"engine_container_memory_limit_bytes{" + labels + "} 4096",
+		"engine_container_pids_current{" + labels + "} 3",
+		`engine_container_blkio_service_bytes_total{` + labels + `,device="8:0",op="read"} 512`,
+		`engine_container_network_receive_bytes_total{` + labels + `,interface="eth0"} 100`,
+		`engine_container_network_transmit_bytes_total{` + labels + `,interface="eth0"} 200`,
+	} {
+		if !strings.Contains(out, expected) {
+			t.Fatalf("expected output to contain %q, got:\n%s", expected, out)
+		}
+	}
+	if strings.Contains(out, "postgres") || strings.Contains(out, "ignored") {
+		t.Fatalf("expected only the running container and selected labels, got:\n%s", out)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"runtime"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/docker/docker/api/types"
@@ -12,6 +13,7 @@ import (
 	"github.com/docker/docker/libcontainerd"
 	"github.com/docker/docker/restartmanager"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 func (daemon *Daemon) setStateCounter(c *container.Container) {
@@ -38,6 +40,9 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 		if runtime.GOOS == "windows" {
 			return errors.New("received StateOOM from libcontainerd on Windows. This should never happen")
 		}
+		c.Lock()
+		c.AddOOMEvent()
+		c.Unlock()
 		daemon.updateHealthMonitor(c)
 		if err := c.CheckpointTo(daemon.containersReplica); err != nil {
 			return err
@@ -65,6 +70,7 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 		attributes := map[string]string{
 			"exitCode": strconv.Itoa(int(e.ExitCode)),
 		}
+		addTerminationAttributes(attributes, c.TerminationReason)
 		daemon.LogContainerEventWithAttributes(c, "die", attributes)
 		daemon.Cleanup(c)
 
@@ -76,7 +82,7 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 					// But containerStart will use daemon.netController segment.
 					// So to avoid panic at startup process, here must wait util daemon restore done.
 					daemon.waitForStartupDone()
-					if err = daemon.containerStart(c, "", "", false); err != nil {
+					if err = daemon.containerStart(context.Background(), c, "", "", false); err != nil {
 						logrus.Debugf("failed to restart container: %+v", err)
 					}
 				}
@@ -104,6 +110,7 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			defer execConfig.Unlock()
 			execConfig.ExitCode = &ec
 			execConfig.Running = false
+			execConfig.StopTimeout()
 			execConfig.StreamConfig.Wait()
 			if err := execConfig.CloseStreams(); err != nil {
 				logrus.Errorf("failed to cleanup exec %s streams: %s", c.ID, err)
@@ -112,6 +119,11 @@ func (daemon *Daemon) StateChanged(id string, e libcontainerd.StateInfo) error {
 			// remove the exec command from the container's store only and not the
 			// daemon's store so that the exec command can be inspected.
 			c.ExecCommands.Delete(execConfig.ID)
+
+			daemon.LogContainerEventWithAttributes(c, "exec_die", map[string]string{
+				"execID":   execConfig.ID,
+				"exitCode": strconv.Itoa(ec),
+			})
 		} else {
 			logrus.Warnf("Ignoring StateExitProcess for %v but no exec command found", e)
 		}
@@ -171,3 +183,21 @@ func (daemon *Daemon) autoRemove(c *container.Container) {
 		logrus.WithError(err).WithField("container", c.ID).Error("error removing container")
 	}
 }
+
+// addTerminationAttributes adds the termination reason of a container to the
+// attributes of its die event.
+func addTerminationAttributes(attributes map[string]string, reason *types.TerminationReason) {
+	if reason == nil {
+		return
+	}
+	attributes["initiator"] = string(reason.Initiator)
+	if reason.Signal != 0 {
+		attributes["signal"] = strconv.Itoa(reason.Signal)
+	}
+	if reason.OOMEvents != 0 {
+		attributes["oomEvents"] = strconv.Itoa(reason.OOMEvents)
+	}
+	if len(reason.Stderr) > 0 {
+		attributes["stderr"] = strings.Join(reason.Stderr, "\n")
+	}
+}
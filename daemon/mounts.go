@@ -2,11 +2,17 @@ package daemon
 
 import (
 	"fmt"
+	"path"
+	"strconv"
 	"strings"
 
 	mounttypes "github.com/docker/docker/api/types/mount"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/mount"
+	"github.com/docker/docker/volume"
 	volumestore "github.com/docker/docker/volume/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 func (daemon *Daemon) prepareMountPoints(container *container.Container) error {
@@ -51,3 +57,138 @@ func (daemon *Daemon) removeMountPoints(container *container.Container, rm bool)
 	}
 	return nil
 }
+
+// ContainerMountAdd mounts a volume or a host path into a running Linux
+// container. The mount is kept when the container is restarted.
+func (daemon *Daemon) ContainerMountAdd(name string, cfg mounttypes.Mount) (retErr error) {
+	c, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+	if c.Platform != "linux" {
+		return validationError{errors.New("mounts can only be added to running Linux containers")}
+	}
+	if cfg.Type != mounttypes.TypeBind && cfg.Type != mounttypes.TypeVolume {
+		return validationError{errors.Errorf("mount type %q cannot be added to a running container", cfg.Type)}
+	}
+	mp, err := volume.NewParser(c.Platform).ParseMountSpec(cfg)
+	if err != nil {
+		return validationError{err}
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.Running {
+		return errNotRunning(c.ID)
+	}
+	if c.Restarting {
+		return errContainerIsRestarting(c.ID)
+	}
+	if _, exists := c.MountPoints[mp.Destination]; exists {
+		return duplicateMountPointError(mp.Destination)
+	}
+	if _, exists := c.HostConfig.Tmpfs[mp.Destination]; exists {
+		return duplicateMountPointError(mp.Destination)
+	}
+
+	if mp.Type == mounttypes.TypeVolume {
+		var v volume.Volume
+		if cfg.VolumeOptions != nil {
+			var driverOpts map[string]string
+			if cfg.VolumeOptions.DriverConfig != nil {
+				driverOpts = cfg.VolumeOptions.DriverConfig.Options
+			}
+			v, err = daemon.volumes.CreateWithRef(mp.Name, mp.Driver, c.ID, driverOpts, cfg.VolumeOptions.Labels)
+		} else {
+			v, err = daemon.volumes.CreateWithRef(mp.Name, mp.Driver, c.ID, nil, nil)
+		}
+		if err != nil {
+			return err
+		}
+		defer func() {
+			if retErr != nil {
+				daemon.volumes.Dereference(v, c.ID)
+			}
+		}()
+		mp.Volume = v
+		mp.Name = v.Name()
+		mp.Driver = v.DriverName()
+		if mp.Driver == volume.DefaultDriverName {
+			setBindModeIfNull(mp)
+		}
+	}
+
+	src, err := mp.Setup(c.MountLabel, daemon.idMappings.RootPair(), nil)
+	if err != nil {
+		return errors.Wrapf(err, "error setting up mount %s", mp.Destination)
+	}
+	if err := mount.BindMountInNamespace(c.Pid, src, mp.Destination, !mp.RW); err != nil {
+		if cleanupErr := mp.Cleanup(); cleanupErr != nil {
+			logrus.Warnf("Failed to clean up mount %s of container %s: %v", mp.Destination, c.ID, cleanupErr)
+		}
+		return systemError{errors.Wrapf(err, "error mounting %s in container %s", mp.Destination, c.ID)}
+	}
+
+	c.MountPoints[mp.Destination] = mp
+	if err := c.CheckpointTo(daemon.containersReplica); err != nil {
+		return err
+	}
+
+	if mp.Volume != nil {
+		attributes := map[string]string{
+			"driver":      mp.Volume.DriverName(),
+			"container":   c.ID,
+			"destination": mp.Destination,
+			"read/write":  strconv.FormatBool(mp.RW),
+			"propagation": string(mp.Propagation),
+		}
+		daemon.LogVolumeEvent(mp.Volume.Name(), "mount", attributes)
+	}
+	return nil
+}
+
+// ContainerMountRemove unmounts the volume or host path mounted at target
+// from a running container, and removes the mount from the container.
+func (daemon *Daemon) ContainerMountRemove(name, target string) error {
+	c, err := daemon.GetContainer(name)
+	if err != nil {
+		return err
+	}
+
+	c.Lock()
+	defer c.Unlock()
+
+	if !c.Running {
+		return errNotRunning(c.ID)
+	}
+	if c.Restarting {
+		return errContainerIsRestarting(c.ID)
+	}
+	mp, exists := c.MountPoints[path.Clean(target)]
+	if !exists {
+		return objNotFoundError{"mount", target}
+	}
+	if err := daemon.lazyInitializeVolume(c.ID, mp); err != nil {
+		return err
+	}
+
+	if err := mount.UnmountInNamespace(c.Pid, mp.Destination); err != nil {
+		return systemError{errors.Wrapf(err, "error unmounting %s from container %s", mp.Destination, c.ID)}
+	}
+	delete(c.MountPoints, mp.Destination)
+
+	if mp.Volume != nil {
+		if err := mp.Cleanup(); err != nil {
+			logrus.Warnf("Failed to clean up mount %s of container %s: %v", mp.Destination, c.ID, err)
+		}
+		daemon.volumes.Dereference(mp.Volume, c.ID)
+		attributes := map[string]string{
+			"driver":    mp.Volume.DriverName(),
+			"container": c.ID,
+		}
+		daemon.LogVolumeEvent(mp.Volume.Name(), "unmount", attributes)
+	}
+
+	return c.CheckpointTo(daemon.containersReplica)
+}
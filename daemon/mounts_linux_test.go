@@ -0,0 +1,138 @@
+package daemon
+
+import (
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/errdefs"
+	containertypes "github.com/docker/docker/api/types/container"
+	mounttypes "github.com/docker/docker/api/types/mount"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+	"github.com/docker/docker/pkg/idtools"
+	"github.com/docker/docker/volume"
+)
+
+func newMountTestDaemon(t *testing.T, root string) (*Daemon, *container.Container) {
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &container.Container{
+		ID:          "mount_container",
+		Name:        "/mount_container",
+		Root:        root,
+		Platform:    "linux",
+		Config:      &containertypes.Config{},
+		HostConfig:  &containertypes.HostConfig{},
+		State:       container.NewState(),
+		MountPoints: make(map[string]*volume.MountPoint),
+	}
+	d := &Daemon{
+		containers:        container.NewMemoryStore(),
+		containersReplica: store,
+		EventsService:     events.New(),
+		idMappings:        &idtools.IDMappings{},
+	}
+	d.containers.Add(c.ID, c)
+	return d, c
+}
+
+func TestContainerMountAddErrors(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-mounts-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	d, c := newMountTestDaemon(t, root)
+
+	err = d.ContainerMountAdd(c.ID, mounttypes.Mount{Type: mounttypes.TypeTmpfs, Target: "/tmp"})
+	if !errdefs.IsInvalidParameter(err) {
+		t.Fatalf("expected an invalid parameter error for a tmpfs mount, got %v", err)
+	}
+
+	err = d.ContainerMountAdd(c.ID, mounttypes.Mount{Type: mounttypes.TypeBind, Source: root, Target: "/data"})
+	if !errdefs.IsConflict(err) {
+		t.Fatalf("expected a conflict error for a stopped container, got %v", err)
+	}
+
+	c.State.Running = true
+	c.MountPoints["/data"] = &volume.MountPoint{Destination: "/data"}
+	err = d.ContainerMountAdd(c.ID, mounttypes.Mount{Type: mounttypes.TypeBind, Source: root, Target: "/data"})
+	if !errdefs.IsInvalidParameter(err) {
+		t.Fatalf("expected an invalid parameter error for a duplicate mount point, got %v", err)
+	}
+
+	err = d.ContainerMountRemove(c.ID, "/other")
+	if !errdefs.IsNotFound(err) {
+		t.Fatalf("expected a not found error for a missing mount point, got %v", err)
+	}
+}
+
+func TestContainerMountAddRemove(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("root required")
+	}
+	root, err := ioutil.TempDir("", "docker-mounts-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+	source := filepath.Join(root, "source")
+	if err := os.Mkdir(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+	target := filepath.Join(root, "target")
+
+	// the process stands in for the container, in its own mount namespace
+	ready := filepath.Join(root, "ready")
+	cmd := exec.Command("sh", "-c", "mount --make-rprivate / && touch "+ready+" && exec sleep 60")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNS}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot create a mount namespace: %v", err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	for i := 0; ; i++ {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		if i == 50 {
+			t.Skip("cannot set up the mount namespace")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+
+	d, c := newMountTestDaemon(t, root)
+	c.State.Running = true
+	c.State.Pid = cmd.Process.Pid
+
+	if err := d.ContainerMountAdd(c.ID, mounttypes.Mount{Type: mounttypes.TypeBind, Source: source, Target: target}); err != nil {
+		if errdefs.IsSystem(err) {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if _, ok := c.MountPoints[target]; !ok {
+		t.Fatalf("expected a mount point for %s, got %v", target, c.MountPoints)
+	}
+	snapshot, err := d.containersReplica.Snapshot().Get(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshot.Mounts) != 1 || snapshot.Mounts[0].Destination != target {
+		t.Fatalf("expected the mount to be saved in the view, got %v", snapshot.Mounts)
+	}
+
+	if err := d.ContainerMountRemove(c.ID, target); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.MountPoints[target]; ok {
+		t.Fatalf("expected the mount point for %s to be removed", target)
+	}
+}
@@ -38,10 +38,11 @@ var (
 		"label!": true,
 	}
 	imagesAcceptedFilters = map[string]bool{
-		"dangling": true,
-		"label":    true,
-		"label!":   true,
-		"until":    true,
+		"dangling":   true,
+		"label":      true,
+		"label!":     true,
+		"until":      true,
+		"unused-for": true,
 	}
 	networksAcceptedFilters = map[string]bool{
 		"label":  true,
@@ -195,6 +196,11 @@ func (daemon *Daemon) ImagesPrune(ctx context.Context, pruneFilters filters.Args
 		return nil, err
 	}
 
+	unusedSince, err := getUnusedSinceFromPruneFilters(pruneFilters, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
 	var allImages map[image.ID]*image.Image
 	if danglingOnly {
 		allImages = daemon.stores[platform].imageStore.Heads()
@@ -227,6 +233,9 @@ func (daemon *Daemon) ImagesPrune(ctx context.Context, pruneFilters filters.Args
 			if !until.IsZero() && img.Created.After(until) {
 				continue
 			}
+			if !unusedSince.IsZero() && imageLastUsed(daemon.stores[platform].imageStore, img).After(unusedSince) {
+				continue
+			}
 			if img.Config != nil && !matchLabels(pruneFilters, img.Config.Labels) {
 				continue
 			}
@@ -459,6 +468,23 @@ func getUntilFromPruneFilters(pruneFilters filters.Args) (time.Time, error) {
 	return until, nil
 }
 
+// getUnusedSinceFromPruneFilters returns the time before which the images
+// matching the unused-for filter were last used.
+func getUnusedSinceFromPruneFilters(pruneFilters filters.Args, now time.Time) (time.Time, error) {
+	if !pruneFilters.Include("unused-for") {
+		return time.Time{}, nil
+	}
+	unusedForFilters := pruneFilters.Get("unused-for")
+	if len(unusedForFilters) > 1 {
+		return time.Time{}, fmt.Errorf("more than one unused-for filter specified")
+	}
+	unusedFor, err := time.ParseDuration(unusedForFilters[0])
+	if err != nil || unusedFor < 0 {
+		return time.Time{}, invalidFilter{"unused-for", unusedForFilters[0]}
+	}
+	return now.Add(-unusedFor), nil
+}
+
 func matchLabels(pruneFilters filters.Args, labels map[string]string) bool {
 	if !pruneFilters.MatchKVList("label", labels) {
 		return false
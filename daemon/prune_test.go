@@ -0,0 +1,35 @@
+package daemon
+
+import (
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types/filters"
+)
+
+func TestGetUnusedSinceFromPruneFilters(t *testing.T) {
+	now := time.Now()
+
+	unusedSince, err := getUnusedSinceFromPruneFilters(filters.NewArgs(), now)
+	if err != nil || !unusedSince.IsZero() {
+		t.Fatalf("expected no unused-for filter, got %v, %v", unusedSince, err)
+	}
+
+	unusedSince, err = getUnusedSinceFromPruneFilters(filters.NewArgs(filters.Arg("unused-for", "24h")), now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := now.Add(-24 * time.Hour); !unusedSince.Equal(expected) {
+		t.Fatalf("expected %v, got %v", expected, unusedSince)
+	}
+
+	for _, args := range []filters.Args{
+		filters.NewArgs(filters.Arg("unused-for", "a day")),
+		filters.NewArgs(filters.Arg("unused-for", "-1h")),
+		filters.NewArgs(filters.Arg("unused-for", "1h"), filters.Arg("unused-for", "2h")),
+	} {
+		if _, err := getUnusedSinceFromPruneFilters(args, now); err == nil {
+			t.Fatalf("expected an error for %v", args)
+		}
+	}
+}
@@ -23,6 +23,7 @@ import (
 // - Insecure registries
 // - Registry mirrors
 // - Daemon live restore
+// - Event sinks
 func (daemon *Daemon) Reload(conf *config.Config) (err error) {
 	daemon.configStore.Lock()
 	attributes := map[string]string{}
@@ -59,9 +60,15 @@ func (daemon *Daemon) Reload(conf *config.Config) (err error) {
 	if err := daemon.reloadRegistryMirrors(conf, attributes); err != nil {
 		return err
 	}
+	if err := daemon.reloadRegistryMirrorRules(conf, attributes); err != nil {
+		return err
+	}
 	if err := daemon.reloadLiveRestore(conf, attributes); err != nil {
 		return err
 	}
+	if err := daemon.reloadEventSinks(conf, attributes); err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -297,6 +304,34 @@ func (daemon *Daemon) reloadRegistryMirrors(conf *config.Config, attributes map[
 	return nil
 }
 
+// reloadRegistryMirrorRules updates configuration with registry mirror rules
+// option and updates the passed attributes
+func (daemon *Daemon) reloadRegistryMirrorRules(conf *config.Config, attributes map[string]string) error {
+	// update corresponding configuration
+	if conf.IsValueSet("registry-mirror-rules") {
+		if err := daemon.RegistryService.LoadMirrorRules(conf.MirrorRules); err != nil {
+			return err
+		}
+		daemon.configStore.MirrorRules = conf.MirrorRules
+	}
+
+	// prepare reload event attributes with updatable configurations, without
+	// the credentials of the mirrors
+	rules := make(map[string][]string)
+	for _, rule := range daemon.configStore.MirrorRules {
+		for _, m := range rule.Mirrors {
+			rules[rule.Repository] = append(rules[rule.Repository], m.Location)
+		}
+	}
+	mirrorRules, err := json.Marshal(rules)
+	if err != nil {
+		return err
+	}
+	attributes["registry-mirror-rules"] = string(mirrorRules)
+
+	return nil
+}
+
 // reloadLiveRestore updates configuration with live retore option
 // and updates the passed attributes
 func (daemon *Daemon) reloadLiveRestore(conf *config.Config, attributes map[string]string) error {
@@ -312,3 +347,29 @@ func (daemon *Daemon) reloadLiveRestore(conf *config.Config, attributes map[stri
 	attributes["live-restore"] = fmt.Sprintf("%t", daemon.configStore.LiveRestoreEnabled)
 	return nil
 }
+
+// reloadEventSinks updates configuration with event sinks option
+// and updates the passed attributes
+func (daemon *Daemon) reloadEventSinks(conf *config.Config, attributes map[string]string) error {
+	// update corresponding configuration
+	if conf.IsValueSet("event-sinks") {
+		if err := daemon.setEventSinks(conf.EventSinks); err != nil {
+			return err
+		}
+		daemon.configStore.EventSinks = conf.EventSinks
+	}
+
+	// prepare reload event attributes with updatable configurations,
+	// only the types of the sinks are included as their addresses and
+	// options may hold credentials
+	types := []string{}
+	for _, sink := range daemon.configStore.EventSinks {
+		types = append(types, sink.Type)
+	}
+	sinkTypes, err := json.Marshal(types)
+	if err != nil {
+		return err
+	}
+	attributes["event-sinks"] = string(sinkTypes)
+	return nil
+}
@@ -3,12 +3,17 @@
 package daemon
 
 import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
 	"sort"
 	"testing"
 	"time"
 
+	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/daemon/events"
 	"github.com/docker/docker/pkg/discovery"
 	_ "github.com/docker/docker/pkg/discovery/memory"
 	"github.com/docker/docker/registry"
@@ -42,6 +47,51 @@ func TestDaemonReloadLabels(t *testing.T) {
 	}
 }
 
+func TestDaemonReloadEventSinks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "reload-event-sinks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	daemon := &Daemon{
+		configStore:   &config.Config{},
+		EventsService: events.New(),
+	}
+	defer daemon.closeEventSinks()
+
+	sinks := []config.EventSinkConfig{
+		{Type: "file", Address: filepath.Join(dir, "events.log")},
+	}
+	newConfig := &config.Config{
+		CommonConfig: config.CommonConfig{
+			EventSinks: sinks,
+			ValuesSet: map[string]interface{}{
+				"event-sinks": sinks,
+			},
+		},
+	}
+	attributes := map[string]string{}
+	if err := daemon.reloadEventSinks(newConfig, attributes); err != nil {
+		t.Fatal(err)
+	}
+	if attributes["event-sinks"] != `["file"]` {
+		t.Fatalf("unexpected event-sinks attribute: %s", attributes["event-sinks"])
+	}
+	if len(daemon.eventForwarders) != 1 {
+		t.Fatalf("expected 1 event sink, got %d", len(daemon.eventForwarders))
+	}
+
+	// an invalid sink must not replace the running ones
+	newConfig.EventSinks = []config.EventSinkConfig{{Type: "file", Address: "relative.log"}}
+	if err := daemon.reloadEventSinks(newConfig, attributes); err == nil {
+		t.Fatal("expected an error reloading an invalid event sink")
+	}
+	if len(daemon.eventForwarders) != 1 || daemon.configStore.EventSinks[0].Address != sinks[0].Address {
+		t.Fatal("expected the previous event sink to be kept")
+	}
+}
+
 func TestDaemonReloadAllowNondistributableArtifacts(t *testing.T) {
 	daemon := &Daemon{
 		configStore: &config.Config{},
@@ -194,6 +244,61 @@ func TestDaemonReloadMirrors(t *testing.T) {
 	}
 }
 
+func TestDaemonReloadMirrorRules(t *testing.T) {
+	daemon := &Daemon{}
+	var err error
+	daemon.RegistryService, err = registry.NewService(registry.ServiceOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	daemon.configStore = &config.Config{}
+
+	ref, err := reference.ParseNormalizedNamed("ghcr.io/org/app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reload := func(rules []registry.MirrorRule) error {
+		return daemon.Reload(&config.Config{
+			CommonConfig: config.CommonConfig{
+				ServiceOptions: registry.ServiceOptions{
+					MirrorRules: rules,
+				},
+				ValuesSet: map[string]interface{}{"registry-mirror-rules": rules},
+			},
+		})
+	}
+
+	rules := []registry.MirrorRule{{Repository: "ghcr.io/org/*", Mirrors: []registry.Mirror{{Location: "mirror.local/ghcr/org/*"}}}}
+	if err := reload(rules); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err := daemon.RegistryService.LookupPullEndpoints(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoints[0].URL.Host != "mirror.local" || endpoints[0].RemoteName != "ghcr/org/app" {
+		t.Fatalf("expected the mirror of the rule to be the first endpoint, got %s", endpoints[0].URL)
+	}
+
+	if err := reload([]registry.MirrorRule{{Repository: "ghcr.io/org/*"}}); err == nil {
+		t.Fatal("expected daemon reload error with an invalid mirror rule")
+	}
+	if !reflect.DeepEqual(daemon.configStore.MirrorRules, rules) {
+		t.Fatalf("expected the mirror rules to be kept after an invalid reload, got %v", daemon.configStore.MirrorRules)
+	}
+
+	if err := reload(nil); err != nil {
+		t.Fatal(err)
+	}
+	endpoints, err = daemon.RegistryService.LookupPullEndpoints(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoints[0].URL.Host != "ghcr.io" {
+		t.Fatalf("expected no mirror after the rules are removed, got %s", endpoints[0].URL)
+	}
+}
+
 func TestDaemonReloadInsecureRegistries(t *testing.T) {
 	daemon := &Daemon{}
 	var err error
@@ -41,6 +41,13 @@ func (daemon *Daemon) ContainerRename(oldName, newName string) error {
 		return validationError{errors.New("Renaming a container with the same name as its current name")}
 	}
 
+	if dependents := daemon.dependents(container); len(dependents) > 0 {
+		return stateConflictError{errors.Errorf("cannot rename container %s: it is a dependency of %s", strings.TrimPrefix(oldName, "/"), strings.Join(dependents, ", "))}
+	}
+	if dependents := daemon.dependentsOfName(newName); len(dependents) > 0 {
+		return stateConflictError{errors.Errorf("cannot rename container %s to %s: the name is a dependency of %s", strings.TrimPrefix(oldName, "/"), strings.TrimPrefix(newName, "/"), strings.Join(dependents, ", "))}
+	}
+
 	links := map[string]*dockercontainer.Container{}
 	for k, v := range daemon.linkIndex.children(container) {
 		if !strings.HasPrefix(k, oldName) {
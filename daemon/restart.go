@@ -3,8 +3,10 @@ package daemon
 import (
 	"fmt"
 
+	"github.com/docker/docker/api/types"
 	"github.com/docker/docker/container"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 // ContainerRestart stops and starts a container. It attempts to
@@ -47,7 +49,7 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		autoRemove := container.HostConfig.AutoRemove
 
 		container.HostConfig.AutoRemove = false
-		err := daemon.containerStop(container, seconds)
+		err := daemon.containerStop(container, seconds, types.TerminationRestart)
 		// restore AutoRemove irrespective of whether the stop worked or not
 		container.HostConfig.AutoRemove = autoRemove
 		// containerStop will write HostConfig to disk, we shall restore AutoRemove
@@ -61,7 +63,7 @@ func (daemon *Daemon) containerRestart(container *container.Container, seconds i
 		}
 	}
 
-	if err := daemon.containerStart(container, "", "", true); err != nil {
+	if err := daemon.containerStart(context.Background(), container, "", "", true); err != nil {
 		return err
 	}
 
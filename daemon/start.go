@@ -7,15 +7,19 @@ import (
 	"github.com/docker/docker/api/types"
 	containertypes "github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/tracing"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
 )
 
 // ContainerStart starts a container.
-func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.HostConfig, checkpoint string, checkpointDir string) error {
-	if checkpoint != "" && !daemon.HasExperimental() {
-		return validationError{errors.New("checkpoint is only supported in experimental mode")}
-	}
+func (daemon *Daemon) ContainerStart(ctx context.Context, name string, hostConfig *containertypes.HostConfig, checkpoint string, checkpointDir string) (retErr error) {
+	span, ctx := tracing.StartSpan(ctx, "daemon.ContainerStart")
+	defer func() {
+		span.SetError(retErr)
+		span.Finish()
+	}()
 
 	container, err := daemon.GetContainer(name)
 	if err != nil {
@@ -90,7 +94,11 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 		}
 	}
 
-	if err := daemon.containerStart(container, checkpoint, checkpointDir, true); err != nil {
+	if err := daemon.startDependencies(ctx, container); err != nil {
+		return err
+	}
+
+	if err := daemon.containerStart(ctx, container, checkpoint, checkpointDir, true); err != nil {
 		return err
 	}
 	return nil
@@ -100,7 +108,7 @@ func (daemon *Daemon) ContainerStart(name string, hostConfig *containertypes.Hos
 // container needs, such as storage and networking, as well as links
 // between containers. The container is left waiting for a signal to
 // begin running.
-func (daemon *Daemon) containerStart(container *container.Container, checkpoint string, checkpointDir string, resetRestartManager bool) (err error) {
+func (daemon *Daemon) containerStart(ctx context.Context, container *container.Container, checkpoint string, checkpointDir string, resetRestartManager bool) (err error) {
 	start := time.Now()
 	container.Lock()
 	defer container.Unlock()
@@ -143,7 +151,11 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 		return err
 	}
 
-	if err := daemon.initializeNetworking(container); err != nil {
+	span, _ := tracing.StartSpan(ctx, "network.initialize")
+	err = daemon.initializeNetworking(container)
+	span.SetError(err)
+	span.Finish()
+	if err != nil {
 		return err
 	}
 
@@ -169,9 +181,14 @@ func (daemon *Daemon) containerStart(container *container.Container, checkpoint
 		return err
 	}
 
-	if err := daemon.containerd.Create(container.ID, checkpoint, checkpointDir, *spec, container.InitializeStdio, createOptions...); err != nil {
-		return translateContainerdStartErr(container.Path, container.SetExitCode, err)
+	container.CaptureStderr()
 
+	span, _ = tracing.StartSpan(ctx, "libcontainerd.create")
+	err = daemon.containerd.Create(container.ID, checkpoint, checkpointDir, *spec, container.InitializeStdio, createOptions...)
+	span.SetError(err)
+	span.Finish()
+	if err != nil {
+		return translateContainerdStartErr(container.Path, container.SetExitCode, err)
 	}
 
 	containerActions.WithValues("start").UpdateSince(start)
@@ -40,3 +40,9 @@ type Collector struct {
 	// The following fields are not set on Windows currently.
 	clockTicksPerSecond uint64
 }
+
+// Snapshot returns the current stats of a single container, as they would
+// be published to the subscribers of the container on the next collection.
+func (s *Collector) Snapshot(c *container.Container) (*types.StatsJSON, error) {
+	return s.supervisor.GetContainerStats(c)
+}
@@ -4,11 +4,18 @@ import (
 	"context"
 	"time"
 
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
 	containerpkg "github.com/docker/docker/container"
+	"github.com/docker/docker/pkg/signal"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
 )
 
+// defaultStopHookTimeout is the time a stop hook may run for when its
+// timeout is not set.
+const defaultStopHookTimeout = 30 * time.Second
+
 // ContainerStop looks for the given container and terminates it,
 // waiting the given number of seconds before forcefully killing the
 // container. If a negative number of seconds is given, ContainerStop
@@ -27,27 +34,30 @@ func (daemon *Daemon) ContainerStop(name string, seconds *int) error {
 		stopTimeout := container.StopTimeout()
 		seconds = &stopTimeout
 	}
-	if err := daemon.containerStop(container, *seconds); err != nil {
+	if err := daemon.containerStop(container, *seconds, types.TerminationStop); err != nil {
 		return errors.Wrapf(systemError{err}, "cannot stop container: %s", name)
 	}
 	return nil
 }
 
-// containerStop halts a container by sending a stop signal, waiting for the given
+// containerStop halts a container by running its stop hook, sending a stop
+// signal and the signals of its stop escalation, waiting for the given
 // duration in seconds, and then calling SIGKILL and waiting for the
 // process to exit. If a negative duration is given, Stop will wait
-// for the initial signal forever. If the container is not running Stop returns
-// immediately.
-func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds int) error {
+// for the last signal forever. If the container is not running Stop returns
+// immediately. initiator is recorded as the cause of the termination, unless
+// the container has to be killed after the timeout.
+func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds int, initiator types.TerminationInitiator) error {
 	if !container.IsRunning() {
 		return nil
 	}
 
 	daemon.stopHealthchecks(container)
+	daemon.runStopHook(container)
 
 	stopSignal := container.StopSignal()
 	// 1. Send a stop signal
-	if err := daemon.killPossiblyDeadProcess(container, stopSignal); err != nil {
+	if err := daemon.killPossiblyDeadProcess(container, stopSignal, initiator, true); err != nil {
 		// While normally we might "return err" here we're not going to
 		// because if we can't stop the container by this point then
 		// it's probably because it's already stopped. Meaning, between
@@ -63,20 +73,45 @@ func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds i
 
 		if status := <-container.Wait(ctx, containerpkg.WaitConditionNotRunning); status.Err() != nil {
 			logrus.Infof("Container failed to stop after sending signal %d to the process, force killing", stopSignal)
-			if err := daemon.killPossiblyDeadProcess(container, 9); err != nil {
+			if err := daemon.killPossiblyDeadProcess(container, 9, initiator, true); err != nil {
 				return err
 			}
 		}
 	}
 
-	// 2. Wait for the process to exit on its own
+	// 2. Send the escalation signals, each one if the process did not exit
+	// within the delay of its stage
+	lastSignal := stopSignal
+	for _, stage := range container.Config.StopEscalation {
+		ctx, cancel := context.WithTimeout(context.Background(), stage.Delay)
+		status := <-container.Wait(ctx, containerpkg.WaitConditionNotRunning)
+		cancel()
+		if status.Err() == nil {
+			break
+		}
+		sig, err := signal.ParseSignal(stage.Signal)
+		if err != nil {
+			logrus.Warnf("Invalid stop escalation signal %q for container %v: %v", stage.Signal, container.ID, err)
+			continue
+		}
+		logrus.Infof("Container %v failed to exit within %s of signal %d - sending signal %d", container.ID, stage.Delay, lastSignal, sig)
+		if err := daemon.killPossiblyDeadProcess(container, int(sig), initiator, true); err != nil {
+			if isErrNoSuchProcess(err) {
+				break
+			}
+			logrus.Warnf("Failed to send signal %d to container %v: %v", sig, container.ID, err)
+		}
+		lastSignal = int(sig)
+	}
+
+	// 3. Wait for the process to exit on its own
 	ctx, cancel := context.WithTimeout(context.Background(), time.Duration(seconds)*time.Second)
 	defer cancel()
 
 	if status := <-container.Wait(ctx, containerpkg.WaitConditionNotRunning); status.Err() != nil {
-		logrus.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, stopSignal)
-		// 3. If it doesn't, then send SIGKILL
-		if err := daemon.Kill(container); err != nil {
+		logrus.Infof("Container %v failed to exit within %d seconds of signal %d - using the force", container.ID, seconds, lastSignal)
+		// 4. If it doesn't, then send SIGKILL
+		if err := daemon.Kill(container, types.TerminationStopTimeout); err != nil {
 			// Wait without a timeout, ignore result.
 			<-container.Wait(context.Background(), containerpkg.WaitConditionNotRunning)
 			logrus.Warn(err) // Don't return error because we only care that container is stopped, not what function stopped it
@@ -86,3 +121,60 @@ func (daemon *Daemon) containerStop(container *containerpkg.Container, seconds i
 	daemon.LogContainerEvent(container, "stop")
 	return nil
 }
+
+// runStopHook runs the stop hook of the container, if it has one, and waits
+// for it to complete or to time out. Failures are logged, as they must not
+// prevent the container from being stopped.
+func (daemon *Daemon) runStopHook(c *containerpkg.Container) {
+	hook := c.Config.StopHook
+	if hook == nil || len(hook.Cmd) == 0 {
+		return
+	}
+	c.Lock()
+	runnable := c.Running && !c.Paused && !c.Restarting
+	c.Unlock()
+	if !runnable {
+		return
+	}
+
+	execConfig, err := daemon.newInternalExecConfig(c, hook.Cmd)
+	if err != nil {
+		logrus.Warnf("Failed to set up the stop hook of container %v: %v", c.ID, err)
+		return
+	}
+	execConfig.Timeout = hook.Timeout
+	if execConfig.Timeout == 0 {
+		execConfig.Timeout = defaultStopHookTimeout
+	}
+
+	output := &limitedBuffer{}
+	if err := daemon.ContainerExecStart(context.Background(), execConfig.ID, nil, output, output); err != nil {
+		logrus.Warnf("Failed to run the stop hook of container %v: %v", c.ID, err)
+		return
+	}
+
+	execConfig.Lock()
+	exitCode := execConfig.ExitCode
+	execConfig.Unlock()
+	if exitCode == nil || *exitCode != 0 {
+		logrus.Warnf("Stop hook of container %v failed: %s", c.ID, output.String())
+		return
+	}
+	logrus.Debugf("Stop hook of container %v completed: %s", c.ID, output.String())
+}
+
+// stopHookAndEscalationTimeout returns how long the stop hook and the stop
+// escalation of a container with the given config may delay its stop timeout.
+func stopHookAndEscalationTimeout(config *containertypes.Config) time.Duration {
+	var timeout time.Duration
+	if hook := config.StopHook; hook != nil && len(hook.Cmd) > 0 {
+		timeout = hook.Timeout
+		if timeout == 0 {
+			timeout = defaultStopHookTimeout
+		}
+	}
+	for _, stage := range config.StopEscalation {
+		timeout += stage.Delay
+	}
+	return timeout
+}
@@ -0,0 +1,129 @@
+package daemon
+
+import (
+	"reflect"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/config"
+	"github.com/docker/docker/daemon/events"
+)
+
+func TestStopHookAndEscalationTimeout(t *testing.T) {
+	escalation := []containertypes.StopStage{
+		{Signal: "SIGINT", Delay: time.Second},
+		{Signal: "QUIT", Delay: 2 * time.Second},
+	}
+	tests := []struct {
+		config   *containertypes.Config
+		expected time.Duration
+	}{
+		{config: &containertypes.Config{}},
+		{config: &containertypes.Config{StopHook: &containertypes.StopHookConfig{}}},
+		{config: &containertypes.Config{StopHook: &containertypes.StopHookConfig{Cmd: []string{"/drain"}}}, expected: defaultStopHookTimeout},
+		{config: &containertypes.Config{StopEscalation: escalation}, expected: 3 * time.Second},
+		{config: &containertypes.Config{StopEscalation: escalation, StopHook: &containertypes.StopHookConfig{Cmd: []string{"/drain"}, Timeout: 500 * time.Millisecond}}, expected: 3500 * time.Millisecond},
+	}
+	for _, tc := range tests {
+		if timeout := stopHookAndEscalationTimeout(tc.config); timeout != tc.expected {
+			t.Errorf("%+v: expected %s, got %s", tc.config, tc.expected, timeout)
+		}
+	}
+
+	stopTimeout := 10
+	d := &Daemon{
+		configStore: &config.Config{CommonConfig: config.CommonConfig{ShutdownTimeout: 15}},
+		containers:  container.NewMemoryStore(),
+	}
+	d.containers.Add("c1", &container.Container{
+		ID: "c1",
+		Config: &containertypes.Config{
+			StopTimeout:    &stopTimeout,
+			StopEscalation: escalation,
+			StopHook:       &containertypes.StopHookConfig{Cmd: []string{"/drain"}, Timeout: 500 * time.Millisecond},
+		},
+	})
+	// 10s of stop timeout, 3.5s of hook and escalation rounded up and 5s of
+	// grace time
+	if timeout := d.ShutdownTimeout(); timeout != 19 {
+		t.Fatalf("expected a shutdown timeout of 19 seconds, got %d", timeout)
+	}
+}
+
+func TestKillEscalationSignal(t *testing.T) {
+	c := &container.Container{
+		ID:   "kill_container",
+		Name: "/kill_container",
+		Config: &containertypes.Config{
+			StopSignal:     "SIGTERM",
+			StopEscalation: []containertypes.StopStage{{Signal: "SIGINT", Delay: time.Second}},
+		},
+		HostConfig: &containertypes.HostConfig{},
+		State:      container.NewState(),
+	}
+	client := &signalRecorder{signals: make(chan int, 1)}
+	d := &Daemon{
+		containers:    container.NewMemoryStore(),
+		EventsService: events.New(),
+		containerd:    client,
+	}
+	d.containers.Add(c.ID, c)
+
+	// A signal sent by docker kill is not a stop request, even if it is a
+	// stop escalation signal.
+	c.Running = true
+	if err := d.ContainerKill(c.ID, uint64(syscall.SIGINT)); err != nil {
+		t.Fatal(err)
+	}
+	<-client.signals
+	c.SetStopped(&container.ExitStatus{ExitCode: 128 + int(syscall.SIGINT)})
+	if c.TerminationReason.Initiator != types.TerminationSignal {
+		t.Fatalf("expected the container to be reported killed by a signal, got %+v", c.TerminationReason)
+	}
+
+	// The same signal sent by containerStop is.
+	c.Running = true
+	if err := d.killWithSignal(c, int(syscall.SIGINT), types.TerminationStop, true); err != nil {
+		t.Fatal(err)
+	}
+	<-client.signals
+	c.SetStopped(&container.ExitStatus{ExitCode: 128 + int(syscall.SIGINT)})
+	if c.TerminationReason.Initiator != types.TerminationStop {
+		t.Fatalf("expected the container to be reported stopped, got %+v", c.TerminationReason)
+	}
+}
+
+func TestMergeStopHook(t *testing.T) {
+	escalation := []containertypes.StopStage{{Signal: "SIGINT", Delay: time.Second}}
+	hook := &containertypes.StopHookConfig{Cmd: []string{"/drain"}, Timeout: 10 * time.Second}
+	configImage := &containertypes.Config{
+		StopSignal:     "SIGTERM",
+		StopEscalation: escalation,
+		StopHook:       hook,
+	}
+
+	configUser := &containertypes.Config{}
+	if err := merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.StopSignal != "SIGTERM" || !reflect.DeepEqual(configUser.StopEscalation, escalation) || configUser.StopHook != hook {
+		t.Fatalf("expected the stop settings of the image, got %+v", configUser)
+	}
+
+	// A stop signal set by the user replaces the escalation of the image,
+	// and an empty hook disables the hook of the image.
+	configUser = &containertypes.Config{
+		StopSignal: "SIGUSR1",
+		StopHook:   &containertypes.StopHookConfig{},
+	}
+	if err := merge(configUser, configImage); err != nil {
+		t.Fatal(err)
+	}
+	if configUser.StopSignal != "SIGUSR1" || configUser.StopEscalation != nil || len(configUser.StopHook.Cmd) != 0 {
+		t.Fatalf("expected the stop settings of the user, got %+v", configUser)
+	}
+}
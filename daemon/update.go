@@ -4,11 +4,15 @@ import (
 	"fmt"
 
 	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/daemon/logger"
 	"github.com/pkg/errors"
 )
 
-// ContainerUpdate updates configuration of the container
-func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostConfig) (container.ContainerUpdateOKBody, error) {
+// ContainerUpdate updates configuration of the container. Besides the
+// resources and restart policy in hostConfig, the labels, healthcheck and
+// log options of the container are updated if set in config and
+// hostConfig.LogConfig.Config.
+func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostConfig, config *container.Config) (container.ContainerUpdateOKBody, error) {
 	var warnings []string
 
 	c, err := daemon.GetContainer(name)
@@ -16,53 +20,82 @@ func (daemon *Daemon) ContainerUpdate(name string, hostConfig *container.HostCon
 		return container.ContainerUpdateOKBody{Warnings: warnings}, err
 	}
 
-	warnings, err = daemon.verifyContainerSettings(c.Platform, hostConfig, nil, true)
+	if config != nil && config.Healthcheck != nil && len(config.Healthcheck.Test) == 0 {
+		return container.ContainerUpdateOKBody{Warnings: warnings}, validationError{errors.New("Test in Healthcheck is required to update the healthcheck")}
+	}
+
+	warnings, err = daemon.verifyContainerSettings(c.Platform, hostConfig, config, true)
 	if err != nil {
 		return container.ContainerUpdateOKBody{Warnings: warnings}, validationError{err}
 	}
 
-	if err := daemon.update(name, hostConfig); err != nil {
+	updateWarnings, err := daemon.update(name, hostConfig, config)
+	warnings = append(warnings, updateWarnings...)
+	if err != nil {
 		return container.ContainerUpdateOKBody{Warnings: warnings}, err
 	}
 
 	return container.ContainerUpdateOKBody{Warnings: warnings}, nil
 }
 
-func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) error {
+func (daemon *Daemon) update(name string, hostConfig *container.HostConfig, config *container.Config) ([]string, error) {
 	if hostConfig == nil {
-		return nil
+		return nil, nil
 	}
 
 	container, err := daemon.GetContainer(name)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	restoreConfig := false
 	backupHostConfig := *container.HostConfig
+	backupConfig := *container.Config
 	defer func() {
 		if restoreConfig {
 			container.Lock()
 			container.HostConfig = &backupHostConfig
+			container.Config = &backupConfig
 			container.CheckpointTo(daemon.containersReplica)
 			container.Unlock()
 		}
 	}()
 
 	if container.RemovalInProgress || container.Dead {
-		return errCannotUpdate(container.ID, fmt.Errorf("container is marked for removal and cannot be \"update\""))
+		return nil, errCannotUpdate(container.ID, fmt.Errorf("container is marked for removal and cannot be \"update\""))
 	}
 
+	var warnings []string
+
 	container.Lock()
 	if err := container.UpdateContainer(hostConfig); err != nil {
 		restoreConfig = true
 		container.Unlock()
-		return errCannotUpdate(container.ID, err)
+		return nil, errCannotUpdate(container.ID, err)
+	}
+	logOptsChanged := hostConfig.LogConfig.Config != nil
+	if logOptsChanged {
+		logConfig := mergeLogOptions(container.HostConfig.LogConfig, hostConfig.LogConfig.Config)
+		if err := logger.ValidateLogOpts(logConfig.Type, logConfig.Config); err != nil {
+			restoreConfig = true
+			container.Unlock()
+			return nil, errCannotUpdate(container.ID, validationError{err})
+		}
+		container.HostConfig.LogConfig = logConfig
+	}
+	healthcheckChanged := config != nil && config.Healthcheck != nil
+	if config != nil {
+		if config.Labels != nil {
+			container.Config.Labels = config.Labels
+		}
+		if healthcheckChanged {
+			container.Config.Healthcheck = config.Healthcheck
+		}
 	}
 	if err := container.CheckpointTo(daemon.containersReplica); err != nil {
 		restoreConfig = true
 		container.Unlock()
-		return errCannotUpdate(container.ID, err)
+		return warnings, errCannotUpdate(container.ID, err)
 	}
 	container.Unlock()
 
@@ -79,13 +112,70 @@ func (daemon *Daemon) update(name string, hostConfig *container.HostConfig) erro
 		if err := daemon.containerd.UpdateResources(container.ID, toContainerdResources(hostConfig.Resources)); err != nil {
 			restoreConfig = true
 			// TODO: it would be nice if containerd responded with better errors here so we can classify this better.
-			return errCannotUpdate(container.ID, systemError{err})
+			return warnings, errCannotUpdate(container.ID, systemError{err})
+		}
+	}
+
+	// The health monitor and the log driver of a running container are only
+	// updated once the update can no longer be rolled back, so that they
+	// always match the saved configuration.
+	if healthcheckChanged || logOptsChanged {
+		container.Lock()
+		if container.Running {
+			if healthcheckChanged {
+				daemon.resetHealthMonitor(container)
+			}
+			if logOptsChanged {
+				if warning := updateLogDriver(container.LogDriver, container.HostConfig.LogConfig.Config); warning != "" {
+					warnings = append(warnings, warning)
+				}
+			}
 		}
+		container.Unlock()
 	}
 
 	daemon.LogContainerEvent(container, "update")
 
-	return nil
+	return warnings, nil
+}
+
+// mergeLogOptions returns a copy of cfg with opts merged into its options.
+// Options with an empty value in opts are removed.
+func mergeLogOptions(cfg container.LogConfig, opts map[string]string) container.LogConfig {
+	merged := make(map[string]string, len(cfg.Config)+len(opts))
+	for k, v := range cfg.Config {
+		merged[k] = v
+	}
+	for k, v := range opts {
+		if v == "" {
+			delete(merged, k)
+			continue
+		}
+		merged[k] = v
+	}
+	cfg.Config = merged
+	return cfg
+}
+
+// updateLogDriver applies the log options cfg to the running log driver l.
+// If the driver can't be updated, the options take effect the next time the
+// container starts, and a warning saying so is returned.
+func updateLogDriver(l logger.Logger, cfg map[string]string) string {
+	if l == nil {
+		return ""
+	}
+	var err error = logger.ErrUpdateConfigNotSupported{}
+	if u, ok := l.(logger.ConfigUpdater); ok {
+		err = u.UpdateConfig(cfg)
+	}
+	switch err.(type) {
+	case nil:
+		return ""
+	case logger.ErrUpdateConfigNotSupported:
+		return fmt.Sprintf("Log driver %s does not support updating the options of a running container, they will be applied when the container is restarted", l.Name())
+	default:
+		return fmt.Sprintf("Log options could not be applied to the running container, they will be applied when the container is restarted: %v", err)
+	}
 }
 
 func errCannotUpdate(containerID string, err error) error {
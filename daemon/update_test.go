@@ -0,0 +1,143 @@
+package daemon
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/docker/docker/api/types"
+	containertypes "github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/container"
+	"github.com/docker/docker/daemon/events"
+)
+
+func TestMergeLogOptions(t *testing.T) {
+	cfg := containertypes.LogConfig{
+		Type:   "json-file",
+		Config: map[string]string{"max-size": "10m", "labels": "a"},
+	}
+	merged := mergeLogOptions(cfg, map[string]string{"max-size": "1m", "max-file": "3", "labels": ""})
+	if merged.Type != "json-file" {
+		t.Fatalf("expected the log driver to be kept, got %q", merged.Type)
+	}
+	if len(merged.Config) != 2 || merged.Config["max-size"] != "1m" || merged.Config["max-file"] != "3" {
+		t.Fatalf("unexpected merged log options: %v", merged.Config)
+	}
+	if cfg.Config["max-size"] != "10m" || cfg.Config["labels"] != "a" {
+		t.Fatalf("expected the original log options to be left unchanged, got %v", cfg.Config)
+	}
+}
+
+func TestUpdateLabelsHealthcheckAndLogOptions(t *testing.T) {
+	root, err := ioutil.TempDir("", "docker-update-")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(root)
+
+	store, err := container.NewViewDB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := &container.Container{
+		ID:   "update_container",
+		Name: "/update_container",
+		Root: root,
+		Config: &containertypes.Config{
+			Labels: map[string]string{"old": "label"},
+		},
+		HostConfig: &containertypes.HostConfig{
+			LogConfig: containertypes.LogConfig{Type: "json-file"},
+		},
+		State: container.NewState(),
+	}
+	d := &Daemon{
+		containers:        container.NewMemoryStore(),
+		containersReplica: store,
+		EventsService:     events.New(),
+	}
+	d.containers.Add(c.ID, c)
+
+	hostConfig := &containertypes.HostConfig{
+		LogConfig: containertypes.LogConfig{Config: map[string]string{"max-size": "1m"}},
+	}
+	config := &containertypes.Config{
+		Labels:      map[string]string{"new": "label"},
+		Healthcheck: &containertypes.HealthConfig{Test: []string{"CMD", "true"}},
+	}
+	if _, err := d.update(c.ID, hostConfig, config); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Config.Labels) != 1 || c.Config.Labels["new"] != "label" {
+		t.Fatalf("expected the labels to be replaced, got %v", c.Config.Labels)
+	}
+	if c.Config.Healthcheck == nil || c.Config.Healthcheck.Test[1] != "true" {
+		t.Fatalf("expected the healthcheck to be updated, got %v", c.Config.Healthcheck)
+	}
+	if c.HostConfig.LogConfig.Config["max-size"] != "1m" {
+		t.Fatalf("expected the log options to be updated, got %v", c.HostConfig.LogConfig.Config)
+	}
+
+	snapshot, err := store.Snapshot().Get(c.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Labels["new"] != "label" {
+		t.Fatalf("expected the update to be saved in the view, got labels %v", snapshot.Labels)
+	}
+
+	loaded := &container.Container{Root: root}
+	if err := loaded.FromDisk(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Config.Labels["new"] != "label" || loaded.Config.Healthcheck == nil {
+		t.Fatalf("expected the update to be saved on disk, got %+v", loaded.Config)
+	}
+
+	hostConfig = &containertypes.HostConfig{
+		LogConfig: containertypes.LogConfig{Config: map[string]string{"unknown-option": "value"}},
+	}
+	if _, err := d.update(c.ID, hostConfig, nil); err == nil {
+		t.Fatal("expected an error for an invalid log option")
+	}
+	if _, ok := c.HostConfig.LogConfig.Config["unknown-option"]; ok {
+		t.Fatalf("expected the log options to be restored, got %v", c.HostConfig.LogConfig.Config)
+	}
+
+	// An empty labels object, as sent by the client, removes the labels.
+	var updateConfig containertypes.UpdateConfig
+	body, err := json.Marshal(containertypes.UpdateConfig{Labels: map[string]string{}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(body, &updateConfig); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := d.update(c.ID, &containertypes.HostConfig{}, &containertypes.Config{Labels: updateConfig.Labels}); err != nil {
+		t.Fatal(err)
+	}
+	if c.Config.Labels == nil || len(c.Config.Labels) != 0 {
+		t.Fatalf("expected the labels to be removed, got %v", c.Config.Labels)
+	}
+}
+
+func TestResetHealthMonitorDisabled(t *testing.T) {
+	c := &container.Container{
+		ID: "container_id",
+		Config: &containertypes.Config{
+			Healthcheck: &containertypes.HealthConfig{Test: []string{"NONE"}},
+		},
+		State: container.NewState(),
+	}
+	c.State.Running = true
+	c.State.Health = &container.Health{}
+	c.State.Health.Status = types.Healthy
+
+	d := &Daemon{}
+	d.resetHealthMonitor(c)
+	if c.State.Health != nil {
+		t.Fatalf("expected the health state to be removed, got %v", c.State.Health)
+	}
+}
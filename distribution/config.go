@@ -62,6 +62,20 @@ type ImagePullConfig struct {
 	// Platform is the requested platform of the image being pulled to ensure it can be validated
 	// when the host platform supports multiple image operating systems.
 	Platform string
+	// DownloadChunkSize is the size of the chunks in which the layers
+	// larger than it are downloaded, with concurrent range requests.
+	DownloadChunkSize int64
+	// MaxConcurrentDownloadChunks is the maximum number of chunks of a
+	// layer which are downloaded concurrently. Layers are downloaded with a
+	// single request if it is less than 2.
+	MaxConcurrentDownloadChunks int
+	// SignaturePolicy, if set, requires the images of some repositories to
+	// be signed before they are tagged.
+	SignaturePolicy SignaturePolicy
+	// LazyPull receives the image configuration before the layers are
+	// downloaded, so that a DownloadManager with lazy pulls enabled can
+	// register them as remote-backed. This is experimental.
+	LazyPull bool
 }
 
 // ImagePushConfig stores push configuration.
@@ -71,6 +85,12 @@ type ImagePushConfig struct {
 	// ConfigMediaType is the configuration media type for
 	// schema2 manifests.
 	ConfigMediaType string
+	// OCIManifest pushes OCI image manifests instead of schema2
+	// manifests. There is no fallback to schema1 manifests.
+	OCIManifest bool
+	// ZstdCompression uploads the layers compressed with zstd instead of
+	// gzip.
+	ZstdCompression bool
 	// LayerStore manages layers.
 	LayerStore PushLayerProvider
 	// TrustKey is the private key for legacy signatures. This is typically
@@ -13,6 +13,7 @@ import (
 	"github.com/docker/distribution/registry/client"
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/docker/distribution/xfer"
+	"github.com/opencontainers/go-digest"
 	"github.com/sirupsen/logrus"
 )
 
@@ -119,6 +120,8 @@ func TranslatePullError(err error, ref reference.Named) error {
 		}
 	case xfer.DoNotRetry:
 		return TranslatePullError(v.Err, ref)
+	case signatureError:
+		return v
 	}
 
 	return unknownError{err}
@@ -141,6 +144,8 @@ func continueOnError(err error) bool {
 		return true
 	case ImageConfigPullError:
 		return false
+	case signatureError:
+		return false
 	case error:
 		return !strings.Contains(err.Error(), strings.ToLower(syscall.ESRCH.Error()))
 	}
@@ -213,3 +218,17 @@ func (e reservedNameError) Error() string {
 }
 
 func (e reservedNameError) Forbidden() {}
+
+// signatureError is returned when the signature of a manifest pulled from a
+// repository which requires signed images cannot be verified.
+type signatureError struct {
+	name   reference.Named
+	digest digest.Digest
+	err    error
+}
+
+func (e signatureError) Error() string {
+	return fmt.Sprintf("signature verification failed for %s@%s: %v", e.name.Name(), e.digest, e.err)
+}
+
+func (e signatureError) Forbidden() {}
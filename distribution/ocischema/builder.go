@@ -0,0 +1,136 @@
+package ocischema
+
+import (
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// MediaTypeImageLayerZstd is the media type of zstd compressed layers
+	// in OCI image manifests.
+	MediaTypeImageLayerZstd = "application/vnd.oci.image.layer.v1.tar+zstd"
+
+	// MediaTypeImageLayerNonDistributableZstd is the media type of zstd
+	// compressed non-distributable layers in OCI image manifests.
+	MediaTypeImageLayerNonDistributableZstd = "application/vnd.oci.image.layer.nondistributable.v1.tar+zstd"
+
+	// MediaTypeLayerZstd is the media type of zstd compressed layers in
+	// schema2 manifests.
+	MediaTypeLayerZstd = "application/vnd.docker.image.rootfs.diff.tar.zstd"
+)
+
+// builder is a type for constructing OCI image manifests.
+type builder struct {
+	// bs is a BlobService used to publish the configuration blob.
+	bs distribution.BlobService
+
+	// configJSON references
+	configJSON []byte
+
+	// annotations of the manifest
+	annotations map[string]string
+
+	// layers is a list of descriptors that gets built by successive
+	// calls to AppendReference.
+	layers []distribution.Descriptor
+}
+
+// NewManifestBuilder is used to build new OCI image manifests. It takes a
+// BlobService so it can publish the configuration blob as part of the
+// Build process.
+func NewManifestBuilder(bs distribution.BlobService, configJSON []byte, annotations map[string]string) distribution.ManifestBuilder {
+	mb := &builder{
+		bs:          bs,
+		configJSON:  make([]byte, len(configJSON)),
+		annotations: annotations,
+	}
+	copy(mb.configJSON, configJSON)
+
+	return mb
+}
+
+// Build produces a final manifest from the given references.
+func (mb *builder) Build(ctx context.Context) (distribution.Manifest, error) {
+	m := Manifest{
+		Versioned:   SchemaVersion,
+		Layers:      make([]distribution.Descriptor, len(mb.layers)),
+		Annotations: mb.annotations,
+	}
+	copy(m.Layers, mb.layers)
+
+	configDigest := digest.FromBytes(mb.configJSON)
+
+	var err error
+	m.Config, err = mb.bs.Stat(ctx, configDigest)
+	switch err {
+	case nil:
+		// Override MediaType, since Put always replaces the specified media
+		// type with application/octet-stream in the descriptor it returns.
+		m.Config.MediaType = v1.MediaTypeImageConfig
+		return FromStruct(m)
+	case distribution.ErrBlobUnknown:
+		// nop
+	default:
+		return nil, err
+	}
+
+	// Add config to the blob store
+	m.Config, err = mb.bs.Put(ctx, v1.MediaTypeImageConfig, mb.configJSON)
+	// Override MediaType, since Put always replaces the specified media
+	// type with application/octet-stream in the descriptor it returns.
+	m.Config.MediaType = v1.MediaTypeImageConfig
+	if err != nil {
+		return nil, err
+	}
+
+	return FromStruct(m)
+}
+
+// AppendReference adds a reference to the current ManifestBuilder. The
+// schema2 media type of the layer is replaced with its OCI equivalent.
+func (mb *builder) AppendReference(d distribution.Describable) error {
+	desc := d.Descriptor()
+	desc.MediaType = LayerMediaType(desc.MediaType)
+	mb.layers = append(mb.layers, desc)
+	return nil
+}
+
+// References returns the current references added to this builder.
+func (mb *builder) References() []distribution.Descriptor {
+	return mb.layers
+}
+
+// LayerMediaType returns the OCI media type of the layers with the given
+// schema2 media type. Other media types are returned unchanged.
+func LayerMediaType(schema2MediaType string) string {
+	switch schema2MediaType {
+	case schema2.MediaTypeLayer:
+		return v1.MediaTypeImageLayerGzip
+	case schema2.MediaTypeForeignLayer:
+		return v1.MediaTypeImageLayerNonDistributableGzip
+	case schema2.MediaTypeUncompressedLayer:
+		return v1.MediaTypeImageLayer
+	case MediaTypeLayerZstd:
+		return MediaTypeImageLayerZstd
+	}
+	return schema2MediaType
+}
+
+// Schema2LayerMediaType returns the schema2 media type of the layers with
+// the given OCI media type. Other media types are returned unchanged.
+func Schema2LayerMediaType(ociMediaType string) string {
+	switch ociMediaType {
+	case v1.MediaTypeImageLayerGzip:
+		return schema2.MediaTypeLayer
+	case v1.MediaTypeImageLayerNonDistributableGzip, v1.MediaTypeImageLayerNonDistributable:
+		return schema2.MediaTypeForeignLayer
+	case v1.MediaTypeImageLayer:
+		return schema2.MediaTypeUncompressedLayer
+	case MediaTypeImageLayerZstd:
+		return MediaTypeLayerZstd
+	}
+	return ociMediaType
+}
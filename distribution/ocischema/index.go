@@ -0,0 +1,109 @@
+package ocischema
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// IndexSchemaVersion provides a pre-initialized version structure for OCI
+// image indexes.
+var IndexSchemaVersion = manifest.Versioned{
+	SchemaVersion: 2,
+	MediaType:     v1.MediaTypeImageIndex,
+}
+
+// ImageIndex defines an OCI image index. It references manifests for
+// various platforms, like a manifest list. Its entries are read as the
+// entries of a manifest list: an entry without a platform has an empty
+// platform.
+type ImageIndex struct {
+	manifest.Versioned
+
+	// Manifests references the platform-specific manifests.
+	Manifests []manifestlist.ManifestDescriptor `json:"manifests"`
+
+	// Annotations contains arbitrary metadata for the image index.
+	Annotations map[string]string `json:"annotations,omitempty"`
+}
+
+// References returns the distribution descriptors for the referenced image
+// manifests.
+func (m ImageIndex) References() []distribution.Descriptor {
+	dependencies := make([]distribution.Descriptor, len(m.Manifests))
+	for i := range m.Manifests {
+		dependencies[i] = m.Manifests[i].Descriptor
+	}
+	return dependencies
+}
+
+// DeserializedImageIndex wraps ImageIndex with a copy of the original JSON.
+// It satisfies the distribution.Manifest interface.
+type DeserializedImageIndex struct {
+	ImageIndex
+
+	// canonical is the canonical byte representation of the ImageIndex.
+	canonical []byte
+}
+
+// FromDescriptors takes a slice of descriptors and annotations, and returns
+// a DeserializedImageIndex which contains the resulting image index and its
+// JSON representation.
+func FromDescriptors(descriptors []manifestlist.ManifestDescriptor, annotations map[string]string) (*DeserializedImageIndex, error) {
+	m := ImageIndex{
+		Versioned:   IndexSchemaVersion,
+		Manifests:   make([]manifestlist.ManifestDescriptor, len(descriptors)),
+		Annotations: annotations,
+	}
+	copy(m.Manifests, descriptors)
+
+	deserialized := DeserializedImageIndex{
+		ImageIndex: m,
+	}
+
+	var err error
+	deserialized.canonical, err = json.MarshalIndent(&m, "", "   ")
+	return &deserialized, err
+}
+
+// UnmarshalJSON populates a new ImageIndex struct from JSON data.
+func (m *DeserializedImageIndex) UnmarshalJSON(b []byte) error {
+	m.canonical = make([]byte, len(b))
+	// store the index in canonical
+	copy(m.canonical, b)
+
+	// Unmarshal canonical JSON into an ImageIndex object
+	var index ImageIndex
+	if err := json.Unmarshal(m.canonical, &index); err != nil {
+		return err
+	}
+	// The media type is optional in OCI image indexes
+	if index.MediaType != "" && index.MediaType != v1.MediaTypeImageIndex {
+		return fmt.Errorf("if present, mediaType in image index should be '%s' not '%s'", v1.MediaTypeImageIndex, index.MediaType)
+	}
+
+	m.ImageIndex = index
+
+	return nil
+}
+
+// MarshalJSON returns the contents of canonical. If canonical is empty,
+// marshals the inner contents.
+func (m *DeserializedImageIndex) MarshalJSON() ([]byte, error) {
+	if len(m.canonical) > 0 {
+		return m.canonical, nil
+	}
+
+	return nil, errors.New("JSON representation not initialized in DeserializedImageIndex")
+}
+
+// Payload returns the raw content of the image index. The contents can be
+// used to calculate the content identifier.
+func (m DeserializedImageIndex) Payload() (string, []byte, error) {
+	return v1.MediaTypeImageIndex, m.canonical, nil
+}
@@ -0,0 +1,227 @@
+package ocischema
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/context"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const expectedIndex = `{
+   "schemaVersion": 2,
+   "mediaType": "application/vnd.oci.image.index.v1+json",
+   "manifests": [
+      {
+         "mediaType": "application/vnd.oci.image.manifest.v1+json",
+         "size": 985,
+         "digest": "sha256:1a9ec845ee94c202b2d5da74a24f0ed2058318bfa9879fa541efaecba272e86b",
+         "platform": {
+            "architecture": "amd64",
+            "os": "linux"
+         }
+      },
+      {
+         "mediaType": "application/vnd.oci.image.manifest.v1+json",
+         "size": 985,
+         "digest": "sha256:6d5e1d8bb2c2b3b1e3a3ac31ad4e3b3ba76ac7f1bec0cbd1ba31e55e0a5d64a1"
+      }
+   ]
+}`
+
+func TestManifest(t *testing.T) {
+	m := Manifest{
+		Versioned: SchemaVersion,
+		Config: distribution.Descriptor{
+			MediaType: v1.MediaTypeImageConfig,
+			Digest:    "sha256:1a9ec845ee94c202b2d5da74a24f0ed2058318bfa9879fa541efaecba272e86b",
+			Size:      985,
+		},
+		Layers: []distribution.Descriptor{
+			{
+				MediaType: v1.MediaTypeImageLayerGzip,
+				Digest:    "sha256:62d8908bee94c202b2d35224a221aaa2058318bfa9879fa541efaecba272331b",
+				Size:      153263,
+			},
+		},
+		Annotations: map[string]string{"org.opencontainers.image.title": "test"},
+	}
+
+	deserialized, err := FromStruct(m)
+	if err != nil {
+		t.Fatalf("error creating DeserializedManifest: %v", err)
+	}
+	mediaType, canonical, err := deserialized.Payload()
+	if err != nil || mediaType != v1.MediaTypeImageManifest {
+		t.Fatalf("unexpected payload media type %q: %v", mediaType, err)
+	}
+
+	unmarshalled, descriptor, err := distribution.UnmarshalManifest(v1.MediaTypeImageManifest, canonical)
+	if err != nil {
+		t.Fatalf("error unmarshaling manifest: %v", err)
+	}
+	if descriptor.Digest != digest.FromBytes(canonical) || descriptor.MediaType != v1.MediaTypeImageManifest {
+		t.Fatalf("unexpected descriptor %+v", descriptor)
+	}
+	um, ok := unmarshalled.(*DeserializedManifest)
+	if !ok {
+		t.Fatalf("expected a *DeserializedManifest, got %T", unmarshalled)
+	}
+	if !reflect.DeepEqual(um.Manifest, m) {
+		t.Fatalf("manifest does not round trip: %+v", um.Manifest)
+	}
+	if !reflect.DeepEqual(um.Target(), m.Config) {
+		t.Fatalf("unexpected target %+v", um.Target())
+	}
+	if references := um.References(); len(references) != 2 || !reflect.DeepEqual(references[0], m.Config) || references[1].Digest != m.Layers[0].Digest {
+		t.Fatalf("unexpected references %+v", references)
+	}
+
+	if _, _, err := distribution.UnmarshalManifest(v1.MediaTypeImageManifest, bytes.Replace(canonical, []byte(v1.MediaTypeImageManifest), []byte(schema2.MediaTypeManifest), 1)); err == nil {
+		t.Fatal("expected an error for a manifest with a mismatched media type")
+	}
+}
+
+func TestImageIndex(t *testing.T) {
+	unmarshalled, descriptor, err := distribution.UnmarshalManifest(v1.MediaTypeImageIndex, []byte(expectedIndex))
+	if err != nil {
+		t.Fatalf("error unmarshaling image index: %v", err)
+	}
+	if descriptor.MediaType != v1.MediaTypeImageIndex {
+		t.Fatalf("unexpected descriptor media type %q", descriptor.MediaType)
+	}
+	index, ok := unmarshalled.(*DeserializedImageIndex)
+	if !ok {
+		t.Fatalf("expected a *DeserializedImageIndex, got %T", unmarshalled)
+	}
+	if len(index.Manifests) != 2 {
+		t.Fatalf("expected 2 manifests, got %d", len(index.Manifests))
+	}
+	if !reflect.DeepEqual(index.Manifests[0].Platform, manifestlist.PlatformSpec{Architecture: "amd64", OS: "linux"}) {
+		t.Fatalf("unexpected platform %+v", index.Manifests[0].Platform)
+	}
+	if !reflect.DeepEqual(index.Manifests[1].Platform, manifestlist.PlatformSpec{}) {
+		t.Fatalf("expected an empty platform, got %+v", index.Manifests[1].Platform)
+	}
+	if references := index.References(); len(references) != 2 || references[1].Digest != index.Manifests[1].Digest {
+		t.Fatalf("unexpected references %+v", references)
+	}
+	_, payload, err := index.Payload()
+	if err != nil || string(payload) != expectedIndex {
+		t.Fatalf("image index payload is not the original JSON: %v", err)
+	}
+}
+
+type mockBlobService struct {
+	descriptors map[digest.Digest]distribution.Descriptor
+}
+
+func (bs *mockBlobService) Stat(ctx context.Context, dgst digest.Digest) (distribution.Descriptor, error) {
+	if descriptor, ok := bs.descriptors[dgst]; ok {
+		return descriptor, nil
+	}
+	return distribution.Descriptor{}, distribution.ErrBlobUnknown
+}
+
+func (bs *mockBlobService) Get(ctx context.Context, dgst digest.Digest) ([]byte, error) {
+	panic("not implemented")
+}
+
+func (bs *mockBlobService) Open(ctx context.Context, dgst digest.Digest) (distribution.ReadSeekCloser, error) {
+	panic("not implemented")
+}
+
+func (bs *mockBlobService) Put(ctx context.Context, mediaType string, p []byte) (distribution.Descriptor, error) {
+	d := distribution.Descriptor{
+		Digest:    digest.FromBytes(p),
+		Size:      int64(len(p)),
+		MediaType: "application/octet-stream",
+	}
+	bs.descriptors[d.Digest] = d
+	return d, nil
+}
+
+func (bs *mockBlobService) Create(ctx context.Context, options ...distribution.BlobCreateOption) (distribution.BlobWriter, error) {
+	panic("not implemented")
+}
+
+func (bs *mockBlobService) Resume(ctx context.Context, id string) (distribution.BlobWriter, error) {
+	panic("not implemented")
+}
+
+func TestBuilder(t *testing.T) {
+	bs := &mockBlobService{descriptors: make(map[digest.Digest]distribution.Descriptor)}
+	configJSON := []byte(`{"architecture":"amd64","os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`)
+	layers := []distribution.Descriptor{
+		{MediaType: schema2.MediaTypeLayer, Digest: digest.FromString("layer"), Size: 5},
+		{MediaType: schema2.MediaTypeForeignLayer, Digest: digest.FromString("foreign"), Size: 7, URLs: []string{"https://example.com/foreign"}},
+		{MediaType: schema2.MediaTypeUncompressedLayer, Digest: digest.FromString("uncompressed"), Size: 12},
+		{MediaType: MediaTypeLayerZstd, Digest: digest.FromString("zstd"), Size: 4},
+	}
+	annotations := map[string]string{"org.opencontainers.image.title": "test"}
+
+	builder := NewManifestBuilder(bs, configJSON, annotations)
+	for _, d := range layers {
+		if err := builder.AppendReference(d); err != nil {
+			t.Fatal(err)
+		}
+	}
+	built, err := builder.Build(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := built.(*DeserializedManifest).Manifest
+
+	if m.Config.Digest != digest.FromBytes(configJSON) || m.Config.MediaType != v1.MediaTypeImageConfig {
+		t.Fatalf("unexpected config descriptor %+v", m.Config)
+	}
+	if _, ok := bs.descriptors[m.Config.Digest]; !ok {
+		t.Fatal("config was not put in the blob store")
+	}
+	expectedMediaTypes := []string{v1.MediaTypeImageLayerGzip, v1.MediaTypeImageLayerNonDistributableGzip, v1.MediaTypeImageLayer, MediaTypeImageLayerZstd}
+	for i, l := range m.Layers {
+		if l.MediaType != expectedMediaTypes[i] || l.Digest != layers[i].Digest {
+			t.Errorf("unexpected layer %d: %+v", i, l)
+		}
+		if Schema2LayerMediaType(l.MediaType) != layers[i].MediaType {
+			t.Errorf("media type %q does not convert back to %q", l.MediaType, layers[i].MediaType)
+		}
+	}
+	if !reflect.DeepEqual(m.Annotations, annotations) {
+		t.Fatalf("unexpected annotations %v", m.Annotations)
+	}
+}
+
+func TestFromDescriptors(t *testing.T) {
+	descriptors := []manifestlist.ManifestDescriptor{
+		{
+			Descriptor: distribution.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("amd64"), Size: 10},
+			Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			Descriptor: distribution.Descriptor{MediaType: v1.MediaTypeImageManifest, Digest: digest.FromString("arm64"), Size: 10},
+			Platform:   manifestlist.PlatformSpec{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+	}
+	index, err := FromDescriptors(descriptors, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mediaType, payload, err := index.Payload()
+	if err != nil || mediaType != v1.MediaTypeImageIndex {
+		t.Fatalf("unexpected payload media type %q: %v", mediaType, err)
+	}
+
+	unmarshalled, _, err := distribution.UnmarshalManifest(mediaType, payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(unmarshalled.(*DeserializedImageIndex).ImageIndex, index.ImageIndex) {
+		t.Fatalf("image index does not round trip: %+v", unmarshalled)
+	}
+}
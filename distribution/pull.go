@@ -62,7 +62,7 @@ func Pull(ctx context.Context, ref reference.Named, imagePullConfig *ImagePullCo
 		return err
 	}
 
-	endpoints, err := imagePullConfig.RegistryService.LookupPullEndpoints(reference.Domain(repoInfo.Name))
+	endpoints, err := imagePullConfig.RegistryService.LookupPullEndpoints(repoInfo.Name)
 	if err != nil {
 		return err
 	}
@@ -99,6 +99,11 @@ func Pull(ctx context.Context, ref reference.Named, imagePullConfig *ImagePullCo
 			continue
 		}
 
+		if endpoint.Version == registry.APIVersion1 && imagePullConfig.SignaturePolicy != nil && len(imagePullConfig.SignaturePolicy.PublicKeys(repoInfo.Name)) > 0 {
+			logrus.Debugf("Skipping v1 endpoint %s because the images of %s must be signed", endpoint.URL, repoInfo.Name.Name())
+			continue
+		}
+
 		if endpoint.URL.Scheme != "https" {
 			if _, confirmedTLS := confirmedTLSRegistries[endpoint.URL.Host]; confirmedTLS {
 				logrus.Debugf("Skipping non-TLS endpoint %s for host/port that appears to use TLS", endpoint.URL)
@@ -18,6 +18,7 @@ import (
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/distribution/registry/client/transport"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/ocischema"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/image/v1"
@@ -28,6 +29,7 @@ import (
 	"github.com/docker/docker/pkg/system"
 	refstore "github.com/docker/docker/reference"
 	"github.com/docker/docker/registry"
+	"github.com/docker/docker/registry/resumable"
 	digest "github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
 	"github.com/sirupsen/logrus"
@@ -139,6 +141,14 @@ type v2LayerDescriptor struct {
 	tmpFile           *os.File
 	verifier          digest.Verifier
 	src               distribution.Descriptor
+	// chunkSize and maxConcurrentChunks configure the download of large
+	// blobs in chunks, with concurrent range requests.
+	chunkSize           int64
+	maxConcurrentChunks int
+	// chunks is the state of the chunked download of the blob into tmpFile
+	chunks *chunkedDownload
+	// noByteRanges is set if the registry does not support range requests
+	noByteRanges bool
 }
 
 func (ld *v2LayerDescriptor) Key() string {
@@ -159,6 +169,19 @@ func (ld *v2LayerDescriptor) DiffID() (layer.DiffID, error) {
 func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progress.Output) (io.ReadCloser, int64, error) {
 	logrus.Debugf("pulling blob %q", ld.digest)
 
+	if repo, ok := ld.chunkedDownloadRepository(); ok {
+		rc, size, err := ld.downloadChunked(ctx, progressOutput, repo)
+		if err != resumable.ErrNoByteRanges {
+			return rc, size, err
+		}
+		logrus.Debugf("registry does not support range requests, downloading %s with a single request", ld.digest)
+		ld.noByteRanges = true
+		ld.chunks = nil
+		if err := ld.truncateDownloadFile(); err != nil {
+			return nil, 0, xfer.DoNotRetry{Err: err}
+		}
+	}
+
 	var (
 		err    error
 		offset int64
@@ -267,9 +290,21 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 
 	progress.Update(progressOutput, ld.ID(), "Download complete")
 
+	rc, err := ld.handOffDownloadFile()
+	if err != nil {
+		return nil, 0, err
+	}
+	return rc, size, nil
+}
+
+// handOffDownloadFile returns the downloaded temporary file, rewound to its
+// beginning, to hand it off to the download manager.
+func (ld *v2LayerDescriptor) handOffDownloadFile() (io.ReadCloser, error) {
+	tmpFile := ld.tmpFile
+
 	logrus.Debugf("Downloaded %s to tempfile %s", ld.ID(), tmpFile.Name())
 
-	_, err = tmpFile.Seek(0, os.SEEK_SET)
+	_, err := tmpFile.Seek(0, os.SEEK_SET)
 	if err != nil {
 		tmpFile.Close()
 		if err := os.Remove(tmpFile.Name()); err != nil {
@@ -277,12 +312,16 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 		}
 		ld.tmpFile = nil
 		ld.verifier = nil
-		return nil, 0, xfer.DoNotRetry{Err: err}
+		ld.chunks = nil
+		return nil, xfer.DoNotRetry{Err: err}
 	}
 
 	// hand off the temporary file to the download manager, so it will only
-	// be closed once
+	// be closed once. The descriptor may download the blob again to fetch
+	// a lazily pulled layer, so it starts over from a new verifier.
 	ld.tmpFile = nil
+	ld.verifier = nil
+	ld.chunks = nil
 
 	return ioutils.NewReadCloserWrapper(tmpFile, func() error {
 		tmpFile.Close()
@@ -291,7 +330,7 @@ func (ld *v2LayerDescriptor) Download(ctx context.Context, progressOutput progre
 			logrus.Errorf("Failed to remove temp file: %s", tmpFile.Name())
 		}
 		return err
-	}), size, nil
+	}), nil
 }
 
 func (ld *v2LayerDescriptor) Close() {
@@ -300,6 +339,9 @@ func (ld *v2LayerDescriptor) Close() {
 		if err := os.RemoveAll(ld.tmpFile.Name()); err != nil {
 			logrus.Errorf("Failed to remove temp file: %s", ld.tmpFile.Name())
 		}
+		ld.tmpFile = nil
+		ld.verifier = nil
+		ld.chunks = nil
 	}
 }
 
@@ -321,8 +363,14 @@ func (ld *v2LayerDescriptor) truncateDownloadFile() error {
 }
 
 func (ld *v2LayerDescriptor) Registered(diffID layer.DiffID) {
-	// Cache mapping from this layer's DiffID to the blobsum
-	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{Digest: ld.digest, SourceRepository: ld.repoInfo.Name.Name()})
+	// Cache mapping from this layer's DiffID to the blobsum, and to its
+	// compression if it is not gzip, so that it is only reused by pushes
+	// with the same compression.
+	ld.V2MetadataService.Add(diffID, metadata.V2Metadata{
+		Digest:           ld.digest,
+		SourceRepository: ld.repoInfo.Name.Name(),
+		MediaType:        v2MetadataMediaType(ld.src.MediaType),
+	})
 }
 
 func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdated bool, err error) {
@@ -355,20 +403,20 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 		return false, fmt.Errorf("image manifest does not exist for tag or digest %q", tagOrDigest)
 	}
 
-	if m, ok := manifest.(*schema2.DeserializedManifest); ok {
+	if mediaType, ok := configMediaType(manifest); ok {
 		var allowedMediatype bool
 		for _, t := range p.config.Schema2Types {
-			if m.Manifest.Config.MediaType == t {
+			if mediaType == t {
 				allowedMediatype = true
 				break
 			}
 		}
 		if !allowedMediatype {
-			configClass := mediaTypeClasses[m.Manifest.Config.MediaType]
+			configClass := mediaTypeClasses[mediaType]
 			if configClass == "" {
 				configClass = "unknown"
 			}
-			return false, invalidManifestClassError{m.Manifest.Config.MediaType, configClass}
+			return false, invalidManifestClassError{mediaType, configClass}
 		}
 	}
 
@@ -379,6 +427,11 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 	logrus.Debugf("Pulling ref from V2 registry: %s", reference.FamiliarString(ref))
 	progress.Message(p.config.ProgressOutput, tagOrDigest, "Pulling from "+reference.FamiliarName(p.repo.Named()))
 
+	signedDigest, err := p.verifyManifestSignature(ctx, ref, manifest)
+	if err != nil {
+		return false, err
+	}
+
 	var (
 		id             digest.Digest
 		manifestDigest digest.Digest
@@ -398,8 +451,18 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 		if err != nil {
 			return false, err
 		}
+	case *ocischema.DeserializedManifest:
+		id, manifestDigest, err = p.pullOCI(ctx, ref, v)
+		if err != nil {
+			return false, err
+		}
 	case *manifestlist.DeserializedManifestList:
-		id, manifestDigest, err = p.pullManifestList(ctx, ref, v)
+		id, manifestDigest, err = p.pullManifestList(ctx, ref, v, v.Manifests)
+		if err != nil {
+			return false, err
+		}
+	case *ocischema.DeserializedImageIndex:
+		id, manifestDigest, err = p.pullManifestList(ctx, ref, v, v.Manifests)
 		if err != nil {
 			return false, err
 		}
@@ -409,6 +472,10 @@ func (p *v2Puller) pullV2Tag(ctx context.Context, ref reference.Named) (tagUpdat
 
 	progress.Message(p.config.ProgressOutput, "", "Digest: "+manifestDigest.String())
 
+	if signedDigest != "" {
+		p.config.SignaturePolicy.Verified(p.repoInfo.Name, signedDigest, id)
+	}
+
 	if p.config.ReferenceStore != nil {
 		oldTagID, err := p.config.ReferenceStore.Get(ref)
 		if err == nil {
@@ -528,12 +595,29 @@ func (p *v2Puller) pullSchema1(ctx context.Context, ref reference.Reference, unv
 }
 
 func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *schema2.DeserializedManifest) (id digest.Digest, manifestDigest digest.Digest, err error) {
+	return p.pullSchema2Layers(ctx, ref, mfst, mfst.Target(), mfst.Layers)
+}
+
+// pullOCI pulls an image from an OCI image manifest. The OCI media types of
+// its layers are replaced with their schema2 equivalents, so that
+// non-distributable layers are handled as foreign layers.
+func (p *v2Puller) pullOCI(ctx context.Context, ref reference.Named, mfst *ocischema.DeserializedManifest) (id digest.Digest, manifestDigest digest.Digest, err error) {
+	layers := make([]distribution.Descriptor, len(mfst.Layers))
+	for i, d := range mfst.Layers {
+		d.MediaType = ocischema.Schema2LayerMediaType(d.MediaType)
+		layers[i] = d
+	}
+	return p.pullSchema2Layers(ctx, ref, mfst, mfst.Target(), layers)
+}
+
+// pullSchema2Layers pulls the image with the configuration target and the
+// given layers, which are referenced by the manifest mfst.
+func (p *v2Puller) pullSchema2Layers(ctx context.Context, ref reference.Named, mfst distribution.Manifest, target distribution.Descriptor, layers []distribution.Descriptor) (id digest.Digest, manifestDigest digest.Digest, err error) {
 	manifestDigest, err = schema2ManifestDigest(ref, mfst)
 	if err != nil {
 		return "", "", err
 	}
 
-	target := mfst.Target()
 	if _, err := p.config.ImageStore.Get(target.Digest); err == nil {
 		// If the image already exists locally, no need to pull
 		// anything.
@@ -544,13 +628,15 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 
 	// Note that the order of this loop is in the direction of bottom-most
 	// to top-most, so that the downloads slice gets ordered correctly.
-	for _, d := range mfst.Layers {
+	for _, d := range layers {
 		layerDescriptor := &v2LayerDescriptor{
-			digest:            d.Digest,
-			repo:              p.repo,
-			repoInfo:          p.repoInfo,
-			V2MetadataService: p.V2MetadataService,
-			src:               d,
+			digest:              d.Digest,
+			repo:                p.repo,
+			repoInfo:            p.repoInfo,
+			V2MetadataService:   p.V2MetadataService,
+			src:                 d,
+			chunkSize:           p.config.DownloadChunkSize,
+			maxConcurrentChunks: p.config.MaxConcurrentDownloadChunks,
 		}
 
 		descriptors = append(descriptors, layerDescriptor)
@@ -591,7 +677,9 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 	// which aren't suitable for NTFS. At some point in the future, if a similar
 	// check to block Windows images being pulled on Linux is implemented, it
 	// may be necessary to perform the same type of serialisation.
-	if runtime.GOOS == "windows" {
+	// Lazy pulls need the DiffIDs of the layers before their download too,
+	// to register them as remote-backed.
+	if runtime.GOOS == "windows" || p.config.LazyPull {
 		configJSON, configRootFS, platform, err = receiveConfig(p.config.ImageStore, configChan, configErrChan)
 		if err != nil {
 			return "", "", err
@@ -606,7 +694,7 @@ func (p *v2Puller) pullSchema2(ctx context.Context, ref reference.Named, mfst *s
 		}
 
 		// Populate diff ids in descriptors to avoid downloading foreign layers
-		// which have been side loaded
+		// which have been side loaded, and to register lazily pulled layers
 		for i := range descriptors {
 			descriptors[i].(*v2LayerDescriptor).diffID = configRootFS.DiffIDs[i]
 		}
@@ -699,32 +787,21 @@ func receiveConfig(s ImageConfigStore, configChan <-chan []byte, errChan <-chan
 	}
 }
 
-// pullManifestList handles "manifest lists" which point to various
-// platform-specific manifests.
-func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mfstList *manifestlist.DeserializedManifestList) (id digest.Digest, manifestListDigest digest.Digest, err error) {
+// pullManifestList handles "manifest lists" and OCI image indexes, which
+// point to various platform-specific manifests.
+func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mfstList distribution.Manifest, manifests []manifestlist.ManifestDescriptor) (id digest.Digest, manifestListDigest digest.Digest, err error) {
 	manifestListDigest, err = schema2ManifestDigest(ref, mfstList)
 	if err != nil {
 		return "", "", err
 	}
 
-	logrus.Debugf("%s resolved to a manifestList object with %d entries; looking for a os/arch match", ref, len(mfstList.Manifests))
-	var manifestDigest digest.Digest
+	logrus.Debugf("%s resolved to a manifestList object with %d entries; looking for a os/arch match", ref, len(manifests))
 	// TODO @jhowardmsft LCOW Support: Need to remove the hard coding in LCOW mode.
 	lookingForOS := runtime.GOOS
 	if system.LCOWSupported() {
 		lookingForOS = "linux"
 	}
-	for _, manifestDescriptor := range mfstList.Manifests {
-		// TODO(aaronl): The manifest list spec supports optional
-		// "features" and "variant" fields. These are not yet used.
-		// Once they are, their values should be interpreted here.
-		if manifestDescriptor.Platform.Architecture == runtime.GOARCH && manifestDescriptor.Platform.OS == lookingForOS {
-			manifestDigest = manifestDescriptor.Digest
-			logrus.Debugf("found match for %s/%s with media type %s, digest %s", runtime.GOOS, runtime.GOARCH, manifestDescriptor.MediaType, manifestDigest.String())
-			break
-		}
-	}
-
+	manifestDigest := matchManifestDescriptor(manifests, lookingForOS, runtime.GOARCH)
 	if manifestDigest == "" {
 		errMsg := fmt.Sprintf("no matching manifest for %s/%s in the manifest list entries", runtime.GOOS, runtime.GOARCH)
 		logrus.Debugf(errMsg)
@@ -757,6 +834,11 @@ func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mf
 		if err != nil {
 			return "", "", err
 		}
+	case *ocischema.DeserializedManifest:
+		id, _, err = p.pullOCI(ctx, manifestRef, v)
+		if err != nil {
+			return "", "", err
+		}
 	default:
 		return "", "", errors.New("unsupported manifest format")
 	}
@@ -764,6 +846,34 @@ func (p *v2Puller) pullManifestList(ctx context.Context, ref reference.Named, mf
 	return id, manifestListDigest, err
 }
 
+// matchManifestDescriptor returns the digest of the first manifest for the
+// given os and architecture, or an empty digest if there is none. Entries of
+// OCI image indexes which have no platform never match.
+func matchManifestDescriptor(manifests []manifestlist.ManifestDescriptor, os, arch string) digest.Digest {
+	for _, manifestDescriptor := range manifests {
+		// TODO(aaronl): The manifest list spec supports optional
+		// "features" and "variant" fields. These are not yet used.
+		// Once they are, their values should be interpreted here.
+		if manifestDescriptor.Platform.Architecture == arch && manifestDescriptor.Platform.OS == os {
+			logrus.Debugf("found match for %s/%s with media type %s, digest %s", os, arch, manifestDescriptor.MediaType, manifestDescriptor.Digest.String())
+			return manifestDescriptor.Digest
+		}
+	}
+	return ""
+}
+
+// configMediaType returns the media type of the configuration referenced
+// by an image manifest, and false for other kinds of manifests.
+func configMediaType(manifest distribution.Manifest) (string, bool) {
+	switch m := manifest.(type) {
+	case *schema2.DeserializedManifest:
+		return m.Manifest.Config.MediaType, true
+	case *ocischema.DeserializedManifest:
+		return m.Manifest.Config.MediaType, true
+	}
+	return "", false
+}
+
 func (p *v2Puller) pullSchema2Config(ctx context.Context, dgst digest.Digest) (configJSON []byte, err error) {
 	blobs := p.repo.Blobs(ctx)
 	configJSON, err = blobs.Get(ctx, dgst)
@@ -810,6 +920,39 @@ func schema2ManifestDigest(ref reference.Named, mfst distribution.Manifest) (dig
 	return digest.FromBytes(canonical), nil
 }
 
+// verifyManifestSignature checks that manifest, pulled for ref, is signed if
+// the signature policy requires the images of the repository to be signed.
+// It returns the digest of the signed manifest, or an empty digest if no
+// signature is required.
+func (p *v2Puller) verifyManifestSignature(ctx context.Context, ref reference.Named, manifest distribution.Manifest) (digest.Digest, error) {
+	if p.config.SignaturePolicy == nil {
+		return "", nil
+	}
+	keys := p.config.SignaturePolicy.PublicKeys(p.repoInfo.Name)
+	if len(keys) == 0 {
+		return "", nil
+	}
+
+	var (
+		manifestDigest digest.Digest
+		err            error
+	)
+	if m, ok := manifest.(*schema1.SignedManifest); ok {
+		manifestDigest = digest.FromBytes(m.Canonical)
+	} else if manifestDigest, err = schema2ManifestDigest(ref, manifest); err != nil {
+		return "", err
+	}
+
+	if err := verifySignature(ctx, p.repo, manifestDigest, keys); err != nil {
+		err = signatureError{name: p.repoInfo.Name, digest: manifestDigest, err: err}
+		logrus.Error(err)
+		p.config.SignaturePolicy.Rejected(p.repoInfo.Name, manifestDigest, err)
+		return "", err
+	}
+	progress.Message(p.config.ProgressOutput, "", "Signature verified for "+manifestDigest.String())
+	return manifestDigest, nil
+}
+
 // allowV1Fallback checks if the error is a possible reason to fallback to v1
 // (even if confirmedV2 has been set already), and if so, wraps the error in
 // a fallbackError with confirmedV2 set to false. Otherwise, it returns the
@@ -0,0 +1,232 @@
+package distribution
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/docker/docker/distribution/xfer"
+	"github.com/docker/docker/pkg/ioutils"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry/resumable"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+	"golang.org/x/sync/errgroup"
+	"golang.org/x/time/rate"
+)
+
+// maxChunkRequestAttempts is the number of times the request of a chunk is
+// made again after a failure, before failing the download attempt.
+const maxChunkRequestAttempts = 3
+
+// chunkedDownload is the state of the download of a blob in chunks. It is
+// kept across download attempts, so that a failed download is resumed.
+type chunkedDownload struct {
+	sync.Mutex
+	// downloaded maps the offset of each chunk to the number of its bytes
+	// already written to the download file.
+	downloaded map[int64]int64
+}
+
+func (c *chunkedDownload) get(offset int64) int64 {
+	c.Lock()
+	defer c.Unlock()
+	return c.downloaded[offset]
+}
+
+func (c *chunkedDownload) set(offset, n int64) {
+	c.Lock()
+	c.downloaded[offset] = n
+	c.Unlock()
+}
+
+// chunkedDownloadRepository returns the repository to download the blob of
+// the layer from in chunks, if the blob is large enough to be downloaded in
+// chunks.
+func (ld *v2LayerDescriptor) chunkedDownloadRepository() (*v2Repository, bool) {
+	if ld.maxConcurrentChunks < 2 || ld.chunkSize <= 0 || ld.src.Size <= ld.chunkSize || len(ld.src.URLs) > 0 || ld.noByteRanges {
+		return nil, false
+	}
+	// Do not switch a started single request download to chunks
+	if ld.tmpFile != nil && ld.chunks == nil {
+		return nil, false
+	}
+	repo, ok := ld.repo.(*v2Repository)
+	return repo, ok
+}
+
+// chunkLength returns the length of the chunk of the blob at offset.
+func (ld *v2LayerDescriptor) chunkLength(offset int64) int64 {
+	if length := ld.src.Size - offset; length < ld.chunkSize {
+		return length
+	}
+	return ld.chunkSize
+}
+
+// downloadChunked downloads the blob of the layer in chunks of chunkSize
+// bytes, with up to maxConcurrentChunks concurrent range requests, and
+// verifies its digest once all the chunks are downloaded. The bytes
+// downloaded by previous attempts are not downloaded again.
+// resumable.ErrNoByteRanges is returned if the registry does not support
+// range requests.
+func (ld *v2LayerDescriptor) downloadChunked(ctx context.Context, progressOutput progress.Output, repo *v2Repository) (io.ReadCloser, int64, error) {
+	size := ld.src.Size
+	blobURL, err := repo.blobURL(ld.digest)
+	if err != nil {
+		return nil, 0, xfer.DoNotRetry{Err: err}
+	}
+
+	if ld.tmpFile == nil {
+		ld.tmpFile, err = createDownloadFile()
+		if err != nil {
+			return nil, 0, xfer.DoNotRetry{Err: err}
+		}
+		ld.chunks = &chunkedDownload{downloaded: make(map[int64]int64)}
+	}
+
+	var downloaded int64
+	for _, n := range ld.chunks.downloaded {
+		downloaded += n
+	}
+	resumed := downloaded != 0
+	if resumed {
+		logrus.Debugf("attempting to resume download of %q from %d bytes", ld.digest, downloaded)
+	}
+
+	chunkProgress := &chunkProgress{
+		out:         progressOutput,
+		id:          ld.ID(),
+		current:     downloaded,
+		total:       size,
+		rateLimiter: rate.NewLimiter(rate.Every(100*time.Millisecond), 1),
+	}
+	client := &http.Client{Transport: repo.transport}
+
+	g, gctx := errgroup.WithContext(ctx)
+	offsets := make(chan int64)
+	for i := 0; i < ld.maxConcurrentChunks; i++ {
+		g.Go(func() error {
+			for offset := range offsets {
+				if err := ld.downloadChunk(gctx, client, blobURL, offset, chunkProgress); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+feed:
+	for offset := int64(0); offset < size; offset += ld.chunkSize {
+		if ld.chunks.get(offset) == ld.chunkLength(offset) {
+			continue
+		}
+		select {
+		case offsets <- offset:
+		case <-gctx.Done():
+			break feed
+		}
+	}
+	close(offsets)
+	if err := g.Wait(); err != nil {
+		if err == resumable.ErrNoByteRanges {
+			return nil, 0, err
+		}
+		return nil, 0, retryOnError(err)
+	}
+
+	progress.Update(progressOutput, ld.ID(), "Verifying Checksum")
+
+	verifier := ld.digest.Verifier()
+	if _, err := ld.tmpFile.Seek(0, os.SEEK_SET); err != nil {
+		return nil, 0, xfer.DoNotRetry{Err: err}
+	}
+	if _, err := io.Copy(verifier, ld.tmpFile); err != nil {
+		return nil, 0, xfer.DoNotRetry{Err: err}
+	}
+	if !verifier.Verified() {
+		err = fmt.Errorf("filesystem layer verification failed for digest %s", ld.digest)
+		logrus.Error(err)
+
+		ld.chunks = &chunkedDownload{downloaded: make(map[int64]int64)}
+		if err := ld.truncateDownloadFile(); err != nil {
+			return nil, 0, xfer.DoNotRetry{Err: err}
+		}
+		// Allow a retry if this digest verification error happened
+		// after a resumed download.
+		if resumed {
+			return nil, 0, err
+		}
+		return nil, 0, xfer.DoNotRetry{Err: err}
+	}
+
+	progress.Update(progressOutput, ld.ID(), "Download complete")
+
+	rc, err := ld.handOffDownloadFile()
+	if err != nil {
+		return nil, 0, err
+	}
+	return rc, size, nil
+}
+
+// downloadChunk downloads the missing bytes of the chunk at offset into the
+// download file.
+func (ld *v2LayerDescriptor) downloadChunk(ctx context.Context, client *http.Client, blobURL string, offset int64, chunkProgress io.Writer) error {
+	done := ld.chunks.get(offset)
+	remaining := ld.chunkLength(offset) - done
+
+	req, err := http.NewRequest("GET", blobURL, nil)
+	if err != nil {
+		return err
+	}
+	body := ioutils.NewCancelReadCloser(ctx, resumable.NewRangeRequestReader(client, req.WithContext(ctx), maxChunkRequestAttempts, offset+done, remaining))
+	defer body.Close()
+
+	w := &chunkWriter{file: ld.tmpFile, chunks: ld.chunks, offset: offset, done: done}
+	n, err := io.Copy(w, io.TeeReader(io.LimitReader(body, remaining), chunkProgress))
+	if err != nil {
+		return err
+	}
+	if n != remaining {
+		return io.ErrUnexpectedEOF
+	}
+	return nil
+}
+
+// chunkWriter writes a chunk of a blob at its offset in the download file,
+// recording the number of bytes of the chunk which are written.
+type chunkWriter struct {
+	file   *os.File
+	chunks *chunkedDownload
+	offset int64
+	done   int64
+}
+
+func (w *chunkWriter) Write(p []byte) (int, error) {
+	n, err := w.file.WriteAt(p, w.offset+w.done)
+	w.done += int64(n)
+	w.chunks.set(w.offset, w.done)
+	return n, err
+}
+
+// chunkProgress reports the progress of the concurrent downloads of the
+// chunks of a blob.
+type chunkProgress struct {
+	sync.Mutex
+	out         progress.Output
+	id          string
+	current     int64
+	total       int64
+	rateLimiter *rate.Limiter
+}
+
+func (p *chunkProgress) Write(b []byte) (int, error) {
+	p.Lock()
+	defer p.Unlock()
+	p.current += int64(len(b))
+	if p.current == p.total || p.rateLimiter.Allow() {
+		p.out.WriteProgress(progress.Progress{ID: p.id, Action: "Downloading", Current: p.current, Total: p.total})
+	}
+	return len(b), nil
+}
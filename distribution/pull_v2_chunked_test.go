@@ -0,0 +1,189 @@
+package distribution
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	"golang.org/x/net/context"
+)
+
+// blobRangeHandler serves a single blob, with support for range requests
+// unless noRanges is set. If failOffset is set, the first request of the
+// range at failOffset fails once the other chunks have been served.
+type blobRangeHandler struct {
+	sync.Mutex
+	blob       []byte
+	noRanges   bool
+	failOffset int64
+	failed     bool
+	served     int
+	ranges     []string
+}
+
+func (h *blobRangeHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if r.URL.Path == "/v2/" {
+		return
+	}
+	if !strings.Contains(r.URL.Path, "/blobs/") {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	rangeHeader := r.Header.Get("Range")
+	h.Lock()
+	h.ranges = append(h.ranges, rangeHeader)
+	fail := !h.failed && h.failOffset != 0 && strings.HasPrefix(rangeHeader, fmt.Sprintf("bytes=%d-", h.failOffset))
+	h.Unlock()
+
+	if fail {
+		// Wait for the other chunks to be served before failing
+		for i := 0; i < 100; i++ {
+			h.Lock()
+			served := h.served
+			h.Unlock()
+			if int64(served) == h.failOffset/100 {
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		h.Lock()
+		h.failed = true
+		h.Unlock()
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(h.blob).String())
+	if h.noRanges {
+		r.Header.Del("Range")
+	}
+	http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(h.blob))
+	h.Lock()
+	h.served++
+	h.Unlock()
+}
+
+func (h *blobRangeHandler) reset() []string {
+	h.Lock()
+	defer h.Unlock()
+	ranges := h.ranges
+	h.ranges = nil
+	return ranges
+}
+
+func newChunkedLayerDescriptor(t *testing.T, handler http.Handler) (*v2LayerDescriptor, func()) {
+	ts := httptest.NewServer(handler)
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := reference.ParseNormalizedNamed("testremotename")
+	repoInfo := &registry.RepositoryInfo{
+		Name:  n,
+		Index: &registrytypes.IndexInfo{Name: "testrepo"},
+	}
+	repo, _, err := NewV2Repository(context.Background(), repoInfo, registry.APIEndpoint{URL: uri, Version: registry.APIVersion2}, http.Header{}, &types.AuthConfig{}, "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &v2LayerDescriptor{
+		repo:                repo,
+		repoInfo:            repoInfo,
+		V2MetadataService:   metadata.NewV2MetadataService(nil),
+		chunkSize:           100,
+		maxConcurrentChunks: 2,
+	}, ts.Close
+}
+
+func checkDownloadedBlob(t *testing.T, ld *v2LayerDescriptor, blob []byte) {
+	rc, size, err := ld.Download(context.Background(), progress.DiscardOutput())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rc.Close()
+	b, err := ioutil.ReadAll(rc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size != int64(len(blob)) || !bytes.Equal(b, blob) {
+		t.Fatalf("downloaded %d bytes of size %d, expected the %d bytes of the blob", len(b), size, len(blob))
+	}
+}
+
+func TestDownloadChunked(t *testing.T) {
+	blob := make([]byte, 550)
+	rand.Read(blob)
+	handler := &blobRangeHandler{blob: blob}
+	ld, cleanup := newChunkedLayerDescriptor(t, handler)
+	defer cleanup()
+	ld.digest = digest.FromBytes(blob)
+	ld.src = distribution.Descriptor{Digest: ld.digest, Size: int64(len(blob))}
+
+	checkDownloadedBlob(t, ld, blob)
+	ranges := handler.reset()
+	if len(ranges) != 6 {
+		t.Fatalf("expected a request for each of the 6 chunks, got %v", ranges)
+	}
+	for _, r := range ranges {
+		if !strings.HasPrefix(r, "bytes=") {
+			t.Fatalf("expected range requests, got %v", ranges)
+		}
+	}
+}
+
+func TestDownloadChunkedResume(t *testing.T) {
+	blob := make([]byte, 500)
+	rand.Read(blob)
+	handler := &blobRangeHandler{blob: blob, failOffset: 400}
+	ld, cleanup := newChunkedLayerDescriptor(t, handler)
+	defer cleanup()
+	ld.digest = digest.FromBytes(blob)
+	ld.src = distribution.Descriptor{Digest: ld.digest, Size: int64(len(blob))}
+	defer ld.Close()
+
+	if _, _, err := ld.Download(context.Background(), progress.DiscardOutput()); err == nil {
+		t.Fatal("expected the download of the last chunk to fail")
+	}
+	handler.reset()
+
+	checkDownloadedBlob(t, ld, blob)
+	for _, r := range handler.reset() {
+		var start int64
+		if _, err := fmt.Sscanf(r, "bytes=%d-", &start); err != nil || start < 300 {
+			t.Fatalf("expected only the last chunks to be downloaded again, got range %q", r)
+		}
+	}
+}
+
+func TestDownloadChunkedNoByteRanges(t *testing.T) {
+	blob := make([]byte, 250)
+	rand.Read(blob)
+	handler := &blobRangeHandler{blob: blob, noRanges: true}
+	ld, cleanup := newChunkedLayerDescriptor(t, handler)
+	defer cleanup()
+	ld.digest = digest.FromBytes(blob)
+	ld.src = distribution.Descriptor{Digest: ld.digest, Size: int64(len(blob))}
+
+	checkDownloadedBlob(t, ld, blob)
+	if !ld.noByteRanges {
+		t.Fatal("expected the download to fall back to a single request")
+	}
+}
@@ -2,16 +2,33 @@ package distribution
 
 import (
 	"encoding/json"
+	"fmt"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
 	"reflect"
 	"runtime"
+	"strconv"
 	"strings"
 	"testing"
 
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/manifest/manifestlist"
 	"github.com/docker/distribution/manifest/schema1"
 	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/distribution/ocischema"
+	"github.com/docker/docker/image"
 	"github.com/docker/docker/internal/testutil"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry"
 	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
 )
 
 // TestFixManifestLayers checks that fixManifestLayers removes a duplicate
@@ -181,3 +198,168 @@ func TestValidateManifest(t *testing.T) {
 		t.Fatal("expected validateManifest to fail with digest error")
 	}
 }
+
+func TestMatchManifestDescriptor(t *testing.T) {
+	manifests := []manifestlist.ManifestDescriptor{
+		{Descriptor: distribution.Descriptor{Digest: digest.FromString("no platform")}},
+		{Descriptor: distribution.Descriptor{Digest: digest.FromString("windows")}, Platform: manifestlist.PlatformSpec{OS: "windows", Architecture: "amd64"}},
+		{Descriptor: distribution.Descriptor{Digest: digest.FromString("linux")}, Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"}},
+		{Descriptor: distribution.Descriptor{Digest: digest.FromString("linux too")}, Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"}},
+	}
+
+	if dgst := matchManifestDescriptor(manifests, "linux", "amd64"); dgst != digest.FromString("linux") {
+		t.Fatalf("expected the first linux/amd64 manifest, got %s", dgst)
+	}
+	if dgst := matchManifestDescriptor(manifests, "windows", "amd64"); dgst != digest.FromString("windows") {
+		t.Fatalf("expected the windows/amd64 manifest, got %s", dgst)
+	}
+	if dgst := matchManifestDescriptor(manifests, "linux", "arm64"); dgst != "" {
+		t.Fatalf("expected no manifest for linux/arm64, got %s", dgst)
+	}
+}
+
+type mockImageConfigStore struct {
+	configs map[digest.Digest][]byte
+}
+
+func (s *mockImageConfigStore) Put(config []byte) (digest.Digest, error) {
+	dgst := digest.FromBytes(config)
+	s.configs[dgst] = config
+	return dgst, nil
+}
+
+func (s *mockImageConfigStore) Get(dgst digest.Digest) ([]byte, error) {
+	if config, ok := s.configs[dgst]; ok {
+		return config, nil
+	}
+	return nil, fmt.Errorf("no config %s", dgst)
+}
+
+func (s *mockImageConfigStore) RootFSAndPlatformFromConfig(config []byte) (*image.RootFS, layer.Platform, error) {
+	return image.NewRootFS(), layer.Platform(runtime.GOOS), nil
+}
+
+// ociRegistryHandler serves the OCI image index of a single tag, whose
+// manifests have no layers.
+type ociRegistryHandler struct {
+	blobs     map[digest.Digest][]byte
+	manifests map[string][]byte
+}
+
+func (h *ociRegistryHandler) addManifest(reference string, mediaType string, v interface{}) distribution.Descriptor {
+	b, err := json.Marshal(v)
+	if err != nil {
+		panic(err)
+	}
+	d := distribution.Descriptor{MediaType: mediaType, Digest: digest.FromBytes(b), Size: int64(len(b))}
+	h.manifests[reference] = b
+	h.manifests[d.Digest.String()] = b
+	return d
+}
+
+func (h *ociRegistryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	if r.URL.Path == "/v2/" {
+		return
+	}
+	var (
+		content   []byte
+		mediaType = "application/octet-stream"
+	)
+	switch i := strings.LastIndex(r.URL.Path, "/"); {
+	case strings.HasSuffix(r.URL.Path[:i], "/manifests"):
+		content = h.manifests[r.URL.Path[i+1:]]
+		var versioned manifest.Versioned
+		if err := json.Unmarshal(content, &versioned); err == nil {
+			mediaType = versioned.MediaType
+		}
+	case strings.HasSuffix(r.URL.Path[:i], "/blobs"):
+		content = h.blobs[digest.Digest(r.URL.Path[i+1:])]
+	}
+	if content == nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", mediaType)
+	w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+	w.Header().Set("Docker-Content-Digest", digest.FromBytes(content).String())
+	if r.Method != "HEAD" {
+		w.Write(content)
+	}
+}
+
+func TestPullOCIImageIndex(t *testing.T) {
+	handler := &ociRegistryHandler{
+		blobs:     make(map[digest.Digest][]byte),
+		manifests: make(map[string][]byte),
+	}
+	addImage := func(os string) (distribution.Descriptor, digest.Digest) {
+		config := []byte(fmt.Sprintf(`{"architecture":%q,"os":%q,"rootfs":{"type":"layers","diff_ids":[]}}`, runtime.GOARCH, os))
+		configDigest := digest.FromBytes(config)
+		handler.blobs[configDigest] = config
+		d := handler.addManifest(os, ocispec.MediaTypeImageManifest, ocischema.Manifest{
+			Versioned: ocischema.SchemaVersion,
+			Config:    distribution.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: configDigest, Size: int64(len(config))},
+			Layers:    []distribution.Descriptor{},
+		})
+		return d, configDigest
+	}
+	other, _ := addImage("other")
+	native, nativeConfig := addImage(runtime.GOOS)
+	index := handler.addManifest("latest", ocispec.MediaTypeImageIndex, ocischema.ImageIndex{
+		Versioned: manifest.Versioned{SchemaVersion: 2, MediaType: ocispec.MediaTypeImageIndex},
+		Manifests: []manifestlist.ManifestDescriptor{
+			{Descriptor: other, Platform: manifestlist.PlatformSpec{OS: "other", Architecture: runtime.GOARCH}},
+			{Descriptor: native, Platform: manifestlist.PlatformSpec{OS: runtime.GOOS, Architecture: runtime.GOARCH}},
+		},
+	})
+
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := reference.ParseNormalizedNamed("testremotename")
+	repoInfo := &registry.RepositoryInfo{
+		Name:  n,
+		Index: &registrytypes.IndexInfo{Name: "testrepo"},
+	}
+	imageStore := &mockImageConfigStore{configs: make(map[digest.Digest][]byte)}
+	imagePullConfig := &ImagePullConfig{
+		Config: Config{
+			MetaHeaders:    http.Header{},
+			AuthConfig:     &types.AuthConfig{},
+			ProgressOutput: progress.DiscardOutput(),
+			ImageStore:     imageStore,
+		},
+		Schema2Types: ImageTypes,
+	}
+	puller, err := newPuller(registry.APIEndpoint{URL: uri, Version: registry.APIVersion2}, repoInfo, imagePullConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := puller.(*v2Puller)
+	ctx := context.Background()
+	p.repo, _, err = NewV2Repository(ctx, p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tagged, _ := reference.WithTag(n, "latest")
+	if _, err := p.pullV2Tag(ctx, tagged); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := imageStore.Get(nativeConfig); err != nil {
+		t.Fatalf("expected the image of the native platform to be pulled: %v", err)
+	}
+	if len(imageStore.configs) != 1 {
+		t.Fatalf("expected a single image to be pulled, got %d", len(imageStore.configs))
+	}
+
+	// Pulling the index by digest verifies it.
+	canonical, _ := reference.WithDigest(n, index.Digest)
+	if _, err := p.pullV2Tag(ctx, canonical); err != nil {
+		t.Fatal(err)
+	}
+}
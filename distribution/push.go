@@ -2,12 +2,12 @@ package distribution
 
 import (
 	"bufio"
-	"compress/gzip"
 	"fmt"
 	"io"
 
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/registry"
 	"github.com/sirupsen/logrus"
@@ -76,6 +76,14 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 		return fmt.Errorf("An image does not exist locally with the tag: %s", reference.FamiliarName(repoInfo.Name))
 	}
 
+	return pushToEndpoints(ctx, ref, repoInfo, endpoints, imagePushConfig, func(endpoint registry.APIEndpoint) (Pusher, error) {
+		return NewPusher(ref, endpoint, repoInfo, imagePushConfig)
+	})
+}
+
+// pushToEndpoints tries the pushers created by newPusher for each of the
+// endpoints, until a push succeeds or fails without a possible fallback.
+func pushToEndpoints(ctx context.Context, ref reference.Named, repoInfo *registry.RepositoryInfo, endpoints []registry.APIEndpoint, imagePushConfig *ImagePushConfig, newPusher func(registry.APIEndpoint) (Pusher, error)) error {
 	var (
 		lastErr error
 
@@ -108,7 +116,7 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 
 		logrus.Debugf("Trying to push %s to %s %s", repoInfo.Name.Name(), endpoint.URL, endpoint.Version)
 
-		pusher, err := NewPusher(ref, endpoint, repoInfo, imagePushConfig)
+		pusher, err := newPusher(endpoint)
 		if err != nil {
 			lastErr = err
 			continue
@@ -145,9 +153,9 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 	return lastErr
 }
 
-// compress returns an io.ReadCloser which will supply a compressed version of
-// the provided Reader. The caller must close the ReadCloser after reading the
-// compressed data.
+// compress returns an io.ReadCloser which will supply a version of the
+// provided Reader compressed with compression. The caller must close the
+// ReadCloser after reading the compressed data.
 //
 // Note that this function returns a reader instead of taking a writer as an
 // argument so that it can be used with httpBlobWriter's ReadFrom method.
@@ -158,19 +166,30 @@ func Push(ctx context.Context, ref reference.Named, imagePushConfig *ImagePushCo
 // is finished. This allows the caller to make sure the goroutine finishes
 // before it releases any resources connected with the reader that was
 // passed in.
-func compress(in io.Reader) (io.ReadCloser, chan struct{}) {
+func compress(in io.Reader, compression archive.Compression) (io.ReadCloser, chan struct{}) {
 	compressionDone := make(chan struct{})
 
 	pipeReader, pipeWriter := io.Pipe()
 	// Use a bufio.Writer to avoid excessive chunking in HTTP request.
 	bufWriter := bufio.NewWriterSize(pipeWriter, compressionBufSize)
-	compressor := gzip.NewWriter(bufWriter)
 
 	go func() {
-		_, err := io.Copy(compressor, in)
-		if err == nil {
-			err = compressor.Close()
+		compressor, err := archive.CompressStream(bufWriter, compression)
+		if err != nil {
+			pipeWriter.CloseWithError(err)
+			close(compressionDone)
+			return
+		}
+		_, err = io.Copy(compressor, in)
+		if err != nil {
+			// Close the pipe first, so that closing the compressor
+			// cannot block on writing its remaining output.
+			pipeWriter.CloseWithError(err)
+			compressor.Close()
+			close(compressionDone)
+			return
 		}
+		err = compressor.Close()
 		if err == nil {
 			err = bufWriter.Flush()
 		}
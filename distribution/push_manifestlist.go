@@ -0,0 +1,214 @@
+package distribution
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/reference"
+	apitypes "github.com/docker/docker/api/types"
+	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/ocischema"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/net/context"
+)
+
+// ManifestListEntry is a local image referenced by a manifest list, with
+// the platform it is published for.
+type ManifestListEntry struct {
+	// ImageID is the ID of the image.
+	ImageID digest.Digest
+	// Platform is the platform of the image in the manifest list.
+	Platform manifestlist.PlatformSpec
+}
+
+// NewManifestListEntry creates the manifest list entry of the image id,
+// with the platform of its configuration.
+func NewManifestListEntry(is ImageConfigStore, id digest.Digest) (ManifestListEntry, error) {
+	configJSON, err := is.Get(id)
+	if err != nil {
+		return ManifestListEntry{}, err
+	}
+	var config struct {
+		Architecture string   `json:"architecture,omitempty"`
+		OS           string   `json:"os,omitempty"`
+		OSVersion    string   `json:"os.version,omitempty"`
+		OSFeatures   []string `json:"os.features,omitempty"`
+	}
+	if err := json.Unmarshal(configJSON, &config); err != nil {
+		return ManifestListEntry{}, err
+	}
+	return ManifestListEntry{
+		ImageID: id,
+		Platform: manifestlist.PlatformSpec{
+			Architecture: config.Architecture,
+			OS:           config.OS,
+			OSVersion:    config.OSVersion,
+			OSFeatures:   config.OSFeatures,
+		},
+	}, nil
+}
+
+// Annotate overrides the platform of the entry with the fields of platform
+// which are set.
+func (e *ManifestListEntry) Annotate(platform manifestlist.PlatformSpec) {
+	if platform.Architecture != "" {
+		e.Platform.Architecture = platform.Architecture
+	}
+	if platform.OS != "" {
+		e.Platform.OS = platform.OS
+	}
+	if platform.OSVersion != "" {
+		e.Platform.OSVersion = platform.OSVersion
+	}
+	if len(platform.OSFeatures) > 0 {
+		e.Platform.OSFeatures = platform.OSFeatures
+	}
+	if platform.Variant != "" {
+		e.Platform.Variant = platform.Variant
+	}
+	if len(platform.Features) > 0 {
+		e.Platform.Features = platform.Features
+	}
+}
+
+// validateManifestListEntries checks that the entries of a manifest list
+// all have a platform, and that no two entries have the same platform.
+func validateManifestListEntries(entries []ManifestListEntry) error {
+	if len(entries) == 0 {
+		return errors.New("a manifest list must reference at least one image")
+	}
+	platforms := make(map[string]digest.Digest)
+	for _, e := range entries {
+		if e.Platform.OS == "" || e.Platform.Architecture == "" {
+			return fmt.Errorf("the platform of image %s has no os or architecture", e.ImageID)
+		}
+		key := fmt.Sprintf("%s/%s/%s/%s", e.Platform.OS, e.Platform.Architecture, e.Platform.Variant, e.Platform.OSVersion)
+		if id, exists := platforms[key]; exists {
+			return fmt.Errorf("images %s and %s have the same platform %s/%s", id, e.ImageID, e.Platform.OS, e.Platform.Architecture)
+		}
+		platforms[key] = e.ImageID
+	}
+	return nil
+}
+
+// PushManifestList pushes the images of entries to the repository of ref,
+// and tags a manifest list referencing them with the tag of ref. If
+// imagePushConfig.OCIManifest is set, OCI image manifests and an OCI image
+// index are pushed instead.
+func PushManifestList(ctx context.Context, ref reference.NamedTagged, entries []ManifestListEntry, imagePushConfig *ImagePushConfig) error {
+	if err := validateManifestListEntries(entries); err != nil {
+		return err
+	}
+
+	repoInfo, err := imagePushConfig.RegistryService.ResolveRepository(ref)
+	if err != nil {
+		return err
+	}
+
+	endpoints, err := imagePushConfig.RegistryService.LookupPushEndpoints(reference.Domain(repoInfo.Name))
+	if err != nil {
+		return err
+	}
+
+	progress.Messagef(imagePushConfig.ProgressOutput, "", "The push refers to a repository [%s]", repoInfo.Name.Name())
+
+	return pushToEndpoints(ctx, ref, repoInfo, endpoints, imagePushConfig, func(endpoint registry.APIEndpoint) (Pusher, error) {
+		if endpoint.Version != registry.APIVersion2 {
+			return nil, fmt.Errorf("manifest lists cannot be pushed to v1 registry %s", endpoint.URL)
+		}
+		return &manifestListPusher{
+			v2Pusher: v2Pusher{
+				v2MetadataService: metadata.NewV2MetadataService(imagePushConfig.MetadataStore),
+				ref:               ref,
+				endpoint:          endpoint,
+				repoInfo:          repoInfo,
+				config:            imagePushConfig,
+			},
+			tag:     ref.Tag(),
+			entries: entries,
+		}, nil
+	})
+}
+
+// manifestListPusher pushes the images of a manifest list and the manifest
+// list itself to a v2 registry.
+type manifestListPusher struct {
+	v2Pusher
+	tag     string
+	entries []ManifestListEntry
+}
+
+func (p *manifestListPusher) Push(ctx context.Context) (err error) {
+	p.pushState.remoteLayers = make(map[layer.DiffID]distribution.Descriptor)
+
+	p.repo, p.pushState.confirmedV2, err = NewV2Repository(ctx, p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "push", "pull")
+	if err != nil {
+		logrus.Debugf("Error getting v2 registry: %v", err)
+		return err
+	}
+
+	if err = p.pushManifestList(ctx); err != nil {
+		if continueOnError(err) {
+			return fallbackError{
+				err:         err,
+				confirmedV2: p.pushState.confirmedV2,
+				transportOK: true,
+			}
+		}
+	}
+	return err
+}
+
+func (p *manifestListPusher) pushManifestList(ctx context.Context) error {
+	descriptors := make([]manifestlist.ManifestDescriptor, 0, len(p.entries))
+	for _, e := range p.entries {
+		logrus.Debugf("Pushing image %s for %s/%s to %s", e.ImageID, e.Platform.OS, e.Platform.Architecture, p.repoInfo.Name.Name())
+		desc, err := p.pushV2Image(ctx, nil, e.ImageID)
+		if err != nil {
+			return err
+		}
+		progress.Messagef(p.config.ProgressOutput, "", "%s/%s: digest: %s size: %d", e.Platform.OS, e.Platform.Architecture, desc.Digest, desc.Size)
+		descriptors = append(descriptors, manifestlist.ManifestDescriptor{Descriptor: desc, Platform: e.Platform})
+	}
+
+	var (
+		list distribution.Manifest
+		err  error
+	)
+	if p.config.OCIManifest {
+		list, err = ocischema.FromDescriptors(descriptors, nil)
+	} else {
+		list, err = manifestlist.FromDescriptors(descriptors)
+	}
+	if err != nil {
+		return err
+	}
+
+	manSvc, err := p.repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := manSvc.Put(ctx, list, distribution.WithTag(p.tag)); err != nil {
+		return err
+	}
+
+	_, canonicalList, err := list.Payload()
+	if err != nil {
+		return err
+	}
+	listDigest := digest.FromBytes(canonicalList)
+	progress.Messagef(p.config.ProgressOutput, "", "%s: digest: %s size: %d", p.tag, listDigest, len(canonicalList))
+
+	// Signal digest to the trust client so it can sign the
+	// push, if appropriate.
+	progress.Aux(p.config.ProgressOutput, apitypes.PushResult{Tag: p.tag, Digest: listDigest.String(), Size: len(canonicalList)})
+
+	return nil
+}
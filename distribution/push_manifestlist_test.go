@@ -0,0 +1,246 @@
+package distribution
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/distribution/ocischema"
+	"github.com/docker/docker/distribution/xfer"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+func TestManifestListEntry(t *testing.T) {
+	is := &mockImageConfigStore{configs: make(map[digest.Digest][]byte)}
+	id, _ := is.Put([]byte(`{"architecture":"arm","os":"linux","os.features":["feature"]}`))
+
+	e, err := NewManifestListEntry(is, id)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := manifestlist.PlatformSpec{Architecture: "arm", OS: "linux", OSFeatures: []string{"feature"}}
+	if e.ImageID != id || !reflect.DeepEqual(e.Platform, expected) {
+		t.Fatalf("unexpected entry %+v", e)
+	}
+
+	e.Annotate(manifestlist.PlatformSpec{Variant: "v7", Features: []string{"sse4"}})
+	expected.Variant = "v7"
+	expected.Features = []string{"sse4"}
+	if !reflect.DeepEqual(e.Platform, expected) {
+		t.Fatalf("unexpected annotated platform %+v", e.Platform)
+	}
+
+	if _, err := NewManifestListEntry(is, digest.FromString("missing")); err == nil {
+		t.Fatal("expected an error for a missing image")
+	}
+}
+
+func TestValidateManifestListEntries(t *testing.T) {
+	amd64 := ManifestListEntry{ImageID: digest.FromString("amd64"), Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: "amd64"}}
+	armv6 := ManifestListEntry{ImageID: digest.FromString("armv6"), Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: "arm", Variant: "v6"}}
+	armv7 := ManifestListEntry{ImageID: digest.FromString("armv7"), Platform: manifestlist.PlatformSpec{OS: "linux", Architecture: "arm", Variant: "v7"}}
+	noOS := ManifestListEntry{ImageID: digest.FromString("noos"), Platform: manifestlist.PlatformSpec{Architecture: "amd64"}}
+
+	if err := validateManifestListEntries([]ManifestListEntry{amd64, armv6, armv7}); err != nil {
+		t.Fatal(err)
+	}
+	for _, entries := range [][]ManifestListEntry{nil, {amd64, noOS}, {armv6, amd64, armv6}} {
+		if err := validateManifestListEntries(entries); err == nil {
+			t.Errorf("expected an error for entries %+v", entries)
+		}
+	}
+}
+
+type mockPushLayer struct{}
+
+func (mockPushLayer) ChainID() layer.ChainID               { return "" }
+func (mockPushLayer) DiffID() layer.DiffID                 { return "" }
+func (mockPushLayer) Parent() PushLayer                    { return nil }
+func (mockPushLayer) Open() (io.ReadCloser, error)         { return nil, fmt.Errorf("no content") }
+func (mockPushLayer) Size() (int64, error)                 { return 0, nil }
+func (mockPushLayer) MediaType() string                    { return schema2.MediaTypeLayer }
+func (mockPushLayer) Release()                             {}
+func (mockPushLayer) Get(layer.ChainID) (PushLayer, error) { return mockPushLayer{}, nil }
+
+// pushRegistryHandler is a v2 registry which stores the pushed blobs and
+// manifests in memory.
+type pushRegistryHandler struct {
+	sync.Mutex
+	blobs     map[digest.Digest][]byte
+	manifests map[string][]byte
+	uploads   map[string][]byte
+}
+
+func (h *pushRegistryHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.Lock()
+	defer h.Unlock()
+
+	w.Header().Set("Docker-Distribution-API-Version", "registry/2.0")
+	path := r.URL.Path
+	switch {
+	case path == "/v2/":
+	case strings.Contains(path, "/blobs/uploads/"):
+		uuid := path[strings.LastIndex(path, "/")+1:]
+		if r.Method == "POST" {
+			uuid = fmt.Sprintf("upload%d", len(h.uploads))
+			h.uploads[uuid] = nil
+			w.Header().Set("Location", path+uuid)
+			w.Header().Set("Docker-Upload-UUID", uuid)
+			w.Header().Set("Range", "0-0")
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		h.uploads[uuid] = append(h.uploads[uuid], b...)
+		if r.Method == "PATCH" {
+			w.Header().Set("Location", path)
+			w.Header().Set("Docker-Upload-UUID", uuid)
+			w.Header().Set("Range", fmt.Sprintf("0-%d", len(h.uploads[uuid])-1))
+			w.WriteHeader(http.StatusAccepted)
+			return
+		}
+		dgst := digest.FromBytes(h.uploads[uuid])
+		h.blobs[dgst] = h.uploads[uuid]
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	case strings.Contains(path, "/blobs/"):
+		b, ok := h.blobs[digest.Digest(path[strings.LastIndex(path, "/")+1:])]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Content-Length", fmt.Sprint(len(b)))
+		w.Header().Set("Docker-Content-Digest", digest.FromBytes(b).String())
+		if r.Method != "HEAD" {
+			w.Write(b)
+		}
+	case strings.Contains(path, "/manifests/") && r.Method == "PUT":
+		b, _ := ioutil.ReadAll(r.Body)
+		dgst := digest.FromBytes(b)
+		h.manifests[path[strings.LastIndex(path, "/")+1:]] = b
+		h.manifests[dgst.String()] = b
+		w.Header().Set("Docker-Content-Digest", dgst.String())
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func testPushManifestList(t *testing.T, ociManifest bool) distribution.Manifest {
+	handler := &pushRegistryHandler{
+		blobs:     make(map[digest.Digest][]byte),
+		manifests: make(map[string][]byte),
+		uploads:   make(map[string][]byte),
+	}
+	ts := httptest.NewServer(handler)
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	is := &mockImageConfigStore{configs: make(map[digest.Digest][]byte)}
+	var entries []ManifestListEntry
+	for _, arch := range []string{"amd64", "arm64"} {
+		id, _ := is.Put([]byte(fmt.Sprintf(`{"architecture":%q,"os":"linux","rootfs":{"type":"layers","diff_ids":[]}}`, arch)))
+		e, err := NewManifestListEntry(is, id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries = append(entries, e)
+	}
+
+	n, _ := reference.ParseNormalizedNamed("testremotename")
+	ref, _ := reference.WithTag(n, "multi")
+	p := &manifestListPusher{
+		v2Pusher: v2Pusher{
+			v2MetadataService: &mockV2MetadataService{},
+			ref:               ref,
+			endpoint:          registry.APIEndpoint{URL: uri, Version: registry.APIVersion2},
+			repoInfo: &registry.RepositoryInfo{
+				Name:  n,
+				Index: &registrytypes.IndexInfo{Name: "testrepo"},
+			},
+			config: &ImagePushConfig{
+				Config: Config{
+					MetaHeaders:    http.Header{},
+					AuthConfig:     &types.AuthConfig{},
+					ProgressOutput: progress.DiscardOutput(),
+					ImageStore:     is,
+				},
+				ConfigMediaType: schema2.MediaTypeImageConfig,
+				OCIManifest:     ociManifest,
+				LayerStore:      mockPushLayer{},
+				UploadManager:   xfer.NewLayerUploadManager(1),
+			},
+		},
+		tag:     ref.Tag(),
+		entries: entries,
+	}
+	if err := p.Push(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	listType := manifestlist.MediaTypeManifestList
+	manifestType := schema2.MediaTypeManifest
+	if ociManifest {
+		listType = ocispec.MediaTypeImageIndex
+		manifestType = ocispec.MediaTypeImageManifest
+	}
+	list, _, err := distribution.UnmarshalManifest(listType, handler.manifests["multi"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var descriptors []manifestlist.ManifestDescriptor
+	switch l := list.(type) {
+	case *manifestlist.DeserializedManifestList:
+		descriptors = l.Manifests
+	case *ocischema.DeserializedImageIndex:
+		descriptors = l.Manifests
+	}
+	if len(descriptors) != len(entries) {
+		t.Fatalf("expected %d manifests in the list, got %d", len(entries), len(descriptors))
+	}
+	for i, d := range descriptors {
+		if !reflect.DeepEqual(d.Platform, entries[i].Platform) {
+			t.Errorf("expected platform %+v, got %+v", entries[i].Platform, d.Platform)
+		}
+		if d.MediaType != manifestType {
+			t.Errorf("expected media type %s, got %s", manifestType, d.MediaType)
+		}
+		if _, ok := handler.manifests[d.Digest.String()]; !ok {
+			t.Errorf("manifest %s of the list was not pushed", d.Digest)
+		}
+		if _, ok := handler.blobs[entries[i].ImageID]; !ok {
+			t.Errorf("config %s was not pushed", entries[i].ImageID)
+		}
+	}
+	return list
+}
+
+func TestPushManifestList(t *testing.T) {
+	if _, ok := testPushManifestList(t, false).(*manifestlist.DeserializedManifestList); !ok {
+		t.Fatal("expected a manifest list")
+	}
+	if _, ok := testPushManifestList(t, true).(*ocischema.DeserializedImageIndex); !ok {
+		t.Fatal("expected an OCI image index")
+	}
+}
@@ -18,8 +18,10 @@ import (
 	"github.com/docker/distribution/registry/client"
 	apitypes "github.com/docker/docker/api/types"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/ocischema"
 	"github.com/docker/docker/distribution/xfer"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/ioutils"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/docker/docker/pkg/stringid"
@@ -113,25 +115,48 @@ func (p *v2Pusher) pushV2Repository(ctx context.Context) (err error) {
 func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id digest.Digest) error {
 	logrus.Debugf("Pushing repository: %s", reference.FamiliarString(ref))
 
+	desc, err := p.pushV2Image(ctx, ref, id)
+	if err != nil {
+		return err
+	}
+
+	progress.Messagef(p.config.ProgressOutput, "", "%s: digest: %s size: %d", ref.Tag(), desc.Digest, desc.Size)
+
+	if err := addDigestReference(p.config.ReferenceStore, ref, desc.Digest, id); err != nil {
+		return err
+	}
+
+	// Signal digest to the trust client so it can sign the
+	// push, if appropriate.
+	progress.Aux(p.config.ProgressOutput, apitypes.PushResult{Tag: ref.Tag(), Digest: desc.Digest.String(), Size: int(desc.Size)})
+
+	return nil
+}
+
+// pushV2Image pushes the layers and the manifest of the image id, and
+// returns the descriptor of the manifest. The manifest is tagged with ref,
+// or only pushed by digest if ref is nil, in which case there is no
+// fallback to schema1.
+func (p *v2Pusher) pushV2Image(ctx context.Context, ref reference.NamedTagged, id digest.Digest) (distribution.Descriptor, error) {
 	imgConfig, err := p.config.ImageStore.Get(id)
 	if err != nil {
-		return fmt.Errorf("could not find image from tag %s: %v", reference.FamiliarString(ref), err)
+		return distribution.Descriptor{}, fmt.Errorf("could not find image %s: %v", id, err)
 	}
 
 	rootfs, _, err := p.config.ImageStore.RootFSAndPlatformFromConfig(imgConfig)
 	if err != nil {
-		return fmt.Errorf("unable to get rootfs for image %s: %s", reference.FamiliarString(ref), err)
+		return distribution.Descriptor{}, fmt.Errorf("unable to get rootfs for image %s: %s", id, err)
 	}
 
 	l, err := p.config.LayerStore.Get(rootfs.ChainID())
 	if err != nil {
-		return fmt.Errorf("failed to get top layer from image: %v", err)
+		return distribution.Descriptor{}, fmt.Errorf("failed to get top layer from image: %v", err)
 	}
 	defer l.Release()
 
 	hmacKey, err := metadata.ComputeV2MetadataHMACKey(p.config.AuthConfig)
 	if err != nil {
-		return fmt.Errorf("failed to compute hmac key of auth config: %v", err)
+		return distribution.Descriptor{}, fmt.Errorf("failed to compute hmac key of auth config: %v", err)
 	}
 
 	var descriptors []xfer.UploadDescriptor
@@ -144,6 +169,7 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 		endpoint:          p.endpoint,
 		repo:              p.repo,
 		pushState:         &p.pushState,
+		zstd:              p.config.ZstdCompression,
 	}
 
 	// Loop bounds condition is to avoid pushing the base layer on Windows.
@@ -157,69 +183,83 @@ func (p *v2Pusher) pushV2Tag(ctx context.Context, ref reference.NamedTagged, id
 	}
 
 	if err := p.config.UploadManager.Upload(ctx, descriptors, p.config.ProgressOutput); err != nil {
-		return err
+		return distribution.Descriptor{}, err
 	}
 
-	// Try schema2 first
-	builder := schema2.NewManifestBuilder(p.repo.Blobs(ctx), p.config.ConfigMediaType, imgConfig)
+	// Try schema2 first, unless an OCI manifest is requested
+	var builder distribution.ManifestBuilder
+	if p.config.OCIManifest {
+		builder = ocischema.NewManifestBuilder(p.repo.Blobs(ctx), imgConfig, nil)
+	} else {
+		builder = schema2.NewManifestBuilder(p.repo.Blobs(ctx), p.config.ConfigMediaType, imgConfig)
+	}
 	manifest, err := manifestFromBuilder(ctx, builder, descriptors)
 	if err != nil {
-		return err
+		return distribution.Descriptor{}, err
 	}
 
 	manSvc, err := p.repo.Manifests(ctx)
 	if err != nil {
-		return err
+		return distribution.Descriptor{}, err
 	}
 
-	putOptions := []distribution.ManifestServiceOption{distribution.WithTag(ref.Tag())}
+	var putOptions []distribution.ManifestServiceOption
+	if ref != nil {
+		putOptions = append(putOptions, distribution.WithTag(ref.Tag()))
+	}
 	if _, err = manSvc.Put(ctx, manifest, putOptions...); err != nil {
-		if runtime.GOOS == "windows" || p.config.TrustKey == nil || p.config.RequireSchema2 {
+		if p.config.OCIManifest {
+			logrus.Warnf("failed to upload OCI manifest: %v", err)
+			return distribution.Descriptor{}, err
+		}
+		if runtime.GOOS == "windows" || p.config.TrustKey == nil || p.config.RequireSchema2 || ref == nil {
 			logrus.Warnf("failed to upload schema2 manifest: %v", err)
-			return err
+			return distribution.Descriptor{}, err
 		}
 
 		logrus.Warnf("failed to upload schema2 manifest: %v - falling back to schema1", err)
 
 		manifestRef, err := reference.WithTag(p.repo.Named(), ref.Tag())
 		if err != nil {
-			return err
+			return distribution.Descriptor{}, err
 		}
 		builder = schema1.NewConfigManifestBuilder(p.repo.Blobs(ctx), p.config.TrustKey, manifestRef, imgConfig)
 		manifest, err = manifestFromBuilder(ctx, builder, descriptors)
 		if err != nil {
-			return err
+			return distribution.Descriptor{}, err
 		}
 
 		if _, err = manSvc.Put(ctx, manifest, putOptions...); err != nil {
-			return err
+			return distribution.Descriptor{}, err
 		}
 	}
 
-	var canonicalManifest []byte
+	var (
+		mediaType         string
+		canonicalManifest []byte
+	)
 
 	switch v := manifest.(type) {
 	case *schema1.SignedManifest:
+		mediaType = schema1.MediaTypeSignedManifest
 		canonicalManifest = v.Canonical
 	case *schema2.DeserializedManifest:
-		_, canonicalManifest, err = v.Payload()
+		mediaType, canonicalManifest, err = v.Payload()
 		if err != nil {
-			return err
+			return distribution.Descriptor{}, err
+		}
+	case *ocischema.DeserializedManifest:
+		mediaType, canonicalManifest, err = v.Payload()
+		if err != nil {
+			return distribution.Descriptor{}, err
 		}
 	}
 
-	manifestDigest := digest.FromBytes(canonicalManifest)
-	progress.Messagef(p.config.ProgressOutput, "", "%s: digest: %s size: %d", ref.Tag(), manifestDigest, len(canonicalManifest))
-
-	if err := addDigestReference(p.config.ReferenceStore, ref, manifestDigest, id); err != nil {
-		return err
-	}
-
-	// Signal digest to the trust client so it can sign the
-	// push, if appropriate.
-	progress.Aux(p.config.ProgressOutput, apitypes.PushResult{Tag: ref.Tag(), Digest: manifestDigest.String(), Size: len(canonicalManifest)})
-
-	return nil
+	return distribution.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(canonicalManifest),
+		Size:      int64(len(canonicalManifest)),
+	}, nil
 }
 
 func manifestFromBuilder(ctx context.Context, builder distribution.ManifestBuilder, descriptors []xfer.UploadDescriptor) (distribution.Manifest, error) {
@@ -244,6 +284,8 @@ type v2PushDescriptor struct {
 	repo              distribution.Repository
 	pushState         *pushState
 	remoteDescriptor  distribution.Descriptor
+	// zstd compresses the layer with zstd instead of gzip
+	zstd bool
 	// a set of digests whose presence has been checked in a target repository
 	checkedDigests map[digest.Digest]struct{}
 }
@@ -284,10 +326,14 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 	pd.pushState.Unlock()
 
 	maxMountAttempts, maxExistenceChecks, checkOtherRepositories := getMaxMountAndExistenceCheckAttempts(pd.layer)
+	mediaType := pd.blobMediaType()
 
 	// Do we have any metadata associated with this layer's DiffID?
 	v2Metadata, err := pd.v2MetadataService.GetMetadata(diffID)
 	if err == nil {
+		// Only the blobs with the compression of this push can be reused
+		v2Metadata = filterV2MetadataByMediaType(v2Metadata, mediaType)
+
 		// check for blob existence in the target repository
 		descriptor, exists, err := pd.layerAlreadyExists(ctx, progressOutput, diffID, true, 1, v2Metadata)
 		if exists || err != nil {
@@ -340,7 +386,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 		case distribution.ErrBlobMounted:
 			progress.Updatef(progressOutput, pd.ID(), "Mounted from %s", err.From.Name())
 
-			err.Descriptor.MediaType = schema2.MediaTypeLayer
+			err.Descriptor.MediaType = mediaType
 
 			pd.pushState.Lock()
 			pd.pushState.confirmedV2 = true
@@ -351,6 +397,7 @@ func (pd *v2PushDescriptor) Upload(ctx context.Context, progressOutput progress.
 			if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
 				Digest:           err.Descriptor.Digest,
 				SourceRepository: pd.repoInfo.Name(),
+				MediaType:        v2MetadataMediaType(mediaType),
 			}); err != nil {
 				return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 			}
@@ -406,6 +453,38 @@ func (pd *v2PushDescriptor) Descriptor() distribution.Descriptor {
 	return pd.remoteDescriptor
 }
 
+// blobMediaType returns the media type of the blob of the layer in the
+// registry. Uncompressed layers are compressed with zstd if requested, and
+// with gzip otherwise.
+func (pd *v2PushDescriptor) blobMediaType() string {
+	if pd.zstd && pd.layer.MediaType() == schema2.MediaTypeUncompressedLayer {
+		return ocischema.MediaTypeLayerZstd
+	}
+	return schema2.MediaTypeLayer
+}
+
+// v2MetadataMediaType returns the media type to record in the V2Metadata of
+// a blob with the given media type. It is only recorded for zstd compressed
+// blobs, as the other blobs are gzip compressed.
+func v2MetadataMediaType(mediaType string) string {
+	if mediaType == ocischema.MediaTypeLayerZstd {
+		return mediaType
+	}
+	return ""
+}
+
+// filterV2MetadataByMediaType returns the metadata of the blobs with the
+// given media type.
+func filterV2MetadataByMediaType(v2Metadata []metadata.V2Metadata, mediaType string) []metadata.V2Metadata {
+	var filtered []metadata.V2Metadata
+	for _, meta := range v2Metadata {
+		if meta.MediaType == v2MetadataMediaType(mediaType) {
+			filtered = append(filtered, meta)
+		}
+	}
+	return filtered
+}
+
 func (pd *v2PushDescriptor) uploadUsingSession(
 	ctx context.Context,
 	progressOutput progress.Output,
@@ -423,9 +502,14 @@ func (pd *v2PushDescriptor) uploadUsingSession(
 
 	reader = progress.NewProgressReader(ioutils.NewCancelReadCloser(ctx, contentReader), progressOutput, size, pd.ID(), "Pushing")
 
+	mediaType := pd.blobMediaType()
 	switch m := pd.layer.MediaType(); m {
 	case schema2.MediaTypeUncompressedLayer:
-		compressedReader, compressionDone := compress(reader)
+		compression := archive.Gzip
+		if mediaType == ocischema.MediaTypeLayerZstd {
+			compression = archive.Zstd
+		}
+		compressedReader, compressionDone := compress(reader, compression)
 		defer func(closer io.Closer) {
 			closer.Close()
 			<-compressionDone
@@ -458,13 +542,14 @@ func (pd *v2PushDescriptor) uploadUsingSession(
 	if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
 		Digest:           pushDigest,
 		SourceRepository: pd.repoInfo.Name(),
+		MediaType:        v2MetadataMediaType(mediaType),
 	}); err != nil {
 		return distribution.Descriptor{}, xfer.DoNotRetry{Err: err}
 	}
 
 	desc := distribution.Descriptor{
 		Digest:    pushDigest,
-		MediaType: schema2.MediaTypeLayer,
+		MediaType: mediaType,
 		Size:      nn,
 	}
 
@@ -533,11 +618,12 @@ attempts:
 				if err := pd.v2MetadataService.TagAndAdd(diffID, pd.hmacKey, metadata.V2Metadata{
 					Digest:           desc.Digest,
 					SourceRepository: pd.repoInfo.Name(),
+					MediaType:        v2MetadataMediaType(pd.blobMediaType()),
 				}); err != nil {
 					return distribution.Descriptor{}, false, xfer.DoNotRetry{Err: err}
 				}
 			}
-			desc.MediaType = schema2.MediaTypeLayer
+			desc.MediaType = pd.blobMediaType()
 			exists = true
 			break attempts
 		case distribution.ErrBlobUnknown:
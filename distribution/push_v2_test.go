@@ -10,6 +10,7 @@ import (
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/distribution/metadata"
+	"github.com/docker/docker/distribution/ocischema"
 	"github.com/docker/docker/layer"
 	"github.com/docker/docker/pkg/progress"
 	"github.com/opencontainers/go-digest"
@@ -461,6 +462,43 @@ func TestLayerAlreadyExists(t *testing.T) {
 	}
 }
 
+type mediaTypePushLayer struct {
+	mockPushLayer
+	mediaType string
+}
+
+func (l mediaTypePushLayer) MediaType() string { return l.mediaType }
+
+func TestBlobMediaType(t *testing.T) {
+	for _, tc := range []struct {
+		layerMediaType string
+		zstd           bool
+		expected       string
+	}{
+		{layerMediaType: schema2.MediaTypeUncompressedLayer, expected: schema2.MediaTypeLayer},
+		{layerMediaType: schema2.MediaTypeUncompressedLayer, zstd: true, expected: ocischema.MediaTypeLayerZstd},
+		{layerMediaType: schema2.MediaTypeLayer, zstd: true, expected: schema2.MediaTypeLayer},
+	} {
+		pd := &v2PushDescriptor{layer: mediaTypePushLayer{mediaType: tc.layerMediaType}, zstd: tc.zstd}
+		if mediaType := pd.blobMediaType(); mediaType != tc.expected {
+			t.Errorf("%s with zstd %t: expected %s, got %s", tc.layerMediaType, tc.zstd, tc.expected, mediaType)
+		}
+	}
+}
+
+func TestFilterV2MetadataByMediaType(t *testing.T) {
+	gzipped := metadata.V2Metadata{Digest: digest.FromString("gzip"), SourceRepository: "docker.io/library/busybox"}
+	zstd := metadata.V2Metadata{Digest: digest.FromString("zstd"), SourceRepository: "docker.io/library/busybox", MediaType: ocischema.MediaTypeLayerZstd}
+	v2Metadata := []metadata.V2Metadata{gzipped, zstd}
+
+	if filtered := filterV2MetadataByMediaType(v2Metadata, schema2.MediaTypeLayer); !reflect.DeepEqual(filtered, []metadata.V2Metadata{gzipped}) {
+		t.Errorf("expected only the gzip blob, got %v", filtered)
+	}
+	if filtered := filterV2MetadataByMediaType(v2Metadata, ocischema.MediaTypeLayerZstd); !reflect.DeepEqual(filtered, []metadata.V2Metadata{zstd}) {
+		t.Errorf("expected only the zstd blob, got %v", filtered)
+	}
+}
+
 func taggedMetadata(key string, dgst string, sourceRepo string) metadata.V2Metadata {
 	meta := metadata.V2Metadata{
 		Digest:           digest.Digest(dgst),
@@ -9,6 +9,7 @@ import (
 	"github.com/docker/distribution"
 	"github.com/docker/distribution/manifest/schema2"
 	"github.com/docker/distribution/reference"
+	"github.com/docker/distribution/registry/api/v2"
 	"github.com/docker/distribution/registry/client"
 	"github.com/docker/distribution/registry/client/auth"
 	"github.com/docker/distribution/registry/client/transport"
@@ -16,12 +17,15 @@ import (
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/registry"
 	"github.com/docker/go-connections/sockets"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"golang.org/x/net/context"
 )
 
-// ImageTypes represents the schema2 config types for images
+// ImageTypes represents the schema2 and OCI config types for images
 var ImageTypes = []string{
 	schema2.MediaTypeImageConfig,
+	ocispec.MediaTypeImageConfig,
 	// Handle unexpected values from https://github.com/docker/distribution/issues/1621
 	// (see also https://github.com/docker/docker/issues/22378,
 	// https://github.com/docker/docker/issues/30083)
@@ -60,6 +64,12 @@ func NewV2Repository(ctx context.Context, repoInfo *registry.RepositoryInfo, end
 	if endpoint.TrimHostname {
 		repoName = reference.Path(repoInfo.Name)
 	}
+	if endpoint.RemoteName != "" {
+		repoName = endpoint.RemoteName
+	}
+	if endpoint.AuthConfig != nil {
+		authConfig = endpoint.AuthConfig
+	}
 
 	direct := &net.Dialer{
 		Timeout:   30 * time.Second,
@@ -138,8 +148,36 @@ func NewV2Repository(ctx context.Context, repoInfo *registry.RepositoryInfo, end
 			confirmedV2: foundVersion,
 			transportOK: true,
 		}
+		return
+	}
+
+	ub, err := v2.NewURLBuilderFromString(endpoint.URL.String(), false)
+	if err != nil {
+		return nil, foundVersion, fallbackError{
+			err:         err,
+			confirmedV2: foundVersion,
+			transportOK: true,
+		}
+	}
+	return &v2Repository{Repository: repo, transport: tr, urlBuilder: ub}, foundVersion, nil
+}
+
+// v2Repository is a repository of a v2 registry, along with the transport
+// and the URL builder of the registry, for the requests which the repository
+// client does not support, such as range requests for blobs.
+type v2Repository struct {
+	distribution.Repository
+	transport  http.RoundTripper
+	urlBuilder *v2.URLBuilder
+}
+
+// blobURL returns the URL of the blob dgst in the repository.
+func (r *v2Repository) blobURL(dgst digest.Digest) (string, error) {
+	ref, err := reference.WithDigest(r.Named(), dgst)
+	if err != nil {
+		return "", err
 	}
-	return
+	return r.urlBuilder.BuildBlobURL(ref)
 }
 
 type existingTokenHandler struct {
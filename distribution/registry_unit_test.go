@@ -7,6 +7,7 @@ import (
 	"strings"
 	"testing"
 
+	"github.com/docker/distribution"
 	"github.com/docker/distribution/reference"
 	"github.com/docker/docker/api/types"
 	registrytypes "github.com/docker/docker/api/types/registry"
@@ -125,3 +126,67 @@ func TestTokenPassThruDifferentHost(t *testing.T) {
 		t.Fatal("Redirect should not forward Authorization header to another host")
 	}
 }
+
+func TestMirrorEndpointRemoteNameAndCredentials(t *testing.T) {
+	var (
+		paths []string
+		users []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _, ok := r.BasicAuth()
+		if !ok {
+			w.Header().Set("WWW-Authenticate", `Basic realm="mirror"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		paths = append(paths, r.URL.Path)
+		users = append(users, user)
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer ts.Close()
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	n, _ := reference.ParseNormalizedNamed("ghcr.io/org/app")
+	repoInfo := &registry.RepositoryInfo{
+		Name:  n,
+		Index: &registrytypes.IndexInfo{Name: "ghcr.io"},
+	}
+	endpoint := registry.APIEndpoint{
+		URL:          uri,
+		Version:      registry.APIVersion2,
+		Mirror:       true,
+		TrimHostname: true,
+		RemoteName:   "ghcr/org/app",
+		AuthConfig:   &types.AuthConfig{Username: "mirroruser", Password: "mirrorpass"},
+	}
+	ctx := context.Background()
+	repo, _, err := NewV2Repository(ctx, repoInfo, endpoint, http.Header{}, &types.AuthConfig{Username: "registryuser", Password: "secret"}, "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if repo.Named().Name() != "ghcr/org/app" {
+		t.Fatalf("expected the repository to be named after the mirror, got %s", repo.Named().Name())
+	}
+	manSvc, err := repo.Manifests(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := manSvc.Get(ctx, "", distribution.WithTag("latest")); err == nil {
+		t.Fatal("expected the manifest to be unknown")
+	}
+
+	if len(paths) == 0 {
+		t.Fatal("expected authenticated requests to the mirror")
+	}
+	for i, path := range paths {
+		if path != "/v2/" && path != "/v2/ghcr/org/app/manifests/latest" {
+			t.Errorf("unexpected request to %s", path)
+		}
+		if users[i] != "mirroruser" {
+			t.Errorf("expected the credentials of the mirror, got user %q", users[i])
+		}
+	}
+}
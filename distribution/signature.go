@@ -0,0 +1,138 @@
+package distribution
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+const (
+	// MediaTypeSimpleSigning is the media type of the signed payloads of
+	// image signatures.
+	MediaTypeSimpleSigning = "application/vnd.dev.cosign.simplesigning.v1+json"
+	// signatureAnnotation is the annotation of the payload layers of a
+	// signature manifest holding the base64 encoded signature of the
+	// payload.
+	signatureAnnotation = "dev.cosignproject.cosign/signature"
+	// maxSignaturePayloadSize is the maximum size of a signed payload.
+	maxSignaturePayloadSize = 1 << 20
+)
+
+// SignaturePolicy requires the images pulled from some repositories to be
+// signed.
+type SignaturePolicy interface {
+	// PublicKeys returns the public keys which may sign the images of the
+	// repository name, or nil if its images do not need to be signed.
+	PublicKeys(name reference.Named) []crypto.PublicKey
+	// Verified is called when the image id, pulled from the manifest
+	// manifestDigest of the repository name, is signed.
+	Verified(name reference.Named, manifestDigest, id digest.Digest)
+	// Rejected is called when the signature of the manifest manifestDigest
+	// of the repository name cannot be verified.
+	Rejected(name reference.Named, manifestDigest digest.Digest, err error)
+}
+
+// simpleSigningPayload is the signed payload of an image signature.
+type simpleSigningPayload struct {
+	Critical struct {
+		Image struct {
+			DockerManifestDigest digest.Digest `json:"docker-manifest-digest"`
+		} `json:"image"`
+		Type string `json:"type"`
+	} `json:"critical"`
+}
+
+// signatureTag returns the tag of the signature of the manifest dgst. The
+// signature is an image manifest stored alongside the signed manifest in
+// its repository, whose layers are signed payloads referencing dgst.
+func signatureTag(dgst digest.Digest) string {
+	return fmt.Sprintf("%s-%s.sig", dgst.Algorithm(), dgst.Hex())
+}
+
+// verifySignature checks that the manifest dgst of repo has a signature
+// made by one of keys.
+func verifySignature(ctx context.Context, repo distribution.Repository, dgst digest.Digest, keys []crypto.PublicKey) error {
+	manSvc, err := repo.Manifests(ctx)
+	if err != nil {
+		return err
+	}
+	sigManifest, err := manSvc.Get(ctx, "", distribution.WithTag(signatureTag(dgst)))
+	if err != nil {
+		return fmt.Errorf("no signature found: %v", err)
+	}
+	_, payload, err := sigManifest.Payload()
+	if err != nil {
+		return err
+	}
+	var m ocispec.Manifest
+	if err := json.Unmarshal(payload, &m); err != nil {
+		return err
+	}
+
+	for _, layer := range m.Layers {
+		sig, ok := layer.Annotations[signatureAnnotation]
+		if !ok || layer.MediaType != MediaTypeSimpleSigning || layer.Size > maxSignaturePayloadSize || layer.Digest.Validate() != nil {
+			continue
+		}
+		signature, err := base64.StdEncoding.DecodeString(sig)
+		if err != nil {
+			continue
+		}
+		signed, err := repo.Blobs(ctx).Get(ctx, layer.Digest)
+		if err != nil {
+			return err
+		}
+		if layer.Digest.Algorithm().FromBytes(signed) != layer.Digest {
+			return fmt.Errorf("signed payload verification failed for digest %s", layer.Digest)
+		}
+		if !verifyPayloadSignature(signed, signature, keys) {
+			continue
+		}
+		var p simpleSigningPayload
+		if err := json.Unmarshal(signed, &p); err != nil {
+			continue
+		}
+		if p.Critical.Image.DockerManifestDigest == dgst {
+			return nil
+		}
+	}
+	return errors.New("no valid signature made by a trusted key")
+}
+
+// verifyPayloadSignature returns whether signature is a signature of payload
+// made by one of keys.
+func verifyPayloadSignature(payload, signature []byte, keys []crypto.PublicKey) bool {
+	hashed := sha256.Sum256(payload)
+	for _, key := range keys {
+		switch k := key.(type) {
+		case *ecdsa.PublicKey:
+			var sig struct {
+				R, S *big.Int
+			}
+			if rest, err := asn1.Unmarshal(signature, &sig); err != nil || len(rest) != 0 {
+				continue
+			}
+			if ecdsa.Verify(k, hashed[:], sig.R, sig.S) {
+				return true
+			}
+		case *rsa.PublicKey:
+			if rsa.VerifyPKCS1v15(k, crypto.SHA256, hashed[:], signature) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
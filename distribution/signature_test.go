@@ -0,0 +1,226 @@
+package distribution
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"runtime"
+	"testing"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+	registrytypes "github.com/docker/docker/api/types/registry"
+	"github.com/docker/docker/distribution/ocischema"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/registry"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"golang.org/x/net/context"
+)
+
+type mockSignaturePolicy struct {
+	keys     []crypto.PublicKey
+	verified []digest.Digest
+	rejected []digest.Digest
+}
+
+func (p *mockSignaturePolicy) PublicKeys(name reference.Named) []crypto.PublicKey {
+	return p.keys
+}
+
+func (p *mockSignaturePolicy) Verified(name reference.Named, manifestDigest, id digest.Digest) {
+	p.verified = append(p.verified, id)
+}
+
+func (p *mockSignaturePolicy) Rejected(name reference.Named, manifestDigest digest.Digest, err error) {
+	p.rejected = append(p.rejected, manifestDigest)
+}
+
+// addSignature stores a signature of the manifest dgst made with key.
+func (h *ociRegistryHandler) addSignature(dgst digest.Digest, key *ecdsa.PrivateKey) {
+	payload := []byte(fmt.Sprintf(`{"critical":{"identity":{"docker-reference":"testremotename"},"image":{"docker-manifest-digest":%q},"type":"cosign container image signature"},"optional":null}`, dgst))
+	payloadDigest := digest.FromBytes(payload)
+	h.blobs[payloadDigest] = payload
+	hashed := sha256.Sum256(payload)
+	signature, err := key.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	if err != nil {
+		panic(err)
+	}
+
+	config := []byte(`{}`)
+	h.blobs[digest.FromBytes(config)] = config
+	h.addManifest(signatureTag(dgst), ocispec.MediaTypeImageManifest, struct {
+		manifest.Versioned
+		Config ocispec.Descriptor   `json:"config"`
+		Layers []ocispec.Descriptor `json:"layers"`
+	}{
+		Versioned: ocischema.SchemaVersion,
+		Config:    ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: digest.FromBytes(config), Size: int64(len(config))},
+		Layers: []ocispec.Descriptor{{
+			MediaType:   MediaTypeSimpleSigning,
+			Digest:      payloadDigest,
+			Size:        int64(len(payload)),
+			Annotations: map[string]string{signatureAnnotation: base64.StdEncoding.EncodeToString(signature)},
+		}},
+	})
+}
+
+func newSignedPuller(t *testing.T, handler http.Handler, policy SignaturePolicy) (*v2Puller, *mockImageConfigStore, func()) {
+	ts := httptest.NewServer(handler)
+	uri, err := url.Parse(ts.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	n, _ := reference.ParseNormalizedNamed("testremotename")
+	repoInfo := &registry.RepositoryInfo{
+		Name:  n,
+		Index: &registrytypes.IndexInfo{Name: "testrepo"},
+	}
+	imageStore := &mockImageConfigStore{configs: make(map[digest.Digest][]byte)}
+	imagePullConfig := &ImagePullConfig{
+		Config: Config{
+			MetaHeaders:    http.Header{},
+			AuthConfig:     &types.AuthConfig{},
+			ProgressOutput: progress.DiscardOutput(),
+			ImageStore:     imageStore,
+		},
+		Schema2Types:    ImageTypes,
+		SignaturePolicy: policy,
+	}
+	puller, err := newPuller(registry.APIEndpoint{URL: uri, Version: registry.APIVersion2}, repoInfo, imagePullConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := puller.(*v2Puller)
+	p.repo, _, err = NewV2Repository(context.Background(), p.repoInfo, p.endpoint, p.config.MetaHeaders, p.config.AuthConfig, "pull")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return p, imageStore, ts.Close
+}
+
+func TestPullSignedImage(t *testing.T) {
+	handler := &ociRegistryHandler{
+		blobs:     make(map[digest.Digest][]byte),
+		manifests: make(map[string][]byte),
+	}
+	addImage := func(tag string) (distribution.Descriptor, digest.Digest) {
+		config := []byte(fmt.Sprintf(`{"architecture":%q,"os":%q,"rootfs":{"type":"layers","diff_ids":[]},"comment":%q}`, runtime.GOARCH, runtime.GOOS, tag))
+		configDigest := digest.FromBytes(config)
+		handler.blobs[configDigest] = config
+		d := handler.addManifest(tag, ocispec.MediaTypeImageManifest, ocischema.Manifest{
+			Versioned: ocischema.SchemaVersion,
+			Config:    distribution.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: configDigest, Size: int64(len(config))},
+			Layers:    []distribution.Descriptor{},
+		})
+		return d, configDigest
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signed, signedConfig := addImage("signed")
+	handler.addSignature(signed.Digest, key)
+	unsigned, unsignedConfig := addImage("unsigned")
+	otherSigned, otherSignedConfig := addImage("othersigned")
+	handler.addSignature(otherSigned.Digest, otherKey)
+	// A valid signature of another manifest is not a valid signature of
+	// mismatched.
+	mismatched, mismatchedConfig := addImage("mismatched")
+	handler.manifests[signatureTag(mismatched.Digest)] = handler.manifests[signatureTag(signed.Digest)]
+
+	policy := &mockSignaturePolicy{keys: []crypto.PublicKey{&key.PublicKey}}
+	p, imageStore, cleanup := newSignedPuller(t, handler, policy)
+	defer cleanup()
+	ctx := context.Background()
+	n, _ := reference.ParseNormalizedNamed("testremotename")
+
+	tagged, _ := reference.WithTag(n, "signed")
+	if _, err := p.pullV2Tag(ctx, tagged); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := imageStore.Get(signedConfig); err != nil {
+		t.Fatalf("expected the signed image to be pulled: %v", err)
+	}
+	if len(policy.verified) != 1 || policy.verified[0] != signedConfig {
+		t.Fatalf("expected the signed image to be verified, got %v", policy.verified)
+	}
+
+	for tag, config := range map[string]digest.Digest{"unsigned": unsignedConfig, "othersigned": otherSignedConfig, "mismatched": mismatchedConfig} {
+		tagged, _ := reference.WithTag(n, tag)
+		_, err := p.pullV2Tag(ctx, tagged)
+		if _, ok := err.(signatureError); !ok {
+			t.Fatalf("expected a signature error pulling %s, got %v", tag, err)
+		}
+		if continueOnError(err) {
+			t.Fatalf("expected no fallback after a signature error pulling %s", tag)
+		}
+		if _, ok := TranslatePullError(err, tagged).(signatureError); !ok {
+			t.Fatalf("expected the signature error pulling %s not to be translated", tag)
+		}
+		if _, err := imageStore.Get(config); err == nil {
+			t.Fatalf("expected image %s not to be pulled", tag)
+		}
+	}
+	if len(policy.rejected) != 3 {
+		t.Fatalf("expected 3 rejected manifests, got %v", policy.rejected)
+	}
+	for _, d := range []digest.Digest{unsigned.Digest, otherSigned.Digest, mismatched.Digest} {
+		var found bool
+		for _, r := range policy.rejected {
+			found = found || r == d
+		}
+		if !found {
+			t.Fatalf("expected manifest %s to be rejected, got %v", d, policy.rejected)
+		}
+	}
+
+	// Images of repositories which do not require signatures are pulled.
+	policy.keys = nil
+	tagged, _ = reference.WithTag(n, "unsigned")
+	if _, err := p.pullV2Tag(ctx, tagged); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestVerifyPayloadSignatureRSA(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload := []byte(`{"critical":{}}`)
+	hashed := sha256.Sum256(payload)
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hashed[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecdsaKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !verifyPayloadSignature(payload, signature, []crypto.PublicKey{&ecdsaKey.PublicKey, &key.PublicKey}) {
+		t.Fatal("expected the signature to be valid")
+	}
+	if verifyPayloadSignature([]byte(`{}`), signature, []crypto.PublicKey{&key.PublicKey}) {
+		t.Fatal("expected the signature of another payload to be invalid")
+	}
+	if verifyPayloadSignature(payload, signature, []crypto.PublicKey{&ecdsaKey.PublicKey}) {
+		t.Fatal("expected the signature to be invalid for another key")
+	}
+}
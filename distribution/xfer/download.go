@@ -26,6 +26,7 @@ type LayerDownloadManager struct {
 	layerStores  map[string]layer.Store
 	tm           TransferManager
 	waitDuration time.Duration
+	lazyPull     bool
 }
 
 // SetConcurrency sets the max concurrent downloads for each pull
@@ -46,6 +47,15 @@ func NewLayerDownloadManager(layerStores map[string]layer.Store, concurrencyLimi
 	return &manager
 }
 
+// WithLazyPull is an option for NewLayerDownloadManager enabling the
+// experimental lazy pulls: layers whose DiffID is known before they are
+// downloaded are registered as remote-backed, and only downloaded when their
+// content is first needed. This requires a layer store implementing
+// layer.RemoteStore.
+func WithLazyPull(ldm *LayerDownloadManager) {
+	ldm.lazyPull = true
+}
+
 type downloadTransfer struct {
 	Transfer
 
@@ -74,7 +84,8 @@ type DownloadDescriptor interface {
 	Download(ctx context.Context, progressOutput progress.Output) (io.ReadCloser, int64, error)
 	// Close is called when the download manager is finished with this
 	// descriptor and will not call Download again or read from the reader
-	// that Download returned.
+	// that Download returned. With lazy pulls, Download is called again
+	// after Close to fetch the content of a remote-backed layer.
 	Close()
 }
 
@@ -253,6 +264,11 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 				}
 			}
 
+			if rs, diffID, ok := ldm.remoteStore(d.layerStore, descriptor); ok {
+				ldm.registerRemote(d, rs, diffID, descriptor, parentLayer, parentDownload, platform, progressOutput, inactive)
+				return
+			}
+
 			var (
 				downloadReader io.ReadCloser
 				size           int64
@@ -375,6 +391,94 @@ func (ldm *LayerDownloadManager) makeDownloadFunc(descriptor DownloadDescriptor,
 	}
 }
 
+// remoteStore returns the store and the DiffID to register the layer of the
+// descriptor as remote-backed with, when lazy pulls are enabled.
+func (ldm *LayerDownloadManager) remoteStore(ls layer.Store, descriptor DownloadDescriptor) (layer.RemoteStore, layer.DiffID, bool) {
+	if !ldm.lazyPull {
+		return nil, "", false
+	}
+	rs, ok := ls.(layer.RemoteStore)
+	if !ok {
+		return nil, "", false
+	}
+	diffID, err := descriptor.DiffID()
+	if err != nil {
+		return nil, "", false
+	}
+	return rs, diffID, true
+}
+
+// registerRemote registers the layer of the descriptor as remote-backed on
+// top of parentDownload's resulting layer, or of parentLayer, without
+// downloading it. The descriptor is kept to fetch the layer content when it
+// is first needed.
+func (ldm *LayerDownloadManager) registerRemote(d *downloadTransfer, rs layer.RemoteStore, diffID layer.DiffID, descriptor DownloadDescriptor, parentLayer layer.ChainID, parentDownload *downloadTransfer, platform layer.Platform, progressOutput progress.Output, inactive chan<- struct{}) {
+	close(inactive)
+
+	if parentDownload != nil {
+		select {
+		case <-d.Transfer.Context().Done():
+			d.err = errors.New("layer registration cancelled")
+			return
+		case <-parentDownload.Done():
+		}
+
+		l, err := parentDownload.result()
+		if err != nil {
+			d.err = err
+			return
+		}
+		parentLayer = l.ChainID()
+	}
+
+	var src distribution.Descriptor
+	if fs, ok := descriptor.(distribution.Describable); ok {
+		src = fs.Descriptor()
+	}
+	l, err := rs.RegisterRemote(diffID, parentLayer, platform, src, fetchFunc(descriptor))
+	if err != nil {
+		d.err = fmt.Errorf("failed to register layer: %v", err)
+		return
+	}
+	d.layer = l
+
+	progress.Update(progressOutput, descriptor.ID(), "Pull complete (lazy)")
+	if withRegistered, ok := descriptor.(DownloadDescriptorWithRegistered); ok {
+		withRegistered.Registered(diffID)
+	}
+
+	go func() {
+		<-d.Transfer.Released()
+		layer.ReleaseAndLog(d.layerStore, d.layer)
+	}()
+}
+
+// fetchFunc returns a function downloading the content of a remote-backed
+// layer with the descriptor.
+func fetchFunc(descriptor DownloadDescriptor) layer.FetchFunc {
+	return func() (io.ReadCloser, error) {
+		downloadReader, _, err := descriptor.Download(context.Background(), progress.DiscardOutput())
+		if err != nil {
+			descriptor.Close()
+			return nil, err
+		}
+
+		inflatedLayerData, err := archive.DecompressStream(downloadReader)
+		if err != nil {
+			downloadReader.Close()
+			descriptor.Close()
+			return nil, fmt.Errorf("could not get decompression stream: %v", err)
+		}
+
+		return ioutils.NewReadCloserWrapper(inflatedLayerData, func() error {
+			inflatedLayerData.Close()
+			err := downloadReader.Close()
+			descriptor.Close()
+			return err
+		}), nil
+	}
+}
+
 // makeDownloadFuncFromDownload returns a function that performs the layer
 // registration when the layer data is coming from an existing download. It
 // waits for sourceDownload and parentDownload to complete, and then
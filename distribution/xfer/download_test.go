@@ -2,10 +2,13 @@ package xfer
 
 import (
 	"bytes"
+	"compress/gzip"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"runtime"
 	"sync/atomic"
 	"testing"
@@ -27,9 +30,21 @@ type mockLayer struct {
 	chainID   layer.ChainID
 	parent    layer.Layer
 	platform  layer.Platform
+	fetch     layer.FetchFunc
 }
 
 func (ml *mockLayer) TarStream() (io.ReadCloser, error) {
+	if ml.fetch != nil {
+		rc, err := ml.fetch()
+		if err != nil {
+			return nil, err
+		}
+		defer rc.Close()
+		if _, err := ml.layerData.ReadFrom(rc); err != nil {
+			return nil, err
+		}
+		ml.fetch = nil
+	}
 	return ioutil.NopCloser(bytes.NewBuffer(ml.layerData.Bytes())), nil
 }
 
@@ -158,6 +173,34 @@ func (ls *mockLayerStore) DriverName() string {
 	return "mock"
 }
 
+type mockRemoteLayerStore struct {
+	mockLayerStore
+}
+
+func (ls *mockRemoteLayerStore) RegisterRemote(diffID layer.DiffID, parentID layer.ChainID, _ layer.Platform, _ distribution.Descriptor, fetch layer.FetchFunc) (layer.Layer, error) {
+	var (
+		parent layer.Layer
+		err    error
+	)
+
+	if parentID != "" {
+		parent, err = ls.Get(parentID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	l := &mockLayer{
+		parent:  parent,
+		diffID:  diffID,
+		chainID: createChainIDFromParent(parentID, diffID),
+		fetch:   fetch,
+	}
+
+	ls.layers[l.chainID] = l
+	return l, nil
+}
+
 type mockDownloadDescriptor struct {
 	currentDownloads *int32
 	id               string
@@ -365,3 +408,124 @@ func TestCancelledDownload(t *testing.T) {
 	close(progressChan)
 	<-progressDone
 }
+
+// blobDescriptor downloads a gzipped layer from a blob server.
+type blobDescriptor struct {
+	url    string
+	diffID layer.DiffID
+}
+
+func (d *blobDescriptor) Key() string {
+	return d.url
+}
+
+func (d *blobDescriptor) ID() string {
+	return d.url
+}
+
+func (d *blobDescriptor) DiffID() (layer.DiffID, error) {
+	return d.diffID, nil
+}
+
+func (d *blobDescriptor) Download(ctx context.Context, progressOutput progress.Output) (io.ReadCloser, int64, error) {
+	resp, err := http.Get(d.url)
+	if err != nil {
+		return nil, 0, err
+	}
+	return resp.Body, resp.ContentLength, nil
+}
+
+func (d *blobDescriptor) Close() {
+}
+
+func TestLazyDownload(t *testing.T) {
+	var requests int32
+	blobs := map[string][]byte{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		blob, ok := blobs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write(blob)
+	}))
+	defer server.Close()
+
+	var descriptors []DownloadDescriptor
+	layerData := map[layer.DiffID][]byte{}
+	for _, id := range []string{"id1", "id2"} {
+		data := []byte(id + id + id + id + id)
+		var blob bytes.Buffer
+		gz := gzip.NewWriter(&blob)
+		gz.Write(data)
+		gz.Close()
+		blobs["/"+id] = blob.Bytes()
+
+		diffID := layer.DiffID(digest.FromBytes(data))
+		layerData[diffID] = data
+		descriptors = append(descriptors, &blobDescriptor{url: server.URL + "/" + id, diffID: diffID})
+	}
+
+	layerStore := &mockRemoteLayerStore{mockLayerStore{make(map[layer.ChainID]*mockLayer)}}
+	lsMap := map[string]layer.Store{runtime.GOOS: layerStore}
+	ldm := NewLayerDownloadManager(lsMap, maxDownloadConcurrency, WithLazyPull)
+
+	progressChan := make(chan progress.Progress)
+	progressDone := make(chan struct{})
+	receivedProgress := make(map[string]progress.Progress)
+
+	go func() {
+		for p := range progressChan {
+			receivedProgress[p.ID] = p
+		}
+		close(progressDone)
+	}()
+
+	rootFS, releaseFunc, err := ldm.Download(context.Background(), *image.NewRootFS(), layer.Platform(runtime.GOOS), descriptors, progress.ChanOutput(progressChan))
+	if err != nil {
+		t.Fatalf("download error: %v", err)
+	}
+	releaseFunc()
+
+	close(progressChan)
+	<-progressDone
+
+	if n := atomic.LoadInt32(&requests); n != 0 {
+		t.Fatalf("expected no blob request before the layers are used, got %d", n)
+	}
+	for _, d := range descriptors {
+		if receivedProgress[d.ID()].Action != "Pull complete (lazy)" {
+			t.Fatalf("did not get 'Pull complete (lazy)' message for %v", d.ID())
+		}
+	}
+
+	for i, diffID := range rootFS.DiffIDs {
+		if diffID != descriptors[i].(*blobDescriptor).diffID {
+			t.Fatalf("rootFS item %d has the wrong diffID (expected: %v got: %v)", i, descriptors[i].(*blobDescriptor).diffID, diffID)
+		}
+
+		l, err := layerStore.Get(rootFS.ChainID())
+		if err != nil {
+			t.Fatal(err)
+		}
+		for l.DiffID() != diffID {
+			l = l.Parent()
+		}
+		rc, err := l.TarStream()
+		if err != nil {
+			t.Fatal(err)
+		}
+		data, err := ioutil.ReadAll(rc)
+		rc.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(data, layerData[diffID]) {
+			t.Fatalf("wrong content fetched for layer %d: %q", i, data)
+		}
+		if n := atomic.LoadInt32(&requests); n != int32(i+1) {
+			t.Fatalf("expected %d blob requests, got %d", i+1, n)
+		}
+	}
+}
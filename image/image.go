@@ -11,6 +11,7 @@ import (
 	"github.com/docker/docker/api/types/container"
 	"github.com/docker/docker/dockerversion"
 	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
 	"github.com/opencontainers/go-digest"
 )
 
@@ -204,6 +205,8 @@ type Exporter interface {
 	Load(io.ReadCloser, io.Writer, bool) error
 	// TODO: Load(net.Context, io.ReadCloser, <- chan StatusMessage) error
 	Save([]string, io.Writer) error
+	// SaveOCILayout saves images in the OCI image layout format.
+	SaveOCILayout([]string, archive.Compression, io.Writer) error
 }
 
 // NewFromJSON creates an Image configuration from json.
@@ -25,6 +25,10 @@ type Store interface {
 	GetParent(id ID) (ID, error)
 	SetLastUpdated(id ID) error
 	GetLastUpdated(id ID) (time.Time, error)
+	SetLastUsed(id ID) error
+	GetLastUsed(id ID) (time.Time, error)
+	AddSignedRepository(id ID, repository string) error
+	GetSignedRepositories(id ID) ([]string, error)
 	Children(id ID) []ID
 	Map() map[ID]*Image
 	Heads() map[ID]*Image
@@ -78,6 +82,16 @@ func (is *store) restore() error {
 		var l layer.Layer
 		if chainID := img.RootFS.ChainID(); chainID != "" {
 			l, err = is.ls.Get(chainID)
+			if err == layer.ErrLayerDoesNotExist {
+				// The layers of a lazily pulled image which were never
+				// fetched are not kept across restarts. Remove the image
+				// so that it is pulled again.
+				logrus.Warnf("Removing image %v: its layer %v does not exist", dgst, chainID)
+				if err := is.fs.Delete(dgst); err != nil {
+					return err
+				}
+				return nil
+			}
 			if err != nil {
 				return err
 			}
@@ -286,6 +300,67 @@ func (is *store) GetLastUpdated(id ID) (time.Time, error) {
 	return time.Parse(time.RFC3339Nano, string(bytes))
 }
 
+// SetLastUsed time for the image ID to the current time
+func (is *store) SetLastUsed(id ID) error {
+	lastUsed := []byte(time.Now().Format(time.RFC3339Nano))
+	return is.fs.SetMetadata(id.Digest(), "lastUsed", lastUsed)
+}
+
+// GetLastUsed time for the image ID, the last time it was used to create a
+// container or to build an image
+func (is *store) GetLastUsed(id ID) (time.Time, error) {
+	bytes, err := is.fs.GetMetadata(id.Digest(), "lastUsed")
+	if err != nil || len(bytes) == 0 {
+		// Never used
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339Nano, string(bytes))
+}
+
+// AddSignedRepository records that the signature of the image ID was
+// verified when it was pulled from repository
+func (is *store) AddSignedRepository(id ID, repository string) error {
+	is.Lock()
+	defer is.Unlock()
+
+	repositories, err := is.getSignedRepositories(id)
+	if err != nil {
+		return err
+	}
+	for _, r := range repositories {
+		if r == repository {
+			return nil
+		}
+	}
+	b, err := json.Marshal(append(repositories, repository))
+	if err != nil {
+		return err
+	}
+	return is.fs.SetMetadata(id.Digest(), "signedRepositories", b)
+}
+
+// GetSignedRepositories returns the repositories the image ID was pulled
+// from with a verified signature
+func (is *store) GetSignedRepositories(id ID) ([]string, error) {
+	is.RLock()
+	defer is.RUnlock()
+
+	return is.getSignedRepositories(id)
+}
+
+func (is *store) getSignedRepositories(id ID) ([]string, error) {
+	b, err := is.fs.GetMetadata(id.Digest(), "signedRepositories")
+	if err != nil || len(b) == 0 {
+		// No verified signature
+		return nil, nil
+	}
+	var repositories []string
+	if err := json.Unmarshal(b, &repositories); err != nil {
+		return nil, err
+	}
+	return repositories, nil
+}
+
 func (is *store) Children(id ID) []ID {
 	is.RLock()
 	defer is.RUnlock()
@@ -66,6 +66,29 @@ func TestRestore(t *testing.T) {
 	testutil.ErrorContains(t, err, "No such image")
 }
 
+func TestRestoreMissingLayer(t *testing.T) {
+	fs, cleanup := defaultFSStoreBackend(t)
+	defer cleanup()
+
+	id1, err := fs.Set([]byte(`{"comment": "abc", "rootfs": {"type": "layers", "diff_ids": ["2c26b46b68ffc68ff99b453c1d30413413422d706483bfa0f98a5e886266e7ae"]}}`))
+	assert.NoError(t, err)
+
+	id2, err := fs.Set([]byte(`{"comment": "def", "rootfs": {"type": "layers", "diff_ids": ["fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"]}}`))
+	assert.NoError(t, err)
+
+	ls := &mockLayerGetReleaser{missing: map[layer.ChainID]struct{}{
+		layer.ChainID("fcde2b2edba56bf408601fb721fe9b5c338d10ee429ea04fae5511b68fbf8fb9"): {},
+	}}
+	is, err := NewImageStore(fs, runtime.GOOS, ls)
+	assert.NoError(t, err)
+
+	assert.Len(t, is.Map(), 1)
+	_, err = is.Get(ID(id1))
+	assert.NoError(t, err)
+	_, err = is.Get(ID(id2))
+	assert.Error(t, err)
+}
+
 func TestAddDelete(t *testing.T) {
 	is, cleanup := defaultImageStore(t)
 	defer cleanup()
@@ -167,9 +190,56 @@ func TestGetAndSetLastUpdated(t *testing.T) {
 	assert.Equal(t, updated.IsZero(), false)
 }
 
-type mockLayerGetReleaser struct{}
+func TestGetAndSetLastUsed(t *testing.T) {
+	store, cleanup := defaultImageStore(t)
+	defer cleanup()
+
+	id, err := store.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	assert.NoError(t, err)
+
+	used, err := store.GetLastUsed(id)
+	assert.NoError(t, err)
+	assert.Equal(t, used.IsZero(), true)
+
+	assert.NoError(t, store.SetLastUsed(id))
+
+	used, err = store.GetLastUsed(id)
+	assert.NoError(t, err)
+	assert.Equal(t, used.IsZero(), false)
+
+	updated, err := store.GetLastUpdated(id)
+	assert.NoError(t, err)
+	assert.Equal(t, updated.IsZero(), true)
+}
+
+func TestAddAndGetSignedRepositories(t *testing.T) {
+	store, cleanup := defaultImageStore(t)
+	defer cleanup()
+
+	id, err := store.Create([]byte(`{"comment": "abc1", "rootfs": {"type": "layers"}}`))
+	assert.NoError(t, err)
+
+	repositories, err := store.GetSignedRepositories(id)
+	assert.NoError(t, err)
+	assert.Len(t, repositories, 0)
+
+	assert.NoError(t, store.AddSignedRepository(id, "docker.io/myorg/app"))
+	assert.NoError(t, store.AddSignedRepository(id, "docker.io/myorg/other"))
+	assert.NoError(t, store.AddSignedRepository(id, "docker.io/myorg/app"))
+
+	repositories, err = store.GetSignedRepositories(id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"docker.io/myorg/app", "docker.io/myorg/other"}, repositories)
+}
+
+type mockLayerGetReleaser struct {
+	missing map[layer.ChainID]struct{}
+}
 
-func (ls *mockLayerGetReleaser) Get(layer.ChainID) (layer.Layer, error) {
+func (ls *mockLayerGetReleaser) Get(chainID layer.ChainID) (layer.Layer, error) {
+	if _, ok := ls.missing[chainID]; ok {
+		return nil, layer.ErrLayerDoesNotExist
+	}
 	return nil, nil
 }
 
@@ -24,6 +24,7 @@ import (
 	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
 	digest "github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 )
 
@@ -51,6 +52,9 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer, quiet bool)
 	manifestFile, err := os.Open(manifestPath)
 	if err != nil {
 		if os.IsNotExist(err) {
+			if isOCILayout(tmpDir) {
+				return l.ociLoad(tmpDir, outStream, progressOutput)
+			}
 			return l.legacyLoad(tmpDir, outStream, progressOutput)
 		}
 		return err
@@ -89,15 +93,9 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer, quiet bool)
 			return fmt.Errorf("invalid manifest, layers length mismatch: expected %d, got %d", expected, actual)
 		}
 
-		// On Windows, validate the platform, defaulting to windows if not present.
-		platform := layer.Platform(img.OS)
-		if runtime.GOOS == "windows" {
-			if platform == "" {
-				platform = "windows"
-			}
-			if (platform != "windows") && (platform != "linux") {
-				return fmt.Errorf("configuration for this image has an unsupported platform: %s", platform)
-			}
+		platform, err := imagePlatform(img)
+		if err != nil {
+			return err
 		}
 
 		for i, diffID := range img.RootFS.DiffIDs {
@@ -161,6 +159,30 @@ func (l *tarexporter) Load(inTar io.ReadCloser, outStream io.Writer, quiet bool)
 	return nil
 }
 
+// imagePlatform returns the platform of the layers of img. On Windows, it
+// validates the platform, defaulting to windows if not present.
+func imagePlatform(img *image.Image) (layer.Platform, error) {
+	platform := layer.Platform(img.OS)
+	if runtime.GOOS == "windows" {
+		if platform == "" {
+			platform = "windows"
+		}
+		if (platform != "windows") && (platform != "linux") {
+			return "", fmt.Errorf("configuration for this image has an unsupported platform: %s", platform)
+		}
+	}
+	return platform, nil
+}
+
+// isOCILayout returns whether dir holds an OCI image layout.
+func isOCILayout(dir string) bool {
+	var layout ocispec.ImageLayout
+	if err := readOCIJSONFile(dir, ocispec.ImageLayoutFile, &layout); err != nil {
+		return false
+	}
+	return layout.Version != ""
+}
+
 func (l *tarexporter) setParentID(id, parentID image.ID) error {
 	img, err := l.is.Get(id)
 	if err != nil {
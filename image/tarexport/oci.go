@@ -0,0 +1,603 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/docker/distribution"
+	"github.com/docker/distribution/manifest/manifestlist"
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/distribution/ocischema"
+	"github.com/docker/docker/image"
+	"github.com/docker/docker/layer"
+	"github.com/docker/docker/pkg/archive"
+	"github.com/docker/docker/pkg/progress"
+	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ociIndexFileName = "index.json"
+	ociBlobsDirName  = "blobs"
+
+	// containerdImageNameAnnotation is the annotation containerd uses for
+	// the full name of an image in an image layout, the image ref name
+	// annotation only holding its tag.
+	containerdImageNameAnnotation = "io.containerd.image.name"
+
+	// The annotations of a saved foreign layer hold the descriptor it was
+	// pulled with: its saved blob is compressed again, so it does not have
+	// the digest of the blob its URLs refer to.
+	foreignDigestAnnotation = "com.docker.image.foreign.digest"
+	foreignSizeAnnotation   = "com.docker.image.foreign.size"
+	foreignURLsAnnotation   = "com.docker.image.foreign.urls"
+)
+
+type ociSaveSession struct {
+	*tarexporter
+	compression archive.Compression
+	outDir      string
+	images      map[image.ID]*imageDescriptor
+	savedLayers map[layer.DiffID]ocispec.Descriptor
+}
+
+// SaveOCILayout saves the images in the OCI image layout format. Each tag of
+// an image is an entry of the index of the layout, annotated with the tag and
+// the full reference of the tag. Layers are stored compressed with
+// compression, either gzip or zstd.
+//
+// The daemon does not keep the compressed blobs of the layers it pulls, so
+// the layers are compressed again, the way a push compresses them. A layer
+// pushed from this daemon with the same compression keeps the digest it has
+// in the registry, but a layer compressed by another tool generally gets a
+// different digest: its registry digest is only kept if the compressed blob
+// is the same, which is checked against the digests known from pulls and
+// pushes.
+func (l *tarexporter) SaveOCILayout(names []string, compression archive.Compression, outStream io.Writer) error {
+	if compression != archive.Gzip && compression != archive.Zstd {
+		return errors.Errorf("unsupported layer compression %s", compression.Extension())
+	}
+	images, err := l.parseNames(names)
+	if err != nil {
+		return err
+	}
+
+	// Release all the image top layer references
+	defer l.releaseLayerReferences(images)
+	return (&ociSaveSession{tarexporter: l, compression: compression, images: images}).save(outStream)
+}
+
+func (s *ociSaveSession) save(outStream io.Writer) error {
+	s.savedLayers = make(map[layer.DiffID]ocispec.Descriptor)
+
+	tempDir, err := ioutil.TempDir("", "docker-export-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tempDir)
+	s.outDir = tempDir
+
+	if err := os.MkdirAll(filepath.Join(tempDir, ociBlobsDirName, string(digest.Canonical)), 0755); err != nil {
+		return err
+	}
+
+	// Save the images in a stable order, for the index to be reproducible
+	var ids []image.ID
+	for id := range s.images {
+		ids = append(ids, id)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	index := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+	}
+	for _, id := range ids {
+		desc, err := s.saveImage(id)
+		if err != nil {
+			return err
+		}
+
+		refs := s.images[id].refs
+		if len(refs) == 0 {
+			index.Manifests = append(index.Manifests, desc)
+		}
+		for _, ref := range refs {
+			d := desc
+			d.Annotations = ociRefAnnotations(ref)
+			index.Manifests = append(index.Manifests, d)
+		}
+		s.tarexporter.loggerImgEvent.LogImageEvent(id.String(), id.String(), "save")
+	}
+
+	layout := ocispec.ImageLayout{Version: ocispec.ImageLayoutVersion}
+	if err := writeJSONFile(filepath.Join(tempDir, ocispec.ImageLayoutFile), layout); err != nil {
+		return err
+	}
+	if err := writeJSONFile(filepath.Join(tempDir, ociIndexFileName), index); err != nil {
+		return err
+	}
+
+	fs, err := archive.Tar(tempDir, archive.Uncompressed)
+	if err != nil {
+		return err
+	}
+	defer fs.Close()
+
+	_, err = io.Copy(outStream, fs)
+	return err
+}
+
+// saveImage saves the config, layers and manifest of the image as blobs,
+// and returns the descriptor of the manifest.
+func (s *ociSaveSession) saveImage(id image.ID) (ocispec.Descriptor, error) {
+	img := s.images[id].image
+	if len(img.RootFS.DiffIDs) == 0 {
+		return ocispec.Descriptor{}, fmt.Errorf("empty export - not implemented")
+	}
+
+	config, err := s.writeBlob(img.RawJSON(), img.Created)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	config.MediaType = ocispec.MediaTypeImageConfig
+
+	manifest := ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Config:    config,
+	}
+	for i := range img.RootFS.DiffIDs {
+		rootFS := *img.RootFS
+		rootFS.DiffIDs = rootFS.DiffIDs[:i+1]
+		desc, err := s.saveLayer(rootFS.ChainID(), img.Created)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+
+	b, err := json.Marshal(manifest)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc, err := s.writeBlob(b, img.Created)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	desc.MediaType = ocispec.MediaTypeImageManifest
+	desc.Platform = &ocispec.Platform{
+		Architecture: img.Architecture,
+		OS:           img.Platform(),
+	}
+	return desc, nil
+}
+
+// saveLayer saves the layer as a compressed blob, and returns its
+// descriptor. Foreign layers are saved too, as the legacy format does, so
+// that they can be loaded on hosts which do not have them. They are saved
+// as non-distributable layers, annotated with the descriptor they were
+// pulled with.
+func (s *ociSaveSession) saveLayer(id layer.ChainID, createdTime time.Time) (ocispec.Descriptor, error) {
+	l, err := s.ls.Get(id)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer layer.ReleaseAndLog(s.ls, l)
+
+	if desc, exists := s.savedLayers[l.DiffID()]; exists {
+		return desc, nil
+	}
+
+	var foreignSrc distribution.Descriptor
+	if fs, ok := l.(distribution.Describable); ok {
+		foreignSrc = fs.Descriptor()
+	}
+
+	arch, err := l.TarStream()
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer arch.Close()
+
+	// Use system.CreateSequential rather than os.Create. This ensures sequential
+	// file access on Windows to avoid eating into MM standby list.
+	// On Linux, this equates to a regular os.Create.
+	tmpPath := filepath.Join(s.outDir, ociBlobsDirName, "layer-"+digest.Digest(l.DiffID()).Hex())
+	f, err := system.CreateSequential(tmpPath)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	defer f.Close()
+
+	digester := digest.Canonical.Digester()
+	counter := &countingWriter{}
+	w := io.MultiWriter(f, digester.Hash(), counter)
+	// Compress the layer as a push does, for the layers pushed from this
+	// daemon to keep their digest.
+	compressor, err := archive.CompressStream(w, s.compression)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	mediaType := ocispec.MediaTypeImageLayerGzip
+	if s.compression == archive.Zstd {
+		mediaType = ocischema.MediaTypeImageLayerZstd
+	}
+	if foreignSrc.Digest != "" {
+		mediaType = ocispec.MediaTypeImageLayerNonDistributableGzip
+		if s.compression == archive.Zstd {
+			mediaType = ocischema.MediaTypeImageLayerNonDistributableZstd
+		}
+	}
+	if _, err := io.Copy(compressor, arch); err != nil {
+		compressor.Close()
+		return ocispec.Descriptor{}, err
+	}
+	if err := compressor.Close(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := f.Close(); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digester.Digest(),
+		Size:      counter.n,
+	}
+	blobPath := ociBlobPath(s.outDir, desc.Digest)
+	if err := os.Rename(tmpPath, blobPath); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := system.Chtimes(blobPath, createdTime, createdTime); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if foreignSrc.Digest != "" {
+		desc.Annotations = map[string]string{
+			foreignDigestAnnotation: foreignSrc.Digest.String(),
+			foreignSizeAnnotation:   strconv.FormatInt(foreignSrc.Size, 10),
+			foreignURLsAnnotation:   strings.Join(foreignSrc.URLs, " "),
+		}
+	} else {
+		s.checkRegistryDigest(l.DiffID(), desc.Digest)
+	}
+	s.savedLayers[l.DiffID()] = desc
+	return desc, nil
+}
+
+// checkRegistryDigest logs whether dgst, the digest a layer is saved with,
+// is the digest of the layer in the registries it was pulled from or pushed
+// to.
+func (s *ociSaveSession) checkRegistryDigest(diffID layer.DiffID, dgst digest.Digest) {
+	if s.v2MetadataService == nil {
+		return
+	}
+	v2Metadata, err := s.v2MetadataService.GetMetadata(diffID)
+	if err != nil || len(v2Metadata) == 0 {
+		return
+	}
+	for _, meta := range v2Metadata {
+		if meta.Digest == dgst {
+			logrus.Debugf("Layer %s is saved with its registry digest %s", diffID, dgst)
+			return
+		}
+	}
+	logrus.Infof("Layer %s is saved with digest %s, which differs from its registry digest %s: its registry blob was compressed differently", diffID, dgst, v2Metadata[0].Digest)
+}
+
+// writeBlob writes b as a blob, and returns its descriptor.
+func (s *ociSaveSession) writeBlob(b []byte, createdTime time.Time) (ocispec.Descriptor, error) {
+	desc := ocispec.Descriptor{
+		Digest: digest.Canonical.FromBytes(b),
+		Size:   int64(len(b)),
+	}
+	blobPath := ociBlobPath(s.outDir, desc.Digest)
+	if err := ioutil.WriteFile(blobPath, b, 0644); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if err := system.Chtimes(blobPath, createdTime, createdTime); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	return desc, nil
+}
+
+func writeJSONFile(path string, v interface{}) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if err := ioutil.WriteFile(path, b, 0644); err != nil {
+		return err
+	}
+	return system.Chtimes(path, time.Unix(0, 0), time.Unix(0, 0))
+}
+
+func ociBlobPath(dir string, dgst digest.Digest) string {
+	return filepath.Join(dir, ociBlobsDirName, dgst.Algorithm().String(), dgst.Hex())
+}
+
+type countingWriter struct {
+	n int64
+}
+
+func (w *countingWriter) Write(p []byte) (int, error) {
+	w.n += int64(len(p))
+	return len(p), nil
+}
+
+// ociLoad loads the images of the OCI image layout extracted in tmpDir, and
+// tags them after the reference names of the entries of its index.
+func (l *tarexporter) ociLoad(tmpDir string, outStream io.Writer, progressOutput progress.Output) error {
+	var index ocispec.Index
+	if err := readOCIJSONFile(tmpDir, ociIndexFileName, &index); err != nil {
+		return err
+	}
+
+	var imageIDsStr string
+	var imageRefCount int
+	loaded := make(map[digest.Digest]image.ID)
+
+	for _, desc := range index.Manifests {
+		manifestDesc, err := l.ociResolveManifest(tmpDir, desc)
+		if err != nil {
+			return err
+		}
+
+		imgID, ok := loaded[manifestDesc.Digest]
+		if !ok {
+			imgID, err = l.ociLoadImage(tmpDir, manifestDesc, progressOutput)
+			if err != nil {
+				return err
+			}
+			loaded[manifestDesc.Digest] = imgID
+			imageIDsStr += fmt.Sprintf("Loaded image ID: %s\n", imgID)
+			l.loggerImgEvent.LogImageEvent(imgID.String(), imgID.String(), "load")
+		}
+
+		ref := ociRefName(desc.Annotations)
+		if ref == nil {
+			continue
+		}
+		l.setLoadedTag(ref, imgID.Digest(), outStream)
+		outStream.Write([]byte(fmt.Sprintf("Loaded image: %s\n", reference.FamiliarString(ref))))
+		imageRefCount++
+	}
+
+	if imageRefCount == 0 {
+		outStream.Write([]byte(imageIDsStr))
+	}
+	return nil
+}
+
+// ociResolveManifest returns the descriptor of the manifest of the image
+// for this platform that desc refers to. desc can refer to a manifest, or
+// to an index of manifests for different platforms.
+func (l *tarexporter) ociResolveManifest(dir string, desc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, schema2.MediaTypeManifest:
+		return desc, nil
+	case ocispec.MediaTypeImageIndex, manifestlist.MediaTypeManifestList:
+	default:
+		return ocispec.Descriptor{}, errors.Errorf("unsupported media type %s for %s", desc.MediaType, desc.Digest)
+	}
+
+	b, err := readOCIBlob(dir, desc)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(b, &index); err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	for _, m := range index.Manifests {
+		if m.Platform != nil && (checkCompatibleOS(m.Platform.OS) != nil || m.Platform.Architecture != runtime.GOARCH) {
+			continue
+		}
+		blobPath, err := ociBlobSafePath(dir, m.Digest)
+		if err != nil {
+			return ocispec.Descriptor{}, err
+		}
+		if _, err := os.Stat(blobPath); err != nil {
+			// Image layouts may only hold the images of some platforms
+			continue
+		}
+		return l.ociResolveManifest(dir, m)
+	}
+	return ocispec.Descriptor{}, errors.Errorf("no image for %s/%s in index %s", runtime.GOOS, runtime.GOARCH, desc.Digest)
+}
+
+// ociLoadImage loads the layers and config of the image with the given
+// manifest, and returns the ID of the image.
+func (l *tarexporter) ociLoadImage(dir string, desc ocispec.Descriptor, progressOutput progress.Output) (image.ID, error) {
+	b, err := readOCIBlob(dir, desc)
+	if err != nil {
+		return "", err
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(b, &manifest); err != nil {
+		return "", err
+	}
+
+	config, err := readOCIBlob(dir, manifest.Config)
+	if err != nil {
+		return "", err
+	}
+	img, err := image.NewFromJSON(config)
+	if err != nil {
+		return "", err
+	}
+	if err := checkCompatibleOS(img.OS); err != nil {
+		return "", err
+	}
+	if expected, actual := len(manifest.Layers), len(img.RootFS.DiffIDs); expected != actual {
+		return "", fmt.Errorf("invalid manifest, layers length mismatch: expected %d, got %d", expected, actual)
+	}
+	platform, err := imagePlatform(img)
+	if err != nil {
+		return "", err
+	}
+
+	rootFS := *img.RootFS
+	rootFS.DiffIDs = nil
+	for i, diffID := range img.RootFS.DiffIDs {
+		r := rootFS
+		r.Append(diffID)
+		newLayer, err := l.ls.Get(r.ChainID())
+		if err != nil {
+			layerDesc := manifest.Layers[i]
+			blobPath, err := ociBlobSafePath(dir, layerDesc.Digest)
+			if err != nil {
+				return "", err
+			}
+			foreignSrc, err := ociForeignSource(layerDesc)
+			if err != nil {
+				return "", err
+			}
+			if _, err := os.Stat(blobPath); os.IsNotExist(err) && foreignSrc.Digest != "" {
+				return "", errors.Errorf("foreign layer %s is not in the archive, and does not exist on this host", diffID)
+			}
+			newLayer, err = l.loadLayer(blobPath, rootFS, diffID.String(), platform, foreignSrc, progressOutput)
+			if err != nil {
+				return "", err
+			}
+		}
+		defer layer.ReleaseAndLog(l.ls, newLayer)
+		if expected, actual := diffID, newLayer.DiffID(); expected != actual {
+			return "", fmt.Errorf("invalid diffID for layer %d: expected %q, got %q", i, expected, actual)
+		}
+		rootFS.Append(diffID)
+	}
+
+	return l.is.Create(config)
+}
+
+func isNonDistributable(mediaType string) bool {
+	return strings.Contains(mediaType, ".nondistributable.") || mediaType == schema2.MediaTypeForeignLayer
+}
+
+// ociForeignSource returns the descriptor a non-distributable layer of an
+// image layout is registered with, or an empty descriptor for other layers.
+// The descriptor is taken from the annotations of the layers saved by the
+// daemon, and from the layer descriptor otherwise.
+func ociForeignSource(desc ocispec.Descriptor) (distribution.Descriptor, error) {
+	if !isNonDistributable(desc.MediaType) {
+		return distribution.Descriptor{}, nil
+	}
+	src := distribution.Descriptor{
+		MediaType: schema2.MediaTypeForeignLayer,
+		Digest:    desc.Digest,
+		Size:      desc.Size,
+		URLs:      desc.URLs,
+	}
+	if d, ok := desc.Annotations[foreignDigestAnnotation]; ok {
+		dgst, err := digest.Parse(d)
+		if err != nil {
+			return distribution.Descriptor{}, errors.Wrapf(err, "invalid foreign layer digest %q", d)
+		}
+		size, err := strconv.ParseInt(desc.Annotations[foreignSizeAnnotation], 10, 64)
+		if err != nil {
+			return distribution.Descriptor{}, errors.Wrapf(err, "invalid foreign layer size for %s", dgst)
+		}
+		src.Digest = dgst
+		src.Size = size
+		src.URLs = strings.Fields(desc.Annotations[foreignURLsAnnotation])
+	}
+	return src, nil
+}
+
+// ociRefAnnotations returns the annotations naming an entry of the index of
+// an image layout after ref: the image ref name annotation only holds the
+// tag, as specified by the image layout, and the full reference is held by
+// the annotation containerd uses.
+func ociRefAnnotations(ref reference.NamedTagged) map[string]string {
+	return map[string]string{
+		ocispec.AnnotationRefName:     ref.Tag(),
+		containerdImageNameAnnotation: ref.String(),
+	}
+}
+
+// ociRefName returns the reference an entry of the index of an image layout
+// is named after, or nil if it has no name that can be used as a tag. Only
+// full references can be used: a name made of a tag alone does not say
+// which repository the image belongs to.
+func ociRefName(annotations map[string]string) reference.NamedTagged {
+	name := annotations[containerdImageNameAnnotation]
+	if name == "" {
+		name = annotations[ocispec.AnnotationRefName]
+	}
+	if name == "" || !strings.ContainsAny(name, ":/") {
+		return nil
+	}
+
+	named, err := reference.ParseNormalizedNamed(name)
+	if err != nil {
+		logrus.Warnf("Ignoring invalid image name %q: %v", name, err)
+		return nil
+	}
+	if _, ok := named.(reference.Canonical); ok {
+		return nil
+	}
+	tagged, ok := reference.TagNameOnly(named).(reference.NamedTagged)
+	if !ok {
+		return nil
+	}
+	return tagged
+}
+
+// ociBlobSafePath returns the path of the blob with digest dgst in the
+// image layout extracted to dir. The digest comes from the layout, so it is
+// validated before it is used.
+func ociBlobSafePath(dir string, dgst digest.Digest) (string, error) {
+	if err := dgst.Validate(); err != nil {
+		return "", errors.Wrapf(err, "invalid blob digest %q", dgst)
+	}
+	return safePath(dir, filepath.Join(ociBlobsDirName, dgst.Algorithm().String(), dgst.Hex()))
+}
+
+// readOCIBlob reads the blob desc refers to, and verifies its digest.
+func readOCIBlob(dir string, desc ocispec.Descriptor) ([]byte, error) {
+	blobPath, err := ociBlobSafePath(dir, desc.Digest)
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(blobPath)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	verifier := desc.Digest.Verifier()
+	b, err := ioutil.ReadAll(io.TeeReader(f, verifier))
+	if err != nil {
+		return nil, err
+	}
+	if !verifier.Verified() {
+		return nil, errors.Errorf("digest mismatch for blob %s", desc.Digest)
+	}
+	return b, nil
+}
+
+func readOCIJSONFile(dir, name string, v interface{}) error {
+	p, err := safePath(dir, name)
+	if err != nil {
+		return err
+	}
+	f, err := os.Open(p)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	return json.NewDecoder(f).Decode(v)
+}
@@ -0,0 +1,178 @@
+package tarexport
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/docker/distribution/manifest/schema2"
+	"github.com/docker/distribution/reference"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestOCIRefName(t *testing.T) {
+	tests := []struct {
+		annotations map[string]string
+		expected    string
+	}{
+		{annotations: nil},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "latest"}},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "docker.io/library/busybox:1.27"}, expected: "docker.io/library/busybox:1.27"},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "busybox:1.27"}, expected: "docker.io/library/busybox:1.27"},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "example.com/app"}, expected: "example.com/app:latest"},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "busybox@sha256:" + strings.Repeat("a", 64)}},
+		{annotations: map[string]string{ocispec.AnnotationRefName: "Invalid/Name:tag"}},
+		{
+			annotations: map[string]string{
+				ocispec.AnnotationRefName:     "1.27",
+				containerdImageNameAnnotation: "docker.io/library/busybox:1.27",
+			},
+			expected: "docker.io/library/busybox:1.27",
+		},
+	}
+
+	for _, tc := range tests {
+		ref := ociRefName(tc.annotations)
+		var actual string
+		if ref != nil {
+			actual = ref.String()
+		}
+		if actual != tc.expected {
+			t.Errorf("%v: expected %q, got %q", tc.annotations, tc.expected, actual)
+		}
+	}
+}
+
+func TestOCIRefAnnotations(t *testing.T) {
+	named, err := reference.ParseNormalizedNamed("busybox:1.27")
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := named.(reference.NamedTagged)
+
+	annotations := ociRefAnnotations(ref)
+	if name := annotations[ocispec.AnnotationRefName]; name != "1.27" {
+		t.Fatalf("expected the ref name to be the tag, got %q", name)
+	}
+	if loaded := ociRefName(annotations); loaded == nil || loaded.String() != "docker.io/library/busybox:1.27" {
+		t.Fatalf("expected the reference to round trip, got %v", loaded)
+	}
+}
+
+func writeTestBlob(t *testing.T, dir string, v interface{}) ocispec.Descriptor {
+	b, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	desc := ocispec.Descriptor{
+		Digest: digest.FromBytes(b),
+		Size:   int64(len(b)),
+	}
+	if err := ioutil.WriteFile(ociBlobPath(dir, desc.Digest), b, 0644); err != nil {
+		t.Fatal(err)
+	}
+	return desc
+}
+
+func TestOCIResolveManifest(t *testing.T) {
+	dir, err := ioutil.TempDir("", "docker-oci-layout-test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, ociBlobsDirName, "sha256"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	other := writeTestBlob(t, dir, ocispec.Manifest{Versioned: specs.Versioned{SchemaVersion: 2}, Annotations: map[string]string{"platform": "other"}})
+	other.MediaType = ocispec.MediaTypeImageManifest
+	other.Platform = &ocispec.Platform{OS: runtime.GOOS, Architecture: "unknown"}
+	native := writeTestBlob(t, dir, ocispec.Manifest{Versioned: specs.Versioned{SchemaVersion: 2}})
+	native.MediaType = ocispec.MediaTypeImageManifest
+	native.Platform = &ocispec.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH}
+	missing := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("missing"),
+		Platform:  &ocispec.Platform{OS: runtime.GOOS, Architecture: runtime.GOARCH},
+	}
+	index := writeTestBlob(t, dir, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []ocispec.Descriptor{other, missing, native},
+	})
+	index.MediaType = ocispec.MediaTypeImageIndex
+
+	l := &tarexporter{}
+	desc, err := l.ociResolveManifest(dir, native)
+	if err != nil || desc.Digest != native.Digest {
+		t.Fatalf("expected manifest %s, got %s: %v", native.Digest, desc.Digest, err)
+	}
+	desc, err = l.ociResolveManifest(dir, index)
+	if err != nil || desc.Digest != native.Digest {
+		t.Fatalf("expected manifest %s from the index, got %s: %v", native.Digest, desc.Digest, err)
+	}
+
+	// A blob that does not match its digest is rejected
+	if err := ioutil.WriteFile(ociBlobPath(dir, index.Digest), []byte("{}"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.ociResolveManifest(dir, index); err == nil || !strings.Contains(err.Error(), "digest mismatch") {
+		t.Fatalf("expected a digest mismatch error, got %v", err)
+	}
+
+	if _, err := l.ociResolveManifest(dir, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayer, Digest: native.Digest}); err == nil || !strings.Contains(err.Error(), "unsupported media type") {
+		t.Fatalf("expected an unsupported media type error, got %v", err)
+	}
+
+	// An invalid digest in an index is rejected before it is used
+	invalid := native
+	invalid.Digest = "invalid"
+	index = writeTestBlob(t, dir, ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		Manifests: []ocispec.Descriptor{invalid, native},
+	})
+	index.MediaType = ocispec.MediaTypeImageIndex
+	if _, err := l.ociResolveManifest(dir, index); err == nil || !strings.Contains(err.Error(), "invalid blob digest") {
+		t.Fatalf("expected an invalid digest error, got %v", err)
+	}
+}
+
+func TestOCIForeignSource(t *testing.T) {
+	src, err := ociForeignSource(ocispec.Descriptor{MediaType: ocispec.MediaTypeImageLayerGzip, Digest: digest.FromString("layer")})
+	if err != nil || src.Digest != "" {
+		t.Fatalf("expected no foreign source for a distributable layer, got %v: %v", src, err)
+	}
+
+	desc := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageLayerNonDistributableGzip,
+		Digest:    digest.FromString("layer"),
+		Size:      5,
+		URLs:      []string{"https://example.com/layer"},
+	}
+	src, err = ociForeignSource(desc)
+	if err != nil || src.Digest != desc.Digest || src.Size != 5 || len(src.URLs) != 1 || src.MediaType != schema2.MediaTypeForeignLayer {
+		t.Fatalf("expected the layer descriptor as foreign source, got %v: %v", src, err)
+	}
+
+	// Layers saved by the daemon hold their foreign source in annotations
+	desc.URLs = nil
+	desc.Annotations = map[string]string{
+		foreignDigestAnnotation: digest.FromString("foreign").String(),
+		foreignSizeAnnotation:   "42",
+		foreignURLsAnnotation:   "https://example.com/a https://example.com/b",
+	}
+	src, err = ociForeignSource(desc)
+	if err != nil || src.Digest != digest.FromString("foreign") || src.Size != 42 || len(src.URLs) != 2 {
+		t.Fatalf("expected the annotated foreign source, got %v: %v", src, err)
+	}
+
+	desc.Annotations[foreignDigestAnnotation] = "invalid"
+	if _, err := ociForeignSource(desc); err == nil || !strings.Contains(err.Error(), "invalid foreign layer digest") {
+		t.Fatalf("expected an invalid digest error, got %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package tarexport
 
 import (
 	"github.com/docker/distribution"
+	"github.com/docker/docker/distribution/metadata"
 	"github.com/docker/docker/image"
 	"github.com/docker/docker/layer"
 	refstore "github.com/docker/docker/reference"
@@ -24,10 +25,11 @@ type manifestItem struct {
 }
 
 type tarexporter struct {
-	is             image.Store
-	ls             layer.Store
-	rs             refstore.Store
-	loggerImgEvent LogImageEvent
+	is                image.Store
+	ls                layer.Store
+	rs                refstore.Store
+	v2MetadataService metadata.V2MetadataService
+	loggerImgEvent    LogImageEvent
 }
 
 // LogImageEvent defines interface for event generation related to image tar(load and save) operations
@@ -36,12 +38,14 @@ type LogImageEvent interface {
 	LogImageEvent(imageID, refName, action string)
 }
 
-// NewTarExporter returns new Exporter for tar packages
-func NewTarExporter(is image.Store, ls layer.Store, rs refstore.Store, loggerImgEvent LogImageEvent) image.Exporter {
+// NewTarExporter returns new Exporter for tar packages. v2MetadataService,
+// if not nil, provides the registry digests of the layers.
+func NewTarExporter(is image.Store, ls layer.Store, rs refstore.Store, v2MetadataService metadata.V2MetadataService, loggerImgEvent LogImageEvent) image.Exporter {
 	return &tarexporter{
-		is:             is,
-		ls:             ls,
-		rs:             rs,
-		loggerImgEvent: loggerImgEvent,
+		is:                is,
+		ls:                ls,
+		rs:                rs,
+		v2MetadataService: v2MetadataService,
+		loggerImgEvent:    loggerImgEvent,
 	}
 }
@@ -212,6 +212,18 @@ type DescribableStore interface {
 	RegisterWithDescriptor(io.Reader, ChainID, Platform, distribution.Descriptor) (Layer, error)
 }
 
+// FetchFunc returns the uncompressed tar stream of a remote-backed layer.
+type FetchFunc func() (io.ReadCloser, error)
+
+// RemoteStore represents a layer store capable of registering layers
+// whose content is only fetched when it is first needed. The whole layer
+// is fetched at once, there is no per-file fetch. This is experimental:
+// remote-backed layers are not persisted until their content has been
+// fetched.
+type RemoteStore interface {
+	RegisterRemote(DiffID, ChainID, Platform, distribution.Descriptor, FetchFunc) (Layer, error)
+}
+
 // MetadataTransaction represents functions for setting layer metadata
 // with a single transaction.
 type MetadataTransaction interface {
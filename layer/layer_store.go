@@ -34,6 +34,9 @@ type layerStore struct {
 	layerMap map[ChainID]*roLayer
 	layerL   sync.Mutex
 
+	// fetchL serializes the fetching of remote-backed layers.
+	fetchL sync.Mutex
+
 	mounts map[string]*mountedLayer
 	mountL sync.Mutex
 
@@ -283,7 +286,6 @@ func (ls *layerStore) registerWithDescriptor(ts io.Reader, parent ChainID, platf
 		if p == nil {
 			return nil, ErrLayerDoesNotExist
 		}
-		pid = p.cacheID
 		// Release parent chain if error
 		defer func() {
 			if err != nil {
@@ -296,6 +298,10 @@ func (ls *layerStore) registerWithDescriptor(ts io.Reader, parent ChainID, platf
 			err = ErrMaxDepthExceeded
 			return nil, err
 		}
+		if err = ls.ensureContent(p); err != nil {
+			return nil, err
+		}
+		pid = p.cacheID
 	}
 
 	// Create new roLayer
@@ -362,6 +368,154 @@ func (ls *layerStore) registerWithDescriptor(ts io.Reader, parent ChainID, platf
 	return layer.getReference(), nil
 }
 
+// RegisterRemote registers a layer whose content is fetched with fetch the
+// first time it is needed, that is when a child layer, a mount or a tar
+// stream is created from it. Until then the layer only exists in memory.
+func (ls *layerStore) RegisterRemote(diffID DiffID, parent ChainID, platform Platform, descriptor distribution.Descriptor, fetch FetchFunc) (Layer, error) {
+	// Integrity check - ensure we are creating something for the correct platform
+	if system.LCOWSupported() {
+		if strings.ToLower(ls.platform) != strings.ToLower(string(platform)) {
+			return nil, fmt.Errorf("cannot create entry for platform %q in layer store for platform %q", platform, ls.platform)
+		}
+	}
+
+	layer := &roLayer{
+		diffID:         diffID,
+		chainID:        ChainID(diffID),
+		referenceCount: 1,
+		layerStore:     ls,
+		references:     map[Layer]struct{}{},
+		descriptor:     descriptor,
+		platform:       platform,
+		fetch:          fetch,
+	}
+
+	if string(parent) != "" {
+		p := ls.get(parent)
+		if p == nil {
+			return nil, ErrLayerDoesNotExist
+		}
+		if p.depth() >= maxLayerDepth {
+			ls.layerL.Lock()
+			ls.releaseLayer(p)
+			ls.layerL.Unlock()
+			return nil, ErrMaxDepthExceeded
+		}
+		layer.parent = p
+		layer.chainID = createChainIDFromParent(p.chainID, diffID)
+	}
+
+	ls.layerL.Lock()
+	defer ls.layerL.Unlock()
+
+	if existingLayer := ls.getWithoutLock(layer.chainID); existingLayer != nil {
+		if layer.parent != nil {
+			ls.releaseLayer(layer.parent)
+		}
+		return existingLayer.getReference(), nil
+	}
+
+	ls.layerMap[layer.chainID] = layer
+
+	return layer.getReference(), nil
+}
+
+// isRemote returns whether the content of the layer has not been fetched yet.
+func (ls *layerStore) isRemote(layer *roLayer) bool {
+	ls.layerL.Lock()
+	defer ls.layerL.Unlock()
+	return layer.fetch != nil
+}
+
+// ensureContent fetches the content of the remote-backed layers in the
+// chain of layer, parents first.
+func (ls *layerStore) ensureContent(layer *roLayer) error {
+	ls.fetchL.Lock()
+	defer ls.fetchL.Unlock()
+
+	var chain []*roLayer
+	ls.layerL.Lock()
+	for l := layer; l != nil && l.fetch != nil; l = l.parent {
+		chain = append(chain, l)
+	}
+	ls.layerL.Unlock()
+
+	for i := len(chain) - 1; i >= 0; i-- {
+		if err := ls.fetchLayer(chain[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (ls *layerStore) fetchLayer(layer *roLayer) (err error) {
+	logrus.Debugf("Fetching content of remote layer %s", layer.chainID)
+
+	rc, err := layer.fetch()
+	if err != nil {
+		return fmt.Errorf("failed to fetch layer %s: %v", layer.diffID, err)
+	}
+	defer rc.Close()
+
+	var pid string
+	if layer.parent != nil {
+		pid = layer.parent.cacheID
+	}
+	fetched := &roLayer{
+		chainID:    layer.chainID,
+		parent:     layer.parent,
+		cacheID:    stringid.GenerateRandomID(),
+		descriptor: layer.descriptor,
+		platform:   layer.platform,
+	}
+
+	if err = ls.driver.Create(fetched.cacheID, pid, nil); err != nil {
+		return err
+	}
+
+	tx, err := ls.store.StartTransaction()
+	if err != nil {
+		if err := ls.driver.Remove(fetched.cacheID); err != nil {
+			logrus.Errorf("Error cleaning up cache layer %s: %v", fetched.cacheID, err)
+		}
+		return err
+	}
+
+	defer func() {
+		if err != nil {
+			logrus.Debugf("Cleaning up layer %s: %v", fetched.cacheID, err)
+			if err := ls.driver.Remove(fetched.cacheID); err != nil {
+				logrus.Errorf("Error cleaning up cache layer %s: %v", fetched.cacheID, err)
+			}
+			if err := tx.Cancel(); err != nil {
+				logrus.Errorf("Error canceling metadata transaction %q: %s", tx.String(), err)
+			}
+		}
+	}()
+
+	if err = ls.applyTar(tx, rc, pid, fetched); err != nil {
+		return err
+	}
+	if fetched.diffID != layer.diffID {
+		err = fmt.Errorf("fetched content of layer %s does not match, got %s", layer.diffID, fetched.diffID)
+		return err
+	}
+	if err = storeLayer(tx, fetched); err != nil {
+		return err
+	}
+	if err = tx.Commit(layer.chainID); err != nil {
+		return err
+	}
+
+	ls.layerL.Lock()
+	layer.cacheID = fetched.cacheID
+	layer.size = fetched.size
+	layer.fetch = nil
+	ls.layerL.Unlock()
+
+	return nil
+}
+
 func (ls *layerStore) getWithoutLock(layer ChainID) *roLayer {
 	l, ok := ls.layerMap[layer]
 	if !ok {
@@ -405,14 +559,18 @@ func (ls *layerStore) Map() map[ChainID]Layer {
 }
 
 func (ls *layerStore) deleteLayer(layer *roLayer, metadata *Metadata) error {
-	err := ls.driver.Remove(layer.cacheID)
-	if err != nil {
-		return err
-	}
-	err = ls.store.Remove(layer.chainID)
-	if err != nil {
-		return err
+	// Remote-backed layers which were never fetched have nothing stored
+	if layer.fetch == nil {
+		err := ls.driver.Remove(layer.cacheID)
+		if err != nil {
+			return err
+		}
+		err = ls.store.Remove(layer.chainID)
+		if err != nil {
+			return err
+		}
 	}
+	var err error
 	metadata.DiffID = layer.diffID
 	metadata.ChainID = layer.chainID
 	metadata.Size, err = layer.Size()
@@ -503,7 +661,6 @@ func (ls *layerStore) CreateRWLayer(name string, parent ChainID, opts *CreateRWL
 		if p == nil {
 			return nil, ErrLayerDoesNotExist
 		}
-		pid = p.cacheID
 
 		// Release parent chain if error
 		defer func() {
@@ -513,6 +670,11 @@ func (ls *layerStore) CreateRWLayer(name string, parent ChainID, opts *CreateRWL
 				ls.layerL.Unlock()
 			}
 		}()
+
+		if err = ls.ensureContent(p); err != nil {
+			return nil, err
+		}
+		pid = p.cacheID
 	}
 
 	m = &mountedLayer{
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	"github.com/containerd/continuity/driver"
+	"github.com/docker/distribution"
 	"github.com/docker/docker/daemon/graphdriver"
 	"github.com/docker/docker/daemon/graphdriver/vfs"
 	"github.com/docker/docker/pkg/archive"
@@ -772,3 +773,61 @@ func TestTarStreamVerification(t *testing.T) {
 		t.Fatalf("wrong error returned from tarstream: %q", err)
 	}
 }
+
+func TestRegisterRemote(t *testing.T) {
+	ls, _, cleanup := newTestStore(t)
+	defer cleanup()
+
+	tar1, err := tarFromFiles(newTestFile("/etc/profile", []byte("# Base configuration"), 0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tar2, err := tarFromFiles(newTestFile("/root/.bashrc", []byte("# Root configuration"), 0644))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var fetches []string
+	fetchFunc := func(name string, content []byte) FetchFunc {
+		return func() (io.ReadCloser, error) {
+			fetches = append(fetches, name)
+			return ioutil.NopCloser(bytes.NewReader(content)), nil
+		}
+	}
+
+	rs := ls.(RemoteStore)
+	layer1, err := rs.RegisterRemote(DiffID(digest.FromBytes(tar1)), "", Platform(runtime.GOOS), distribution.Descriptor{}, fetchFunc("layer1", tar1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	layer2, err := rs.RegisterRemote(DiffID(digest.FromBytes(tar2)), layer1.ChainID(), Platform(runtime.GOOS), distribution.Descriptor{}, fetchFunc("layer2", tar2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fetches) != 0 {
+		t.Fatalf("Expected no fetch on registration, got %v", fetches)
+	}
+
+	// Reading the top layer fetches the chain, parents first
+	assertLayerDiff(t, tar2, layer2)
+	if strings.Join(fetches, ",") != "layer1,layer2" {
+		t.Fatalf("Unexpected fetches %v", fetches)
+	}
+	assertLayerDiff(t, tar1, layer1)
+	if len(fetches) != 2 {
+		t.Fatalf("Expected fetched layers to be stored, got fetches %v", fetches)
+	}
+
+	// Fetched content which does not match the diff ID is rejected
+	layer3, err := rs.RegisterRemote(DiffID(digest.FromBytes(tar1)), layer2.ChainID(), Platform(runtime.GOOS), distribution.Descriptor{}, fetchFunc("layer3", tar2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ls.CreateRWLayer("mount", layer3.ChainID(), nil); err == nil || !strings.Contains(err.Error(), "does not match") {
+		t.Fatalf("Expected a mismatched content error, got %v", err)
+	}
+
+	releaseAndCheckDeleted(t, ls, layer3, layer3)
+	releaseAndCheckDeleted(t, ls, layer2, layer2)
+	releaseAndCheckDeleted(t, ls, layer1, layer1)
+}
@@ -18,6 +18,10 @@ type roLayer struct {
 	descriptor distribution.Descriptor
 	platform   Platform
 
+	// fetch is set on remote-backed layers until their content has
+	// been fetched, see layerStore.ensureContent.
+	fetch FetchFunc
+
 	referenceCount int
 	references     map[Layer]struct{}
 }
@@ -25,6 +29,9 @@ type roLayer struct {
 // TarStream for roLayer guarantees that the data that is produced is the exact
 // data that the layer was registered with.
 func (rl *roLayer) TarStream() (io.ReadCloser, error) {
+	if err := rl.layerStore.ensureContent(rl); err != nil {
+		return nil, err
+	}
 	rc, err := rl.layerStore.getTarStream(rl)
 	if err != nil {
 		return nil, err
@@ -41,6 +48,9 @@ func (rl *roLayer) TarStream() (io.ReadCloser, error) {
 // data. As such it should not be used when the layer content must be verified
 // to be an exact match to the registered layer.
 func (rl *roLayer) TarStreamFrom(parent ChainID) (io.ReadCloser, error) {
+	if err := rl.layerStore.ensureContent(rl); err != nil {
+		return nil, err
+	}
 	var parentCacheID string
 	for pl := rl.parent; pl != nil; pl = pl.parent {
 		if pl.chainID == parent {
@@ -86,6 +96,9 @@ func (rl *roLayer) DiffSize() (size int64, err error) {
 }
 
 func (rl *roLayer) Metadata() (map[string]string, error) {
+	if rl.layerStore.isRemote(rl) {
+		return map[string]string{}, nil
+	}
 	return rl.layerStore.driver.GetMetadata(rl.cacheID)
 }
 
@@ -15,7 +15,9 @@ import (
 
 	"github.com/docker/docker/pkg/idtools"
 	"github.com/docker/docker/pkg/pools"
+	"github.com/docker/docker/pkg/symlink"
 	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/go-digest"
 	"github.com/sirupsen/logrus"
 )
 
@@ -390,6 +392,67 @@ func ChangesSize(newDir string, changes []Change) int64 {
 	return size
 }
 
+// ChangeDetails describes the state of a changed file in the directory it
+// was changed in.
+type ChangeDetails struct {
+	Size    int64
+	Mode    os.FileMode
+	UID     int
+	GID     int
+	ModTime time.Time
+	// Digest is the digest of the content of the file. It is only set for
+	// regular files, when requested.
+	Digest digest.Digest
+}
+
+// StatChange returns the details of the file of change, relative to dir. It
+// returns nil for deleted files. If withDigest is true, the content of added
+// and modified regular files is hashed.
+//
+// The path of the change is resolved within dir, as the files of dir, like
+// the filesystem of a running container, must not lead outside of it: the
+// symbolic links of its parent directories are resolved in the scope of dir,
+// and the file itself is never followed if it is a symbolic link.
+func StatChange(dir string, change Change, withDigest bool) (*ChangeDetails, error) {
+	if change.Kind == ChangeDelete {
+		return nil, nil
+	}
+	parent, err := symlink.FollowSymlinkInScope(filepath.Join(dir, filepath.Dir(change.Path)), dir)
+	if err != nil {
+		return nil, err
+	}
+	file := filepath.Join(parent, filepath.Base(change.Path))
+	fi, err := os.Lstat(file)
+	if err != nil {
+		return nil, err
+	}
+
+	details := &ChangeDetails{
+		Size:    fi.Size(),
+		Mode:    fi.Mode(),
+		ModTime: fi.ModTime(),
+	}
+	details.UID, details.GID = getOwner(fi)
+	if withDigest && fi.Mode().IsRegular() {
+		f, err := openNoFollow(file)
+		if err != nil {
+			return nil, err
+		}
+		defer f.Close()
+		// The file may have been replaced since it was stat'ed.
+		if ofi, err := f.Stat(); err != nil {
+			return nil, err
+		} else if !os.SameFile(fi, ofi) {
+			return nil, fmt.Errorf("%s was changed while being read", change.Path)
+		}
+		details.Digest, err = digest.Canonical.FromReader(f)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return details, nil
+}
+
 // ExportChanges produces an Archive from the provided changes, relative to dir.
 func ExportChanges(dir string, changes []Change, uidMaps, gidMaps []idtools.IDMap) (io.ReadCloser, error) {
 	reader, writer := io.Pipe()
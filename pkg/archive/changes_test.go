@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"github.com/docker/docker/pkg/system"
+	"github.com/opencontainers/go-digest"
 	"github.com/stretchr/testify/require"
 )
 
@@ -490,6 +491,60 @@ func TestChangesSize(t *testing.T) {
 	}
 }
 
+func TestStatChange(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("file modes on Windows")
+	}
+	parentPath, err := ioutil.TempDir("", "docker-changes-test")
+	require.NoError(t, err)
+	defer os.RemoveAll(parentPath)
+	err = ioutil.WriteFile(path.Join(parentPath, "file"), []byte("hello"), 0640)
+	require.NoError(t, err)
+	err = os.Mkdir(path.Join(parentPath, "dir"), 0755)
+	require.NoError(t, err)
+
+	details, err := StatChange(parentPath, Change{Path: "/file", Kind: ChangeModify}, true)
+	require.NoError(t, err)
+	require.Equal(t, int64(5), details.Size)
+	require.Equal(t, os.FileMode(0640), details.Mode)
+	require.Equal(t, digest.FromString("hello"), details.Digest)
+
+	details, err = StatChange(parentPath, Change{Path: "/file", Kind: ChangeAdd}, false)
+	require.NoError(t, err)
+	require.Equal(t, digest.Digest(""), details.Digest)
+
+	details, err = StatChange(parentPath, Change{Path: "/dir", Kind: ChangeAdd}, true)
+	require.NoError(t, err)
+	require.True(t, details.Mode.IsDir())
+	require.Equal(t, digest.Digest(""), details.Digest)
+
+	details, err = StatChange(parentPath, Change{Path: "/deleted", Kind: ChangeDelete}, true)
+	require.NoError(t, err)
+	require.Nil(t, details)
+
+	_, err = StatChange(parentPath, Change{Path: "/missing", Kind: ChangeModify}, true)
+	require.True(t, os.IsNotExist(err))
+
+	// Symbolic links are resolved within parentPath.
+	outside, err := ioutil.TempDir("", "docker-changes-test-outside")
+	require.NoError(t, err)
+	defer os.RemoveAll(outside)
+	err = ioutil.WriteFile(path.Join(outside, "secret"), []byte("secret"), 0600)
+	require.NoError(t, err)
+	err = os.Symlink(outside, path.Join(parentPath, "escape"))
+	require.NoError(t, err)
+	err = os.Symlink(path.Join(outside, "secret"), path.Join(parentPath, "link"))
+	require.NoError(t, err)
+
+	_, err = StatChange(parentPath, Change{Path: "/escape/secret", Kind: ChangeModify}, true)
+	require.True(t, os.IsNotExist(err))
+
+	details, err = StatChange(parentPath, Change{Path: "/link", Kind: ChangeModify}, true)
+	require.NoError(t, err)
+	require.True(t, details.Mode&os.ModeSymlink != 0)
+	require.Equal(t, digest.Digest(""), details.Digest)
+}
+
 func checkChanges(expectedChanges, changes []Change, t *testing.T) {
 	sort.Sort(changesByPath(expectedChanges))
 	sort.Sort(changesByPath(changes))
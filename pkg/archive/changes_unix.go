@@ -35,3 +35,14 @@ func getIno(fi os.FileInfo) uint64 {
 func hasHardlinks(fi os.FileInfo) bool {
 	return fi.Sys().(*syscall.Stat_t).Nlink > 1
 }
+
+func getOwner(fi os.FileInfo) (uid, gid int) {
+	st := fi.Sys().(*syscall.Stat_t)
+	return int(st.Uid), int(st.Gid)
+}
+
+// openNoFollow opens the file at path for reading without following it if it
+// is a symbolic link, or blocking if it is a named pipe.
+func openNoFollow(path string) (*os.File, error) {
+	return os.OpenFile(path, os.O_RDONLY|unix.O_NOFOLLOW|unix.O_NONBLOCK, 0)
+}
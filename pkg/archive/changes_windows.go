@@ -28,3 +28,12 @@ func getIno(fi os.FileInfo) (inode uint64) {
 func hasHardlinks(fi os.FileInfo) bool {
 	return false
 }
+
+func getOwner(fi os.FileInfo) (uid, gid int) {
+	return
+}
+
+// openNoFollow opens the file at path for reading.
+func openNoFollow(path string) (*os.File, error) {
+	return os.Open(path)
+}
@@ -0,0 +1,151 @@
+package mount
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"unsafe"
+
+	"golang.org/x/sys/unix"
+)
+
+// Mount API syscalls, available since Linux 5.2. Their numbers are the same
+// on all architectures.
+const (
+	sysOpenTree  = 428
+	sysMoveMount = 429
+
+	openTreeClone       = 0x1
+	atRecursive         = 0x8000
+	moveMountFEmptyPath = 0x4
+)
+
+// BindMountInNamespace bind mounts source, a path in the mount namespace of
+// the caller, to target in the mount namespace of the process pid. The
+// target is created if it doesn't exist. This requires Linux 5.2 or later.
+func BindMountInNamespace(pid int, source, target string, readonly bool) error {
+	fi, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	fd, err := openTree(source)
+	if err != nil {
+		return err
+	}
+	defer unix.Close(fd)
+
+	return inNamespace(pid, func() error {
+		if err := createMountpoint(target, fi.IsDir()); err != nil {
+			return err
+		}
+		if err := moveMount(fd, target); err != nil {
+			return err
+		}
+		if readonly {
+			if err := unix.Mount("", target, "", unix.MS_BIND|unix.MS_REMOUNT|unix.MS_RDONLY, ""); err != nil {
+				unix.Unmount(target, unix.MNT_DETACH)
+				return fmt.Errorf("failed to make %s read-only: %v", target, err)
+			}
+		}
+		return nil
+	})
+}
+
+// UnmountInNamespace lazily unmounts target in the mount namespace of the
+// process pid.
+func UnmountInNamespace(pid int, target string) error {
+	return inNamespace(pid, func() error {
+		return unix.Unmount(target, unix.MNT_DETACH)
+	})
+}
+
+// inNamespace runs fn on a thread in the mount namespace of the process pid.
+func inNamespace(pid int, fn func() error) error {
+	ns, err := os.Open(fmt.Sprintf("/proc/%d/ns/mnt", pid))
+	if err != nil {
+		return err
+	}
+	defer ns.Close()
+
+	errCh := make(chan error, 1)
+	go func() {
+		// The thread is never unlocked, so that the runtime terminates it
+		// when the goroutine exits instead of reusing it in the namespace.
+		runtime.LockOSThread()
+		if unix.Gettid() == unix.Getpid() {
+			// The main thread is not terminated when its goroutine exits,
+			// and its mount namespace is the one seen through /proc/self.
+			// Keep it busy while fn runs on another thread.
+			errCh <- inNamespace(pid, fn)
+			runtime.UnlockOSThread()
+			return
+		}
+		// A thread sharing its filesystem attributes with other threads
+		// can't change its mount namespace.
+		if err := unix.Unshare(unix.CLONE_FS); err != nil {
+			errCh <- err
+			return
+		}
+		if err := unix.Setns(int(ns.Fd()), unix.CLONE_NEWNS); err != nil {
+			errCh <- fmt.Errorf("failed to join the mount namespace of process %d: %v", pid, err)
+			return
+		}
+		errCh <- fn()
+	}()
+	return <-errCh
+}
+
+// createMountpoint creates target as a directory, or as an empty file if
+// the source of the mount is not a directory.
+func createMountpoint(target string, dir bool) error {
+	if dir {
+		return os.MkdirAll(target, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(target, os.O_CREATE, 0644)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// fdcwd is AT_FDCWD as a variable, as the negative constant can't be
+// converted to an uintptr.
+var fdcwd = unix.AT_FDCWD
+
+// openTree returns a file descriptor referring to a detached copy of the
+// mount tree at path.
+func openTree(path string) (int, error) {
+	p, err := unix.BytePtrFromString(path)
+	if err != nil {
+		return -1, err
+	}
+	fd, _, errno := unix.Syscall(sysOpenTree, uintptr(fdcwd), uintptr(unsafe.Pointer(p)), uintptr(openTreeClone|atRecursive|unix.O_CLOEXEC))
+	if errno != 0 {
+		if errno == unix.ENOSYS {
+			return -1, fmt.Errorf("mounting in a running container requires Linux 5.2 or later")
+		}
+		return -1, &os.PathError{Op: "open_tree", Path: path, Err: errno}
+	}
+	return int(fd), nil
+}
+
+// moveMount attaches the detached mount tree fd to target.
+func moveMount(fd int, target string) error {
+	empty, err := unix.BytePtrFromString("")
+	if err != nil {
+		return err
+	}
+	p, err := unix.BytePtrFromString(target)
+	if err != nil {
+		return err
+	}
+	_, _, errno := unix.Syscall6(sysMoveMount, uintptr(fd), uintptr(unsafe.Pointer(empty)), uintptr(fdcwd), uintptr(unsafe.Pointer(p)), moveMountFEmptyPath, 0)
+	if errno != 0 {
+		return &os.PathError{Op: "move_mount", Path: target, Err: errno}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// +build linux
+
+package mount
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func mountedIn(pid int, target string) (bool, error) {
+	b, err := ioutil.ReadFile(fmt.Sprintf("/proc/%d/mountinfo", pid))
+	if err != nil {
+		return false, err
+	}
+	for _, line := range strings.Split(string(b), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) > 4 && fields[4] == target {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func TestBindMountInNamespace(t *testing.T) {
+	if os.Getuid() != 0 {
+		t.Skip("root required")
+	}
+	tmp, err := ioutil.TempDir("", "mount-ns-tests")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(tmp)
+	source := filepath.Join(tmp, "source")
+	target := filepath.Join(tmp, "target")
+	if err := os.Mkdir(source, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	ready := filepath.Join(tmp, "ready")
+	cmd := exec.Command("sh", "-c", "mount --make-rprivate / && touch "+ready+" && exec sleep 60")
+	cmd.SysProcAttr = &syscall.SysProcAttr{Cloneflags: syscall.CLONE_NEWNS}
+	if err := cmd.Start(); err != nil {
+		t.Skipf("cannot create a mount namespace: %v", err)
+	}
+	defer cmd.Wait()
+	defer cmd.Process.Kill()
+	for i := 0; ; i++ {
+		if _, err := os.Stat(ready); err == nil {
+			break
+		}
+		if i == 50 {
+			t.Skip("cannot set up the mount namespace")
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+	pid := cmd.Process.Pid
+
+	if err := BindMountInNamespace(pid, source, target, true); err != nil {
+		if strings.Contains(err.Error(), "Linux 5.2") {
+			t.Skip(err)
+		}
+		t.Fatal(err)
+	}
+	if mounted, err := mountedIn(pid, target); err != nil || !mounted {
+		t.Fatalf("expected %s to be mounted in the namespace: %v", target, err)
+	}
+	if mounted, err := mountedIn(os.Getpid(), target); err != nil || mounted {
+		t.Fatalf("expected %s not to be mounted in the current namespace: %v", target, err)
+	}
+
+	if err := UnmountInNamespace(pid, target); err != nil {
+		t.Fatal(err)
+	}
+	if mounted, err := mountedIn(pid, target); err != nil || mounted {
+		t.Fatalf("expected %s to be unmounted from the namespace: %v", target, err)
+	}
+}
@@ -0,0 +1,17 @@
+// +build !linux
+
+package mount
+
+import "errors"
+
+var errNamespaceMountUnsupported = errors.New("mounting in the namespace of a process is only supported on Linux")
+
+// BindMountInNamespace is not supported on this platform.
+func BindMountInNamespace(pid int, source, target string, readonly bool) error {
+	return errNamespaceMountUnsupported
+}
+
+// UnmountInNamespace is not supported on this platform.
+func UnmountInNamespace(pid int, target string) error {
+	return errNamespaceMountUnsupported
+}
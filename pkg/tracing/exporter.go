@@ -0,0 +1,230 @@
+package tracing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	exportQueueSize = 2048
+	exportBatchSize = 256
+	exportInterval  = 5 * time.Second
+)
+
+// batchExporter queues ended spans and hands them in batches to a flush
+// function, from a single goroutine. Spans are dropped when the queue is full.
+type batchExporter struct {
+	mu     sync.RWMutex
+	closed bool
+	queue  chan *Span
+	flush  func([]*Span) error
+	done   chan struct{}
+	err    error
+}
+
+func newBatchExporter(flush func([]*Span) error) *batchExporter {
+	e := &batchExporter{
+		queue: make(chan *Span, exportQueueSize),
+		flush: flush,
+		done:  make(chan struct{}),
+	}
+	go e.run()
+	return e
+}
+
+func (e *batchExporter) run() {
+	defer close(e.done)
+	ticker := time.NewTicker(exportInterval)
+	defer ticker.Stop()
+
+	var batch []*Span
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := e.flush(batch); err != nil {
+			logrus.Warnf("Error exporting %d trace spans: %v", len(batch), err)
+			e.err = err
+		}
+		batch = nil
+	}
+	for {
+		select {
+		case s, ok := <-e.queue:
+			if !ok {
+				flush()
+				return
+			}
+			batch = append(batch, s)
+			if len(batch) >= exportBatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
+
+// Export queues s for export.
+func (e *batchExporter) Export(s *Span) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	if e.closed {
+		return
+	}
+	select {
+	case e.queue <- s:
+	default:
+		logrus.Debugf("Trace span queue full, dropping span %s", s.Name)
+	}
+}
+
+// Close exports the queued spans and stops the exporter. Spans exported
+// after the exporter is closed are dropped.
+func (e *batchExporter) Close() error {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+	<-e.done
+	return e.err
+}
+
+// NewFileExporter returns an exporter appending spans to the file at path,
+// as newline-delimited JSON.
+func NewFileExporter(path string) (Exporter, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	enc := json.NewEncoder(f)
+	e := newBatchExporter(func(spans []*Span) error {
+		for _, s := range spans {
+			if err := enc.Encode(s); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	return &fileExporter{batchExporter: e, f: f}, nil
+}
+
+type fileExporter struct {
+	*batchExporter
+	f *os.File
+}
+
+func (e *fileExporter) Close() error {
+	err := e.batchExporter.Close()
+	if cerr := e.f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// NewOTLPExporter returns an exporter posting spans to an OpenTelemetry
+// collector, using the JSON encoding of the OTLP/HTTP protocol. endpoint is
+// the full URL of the traces endpoint, e.g. http://localhost:4318/v1/traces.
+func NewOTLPExporter(endpoint, serviceName string) (Exporter, error) {
+	u, err := url.Parse(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid OTLP endpoint %q: scheme must be http or https", endpoint)
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+	return newBatchExporter(func(spans []*Span) error {
+		body, err := json.Marshal(otlpRequest(serviceName, spans))
+		if err != nil {
+			return err
+		}
+		resp, err := client.Post(endpoint, "application/json", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+			return fmt.Errorf("OTLP endpoint %s responded with status %s", endpoint, resp.Status)
+		}
+		return nil
+	}), nil
+}
+
+type otlpAttribute struct {
+	Key   string `json:"key"`
+	Value struct {
+		StringValue string `json:"stringValue"`
+	} `json:"value"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              int             `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+}
+
+type otlpScopeSpans struct {
+	Scope struct {
+		Name string `json:"name"`
+	} `json:"scope"`
+	Spans []otlpSpan `json:"spans"`
+}
+
+type otlpResourceSpans struct {
+	Resource struct {
+		Attributes []otlpAttribute `json:"attributes"`
+	} `json:"resource"`
+	ScopeSpans []otlpScopeSpans `json:"scopeSpans"`
+}
+
+type otlpTracesRequest struct {
+	ResourceSpans []otlpResourceSpans `json:"resourceSpans"`
+}
+
+func newOTLPAttribute(key, value string) otlpAttribute {
+	a := otlpAttribute{Key: key}
+	a.Value.StringValue = value
+	return a
+}
+
+// otlpRequest converts spans to an OTLP ExportTraceServiceRequest.
+func otlpRequest(serviceName string, spans []*Span) otlpTracesRequest {
+	var scope otlpScopeSpans
+	scope.Scope.Name = "github.com/docker/docker/pkg/tracing"
+	for _, s := range spans {
+		o := otlpSpan{
+			TraceID:           s.TraceID,
+			SpanID:            s.SpanID,
+			ParentSpanID:      s.ParentID,
+			Name:              s.Name,
+			Kind:              1, // SPAN_KIND_INTERNAL
+			StartTimeUnixNano: fmt.Sprintf("%d", s.Start.UnixNano()),
+			EndTimeUnixNano:   fmt.Sprintf("%d", s.End.UnixNano()),
+		}
+		for k, v := range s.Attributes {
+			o.Attributes = append(o.Attributes, newOTLPAttribute(k, v))
+		}
+		scope.Spans = append(scope.Spans, o)
+	}
+
+	var rs otlpResourceSpans
+	rs.Resource.Attributes = []otlpAttribute{newOTLPAttribute("service.name", serviceName)}
+	rs.ScopeSpans = []otlpScopeSpans{scope}
+	return otlpTracesRequest{ResourceSpans: []otlpResourceSpans{rs}}
+}
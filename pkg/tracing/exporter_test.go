@@ -0,0 +1,107 @@
+package tracing
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileExporter(t *testing.T) {
+	dir, err := ioutil.TempDir("", "tracing")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	p := filepath.Join(dir, "traces.json")
+	e, err := NewFileExporter(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e.Export(&Span{TraceID: "t", SpanID: "a", Name: "first"})
+	e.Export(&Span{TraceID: "t", SpanID: "b", ParentID: "a", Name: "second"})
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// spans exported after close are dropped
+	e.Export(&Span{TraceID: "t", SpanID: "c", Name: "third"})
+
+	f, err := os.Open(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var names []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var s Span
+		if err := json.Unmarshal(scanner.Bytes(), &s); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, s.Name)
+	}
+	if len(names) != 2 || names[0] != "first" || names[1] != "second" {
+		t.Fatalf("unexpected spans in file: %v", names)
+	}
+}
+
+func TestOTLPExporter(t *testing.T) {
+	received := make(chan otlpTracesRequest, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req otlpTracesRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Error(err)
+		}
+		received <- req
+	}))
+	defer srv.Close()
+
+	e, err := NewOTLPExporter(srv.URL+"/v1/traces", "dockerd")
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1, 0)
+	e.Export(&Span{
+		TraceID:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		SpanID:     "00f067aa0ba902b7",
+		Name:       "op",
+		Start:      start,
+		End:        start.Add(time.Second),
+		Attributes: map[string]string{"error": "boom"},
+	})
+	if err := e.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := <-received
+	if len(req.ResourceSpans) != 1 || len(req.ResourceSpans[0].ScopeSpans) != 1 {
+		t.Fatalf("unexpected request: %+v", req)
+	}
+	attrs := req.ResourceSpans[0].Resource.Attributes
+	if len(attrs) != 1 || attrs[0].Key != "service.name" || attrs[0].Value.StringValue != "dockerd" {
+		t.Fatalf("unexpected resource attributes: %+v", attrs)
+	}
+	spans := req.ResourceSpans[0].ScopeSpans[0].Spans
+	if len(spans) != 1 {
+		t.Fatalf("expected 1 span, got %d", len(spans))
+	}
+	s := spans[0]
+	if s.Name != "op" || s.StartTimeUnixNano != "1000000000" || s.EndTimeUnixNano != "2000000000" {
+		t.Fatalf("unexpected span: %+v", s)
+	}
+	if len(s.Attributes) != 1 || s.Attributes[0].Key != "error" || s.Attributes[0].Value.StringValue != "boom" {
+		t.Fatalf("unexpected span attributes: %+v", s.Attributes)
+	}
+}
+
+func TestOTLPExporterInvalidEndpoint(t *testing.T) {
+	if _, err := NewOTLPExporter("localhost:4318", "dockerd"); err == nil {
+		t.Fatal("expected an error for an endpoint without scheme")
+	}
+}
@@ -0,0 +1,181 @@
+// Package tracing provides lightweight request tracing. Spans are propagated
+// through a context.Context, and exported once ended to the exporter set
+// with SetExporter. When no exporter is set, spans are not recorded.
+package tracing
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"strings"
+	"sync"
+	"time"
+)
+
+// TraceparentHeader is the W3C trace context header used to propagate a
+// trace from a client to the daemon.
+const TraceparentHeader = "traceparent"
+
+// TraceIDHeader is the response header holding the ID of the trace recorded
+// for an API request.
+const TraceIDHeader = "Docker-Trace-Id"
+
+// Span is a timed operation, part of a trace.
+type Span struct {
+	TraceID    string            `json:"traceId"`
+	SpanID     string            `json:"spanId"`
+	ParentID   string            `json:"parentSpanId,omitempty"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+
+	mu    sync.Mutex
+	ended bool
+}
+
+// Exporter sends ended spans to a tracing backend.
+type Exporter interface {
+	// Export is called once for each ended span. It must not block.
+	Export(*Span)
+	// Close flushes the spans not exported yet and releases the exporter.
+	Close() error
+}
+
+var (
+	exporterMu sync.RWMutex
+	exporter   Exporter
+)
+
+// SetExporter sets the exporter spans are sent to. A nil exporter disables
+// tracing. The previous exporter, if any, is returned and must be closed
+// by the caller.
+func SetExporter(e Exporter) Exporter {
+	exporterMu.Lock()
+	defer exporterMu.Unlock()
+	prev := exporter
+	exporter = e
+	return prev
+}
+
+func getExporter() Exporter {
+	exporterMu.RLock()
+	defer exporterMu.RUnlock()
+	return exporter
+}
+
+// Enabled returns whether spans are recorded.
+func Enabled() bool {
+	return getExporter() != nil
+}
+
+type spanKey struct{}
+
+// FromContext returns the span held by ctx, or nil if there is none.
+func FromContext(ctx context.Context) *Span {
+	s, _ := ctx.Value(spanKey{}).(*Span)
+	return s
+}
+
+// WithSpan returns a copy of ctx holding s.
+func WithSpan(ctx context.Context, s *Span) context.Context {
+	return context.WithValue(ctx, spanKey{}, s)
+}
+
+// StartSpan starts a span named name, child of the span held by ctx if any,
+// and returns it along with a context holding it. If tracing is disabled,
+// the returned span is nil, which is safe to use with the Span methods.
+func StartSpan(ctx context.Context, name string) (*Span, context.Context) {
+	if !Enabled() {
+		return nil, ctx
+	}
+	s := &Span{
+		SpanID: newID(8),
+		Name:   name,
+		Start:  time.Now(),
+	}
+	if parent := FromContext(ctx); parent != nil {
+		s.TraceID = parent.TraceID
+		s.ParentID = parent.SpanID
+	} else {
+		s.TraceID = newID(16)
+	}
+	return s, WithSpan(ctx, s)
+}
+
+// StartRemoteSpan starts a root span for a request carrying the traceparent
+// header value. The span joins the trace of the caller if the header is valid,
+// and starts a new trace otherwise.
+func StartRemoteSpan(ctx context.Context, name, traceparent string) (*Span, context.Context) {
+	if traceID, parentID, ok := parseTraceparent(traceparent); ok {
+		ctx = WithSpan(ctx, &Span{TraceID: traceID, SpanID: parentID, ended: true})
+	}
+	return StartSpan(ctx, name)
+}
+
+// parseTraceparent parses a W3C traceparent header value, of the form
+// "version-traceid-parentid-flags".
+func parseTraceparent(v string) (traceID, parentID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(v), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	for _, p := range parts[1:3] {
+		if _, err := hex.DecodeString(p); err != nil || strings.Trim(p, "0") == "" {
+			return "", "", false
+		}
+	}
+	return strings.ToLower(parts[1]), strings.ToLower(parts[2]), true
+}
+
+// SetAttribute sets an attribute on the span. It has no effect once the
+// span is finished.
+func (s *Span) SetAttribute(key, value string) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.ended {
+		return
+	}
+	if s.Attributes == nil {
+		s.Attributes = make(map[string]string)
+	}
+	s.Attributes[key] = value
+}
+
+// SetError records err on the span, if it is not nil.
+func (s *Span) SetError(err error) {
+	if err != nil {
+		s.SetAttribute("error", err.Error())
+	}
+}
+
+// Finish ends the span and exports it. Only the first call has an effect,
+// and the span must not be modified afterwards.
+func (s *Span) Finish() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.End = time.Now()
+	s.mu.Unlock()
+
+	if e := getExporter(); e != nil {
+		e.Export(s)
+	}
+}
+
+func newID(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,96 @@
+package tracing
+
+import (
+	"context"
+	"sync"
+	"testing"
+)
+
+type recordingExporter struct {
+	mu    sync.Mutex
+	spans []*Span
+}
+
+func (e *recordingExporter) Export(s *Span) {
+	e.mu.Lock()
+	e.spans = append(e.spans, s)
+	e.mu.Unlock()
+}
+
+func (e *recordingExporter) Close() error {
+	return nil
+}
+
+func TestStartSpanDisabled(t *testing.T) {
+	SetExporter(nil)
+	s, ctx := StartSpan(context.Background(), "op")
+	if s != nil {
+		t.Fatalf("expected no span when tracing is disabled, got %v", s)
+	}
+	if FromContext(ctx) != nil {
+		t.Fatal("expected no span in the context when tracing is disabled")
+	}
+	// methods must be safe to call on a nil span
+	s.SetAttribute("k", "v")
+	s.SetError(nil)
+	s.Finish()
+}
+
+func TestStartSpanChild(t *testing.T) {
+	e := &recordingExporter{}
+	SetExporter(e)
+	defer SetExporter(nil)
+
+	parent, ctx := StartSpan(context.Background(), "parent")
+	child, _ := StartSpan(ctx, "child")
+	child.SetAttribute("key", "value")
+	child.Finish()
+	child.Finish()
+	parent.Finish()
+
+	if child.TraceID != parent.TraceID {
+		t.Fatalf("expected child to be in trace %s, got %s", parent.TraceID, child.TraceID)
+	}
+	if child.ParentID != parent.SpanID {
+		t.Fatalf("expected child parent to be %s, got %s", parent.SpanID, child.ParentID)
+	}
+	if parent.ParentID != "" {
+		t.Fatalf("expected root span to have no parent, got %s", parent.ParentID)
+	}
+	if len(e.spans) != 2 || e.spans[0] != child || e.spans[1] != parent {
+		t.Fatalf("expected child and parent to be exported once each, got %v", e.spans)
+	}
+	child.SetAttribute("late", "value")
+	if len(child.Attributes) != 1 || child.Attributes["key"] != "value" {
+		t.Fatalf("unexpected attributes: %v", child.Attributes)
+	}
+	if child.End.Before(child.Start) {
+		t.Fatalf("span ended before it started: %v - %v", child.Start, child.End)
+	}
+}
+
+func TestStartRemoteSpan(t *testing.T) {
+	SetExporter(&recordingExporter{})
+	defer SetExporter(nil)
+
+	s, _ := StartRemoteSpan(context.Background(), "req", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	if s.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected trace ID: %s", s.TraceID)
+	}
+	if s.ParentID != "00f067aa0ba902b7" {
+		t.Fatalf("unexpected parent ID: %s", s.ParentID)
+	}
+
+	for _, v := range []string{
+		"",
+		"garbage",
+		"00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e4736-zzf067aa0ba902b7-01",
+		"00-4bf92f3577b34da6a3ce929d0e0e473-00f067aa0ba902b7-01",
+	} {
+		s, _ := StartRemoteSpan(context.Background(), "req", v)
+		if s.ParentID != "" || len(s.TraceID) != 32 {
+			t.Fatalf("expected a new trace for traceparent %q, got trace %s parent %s", v, s.TraceID, s.ParentID)
+		}
+	}
+}
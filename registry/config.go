@@ -19,6 +19,8 @@ type ServiceOptions struct {
 	AllowNondistributableArtifacts []string `json:"allow-nondistributable-artifacts,omitempty"`
 	Mirrors                        []string `json:"registry-mirrors,omitempty"`
 	InsecureRegistries             []string `json:"insecure-registries,omitempty"`
+	// MirrorRules are the mirrors of the repositories of any registry.
+	MirrorRules []MirrorRule `json:"registry-mirror-rules,omitempty"`
 
 	// V2Only controls access to legacy registries.  If it is set to true via the
 	// command line flag the daemon will not attempt to contact v1 legacy registries
@@ -28,7 +30,8 @@ type ServiceOptions struct {
 // serviceConfig holds daemon configuration for the registry service.
 type serviceConfig struct {
 	registrytypes.ServiceConfig
-	V2Only bool
+	MirrorRules []MirrorRule
+	V2Only      bool
 }
 
 var (
@@ -90,6 +93,9 @@ func newServiceConfig(options ServiceOptions) (*serviceConfig, error) {
 	if err := config.LoadInsecureRegistries(options.InsecureRegistries); err != nil {
 		return nil, err
 	}
+	if err := config.LoadMirrorRules(options.MirrorRules); err != nil {
+		return nil, err
+	}
 
 	return config, nil
 }
@@ -131,6 +137,18 @@ func (config *serviceConfig) LoadAllowNondistributableArtifacts(registries []str
 	return nil
 }
 
+// LoadMirrorRules loads mirror rules to config.
+// Returns an error if rules contains an invalid rule.
+func (config *serviceConfig) LoadMirrorRules(rules []MirrorRule) error {
+	for _, rule := range rules {
+		if err := ValidateMirrorRule(rule); err != nil {
+			return err
+		}
+	}
+	config.MirrorRules = rules
+	return nil
+}
+
 // LoadMirrors loads mirrors to config, after removing duplicates.
 // Returns an error if mirrors contains an invalid mirror.
 func (config *serviceConfig) LoadMirrors(mirrors []string) error {
@@ -0,0 +1,242 @@
+package registry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/docker/distribution/reference"
+	"github.com/docker/docker/api/types"
+)
+
+// MirrorRule redirects the pulls of the repositories matching a pattern to
+// mirrors, which are tried in order before the registry of the repository.
+type MirrorRule struct {
+	// Repository is the full name of a repository, such as
+	// "ghcr.io/org/app", or a prefix of full repository names followed by
+	// "/*", such as "ghcr.io/org/*" or "ghcr.io/*".
+	Repository string `json:"repository"`
+	// Mirrors are the mirrors of the matching repositories, in order of
+	// preference.
+	Mirrors []Mirror `json:"mirrors"`
+}
+
+// Mirror is a mirror of the repositories matching a mirror rule.
+type Mirror struct {
+	// Location is the name of the repository on the mirror, such as
+	// "mirror.local/ghcr/org/app". If the rule matches a prefix, the
+	// location ends with "/*", which is replaced by the rest of the name of
+	// the matching repository, as in "mirror.local/ghcr/org/*". A location
+	// without a path, such as "mirror.local", mirrors the repositories under
+	// their path in their registry.
+	Location string `json:"location"`
+	// Insecure allows plain HTTP and TLS connections without certificate
+	// verification to the mirror.
+	Insecure bool `json:"insecure,omitempty"`
+	// Username and Password are the credentials of the mirror. The
+	// credentials of the registry of the repository are never sent to the
+	// mirror.
+	Username string `json:"username,omitempty"`
+	Password string `json:"password,omitempty"`
+}
+
+// splitMirrorPattern splits a repository pattern or a mirror location into
+// its prefix and whether it ends with the "/*" wildcard.
+func splitMirrorPattern(pattern string) (string, bool) {
+	if strings.HasSuffix(pattern, "/*") {
+		return strings.TrimSuffix(pattern, "/*"), true
+	}
+	return pattern, false
+}
+
+// ValidateMirrorRule validates a mirror rule.
+func ValidateMirrorRule(rule MirrorRule) error {
+	prefix, wildcard := splitMirrorPattern(rule.Repository)
+	if !wildcard && !strings.Contains(prefix, "/") {
+		return fmt.Errorf("invalid mirror rule repository %q: it must be the full name of a repository, or end with /*", rule.Repository)
+	}
+	if err := validateMirrorName(prefix); err != nil {
+		return fmt.Errorf("invalid mirror rule repository %q: %v", rule.Repository, err)
+	}
+	if len(rule.Mirrors) == 0 {
+		return fmt.Errorf("invalid mirror rule for %q: at least one mirror is required", rule.Repository)
+	}
+	for _, m := range rule.Mirrors {
+		location, locationWildcard := splitMirrorPattern(m.Location)
+		if err := validateMirrorName(location); err != nil {
+			return fmt.Errorf("invalid mirror location %q: %v", m.Location, err)
+		}
+		if strings.Contains(location, "/") && locationWildcard != wildcard {
+			return fmt.Errorf("invalid mirror location %q: it must end with /* if and only if the repository %q does", m.Location, rule.Repository)
+		}
+	}
+	return nil
+}
+
+// validateMirrorName validates a registry host, or the full name of a
+// repository, including its registry host.
+func validateMirrorName(name string) error {
+	if validateNoScheme(name) != nil {
+		return fmt.Errorf("it must not contain '://'")
+	}
+	i := strings.Index(name, "/")
+	if i < 0 {
+		return validateHostPort(name)
+	}
+	if err := validateHostPort(name[:i]); err != nil {
+		return err
+	}
+	_, err := reference.WithName(name)
+	return err
+}
+
+// match returns whether the rule matches the repository name, and the part
+// of its name matched by the wildcard of the rule.
+func (rule MirrorRule) match(name reference.Named) (string, bool) {
+	prefix, wildcard := splitMirrorPattern(rule.Repository)
+	if !wildcard {
+		return "", name.Name() == prefix
+	}
+	if !strings.Contains(prefix, "/") {
+		// The rule matches all the repositories of a registry.
+		if reference.Domain(name) != prefix {
+			return "", false
+		}
+		return reference.Path(name), true
+	}
+	if !strings.HasPrefix(name.Name(), prefix+"/") {
+		return "", false
+	}
+	return strings.TrimPrefix(name.Name(), prefix+"/"), true
+}
+
+// endpoints returns the endpoints of the mirror for the repository name,
+// whose part matched by the wildcard of the rule is rest.
+func (m Mirror) endpoints(config *serviceConfig, name reference.Named, rest string) ([]APIEndpoint, error) {
+	location, wildcard := splitMirrorPattern(m.Location)
+	host, remoteName := location, reference.Path(name)
+	if i := strings.Index(location, "/"); i >= 0 {
+		host, remoteName = location[:i], location[i+1:]
+		if wildcard {
+			remoteName += "/" + rest
+		}
+	}
+
+	tlsConfig, err := newTLSConfig(host, !m.Insecure && isSecureIndex(config, host))
+	if err != nil {
+		return nil, err
+	}
+	authConfig := &types.AuthConfig{
+		Username:      m.Username,
+		Password:      m.Password,
+		ServerAddress: host,
+	}
+
+	endpoints := []APIEndpoint{{
+		URL:          &url.URL{Scheme: "https", Host: host},
+		Version:      APIVersion2,
+		Mirror:       true,
+		TrimHostname: true,
+		RemoteName:   remoteName,
+		AuthConfig:   authConfig,
+		TLSConfig:    tlsConfig,
+	}}
+	if tlsConfig.InsecureSkipVerify {
+		endpoints = append(endpoints, APIEndpoint{
+			URL:          &url.URL{Scheme: "http", Host: host},
+			Version:      APIVersion2,
+			Mirror:       true,
+			TrimHostname: true,
+			RemoteName:   remoteName,
+			AuthConfig:   authConfig,
+			// used to check if supposed to be secure via InsecureSkipVerify
+			TLSConfig: tlsConfig,
+		})
+	}
+	return endpoints, nil
+}
+
+// lookupMirrorRuleEndpoints returns the endpoints of the mirrors of the
+// first mirror rule matching the repository name.
+func (s *DefaultService) lookupMirrorRuleEndpoints(name reference.Named) ([]APIEndpoint, error) {
+	for _, rule := range s.config.MirrorRules {
+		rest, ok := rule.match(name)
+		if !ok {
+			continue
+		}
+		var endpoints []APIEndpoint
+		for _, m := range rule.Mirrors {
+			mirrorEndpoints, err := m.endpoints(s.config, name, rest)
+			if err != nil {
+				return nil, err
+			}
+			endpoints = append(endpoints, mirrorEndpoints...)
+		}
+		return endpoints, nil
+	}
+	return nil, nil
+}
+
+// MirrorRulesOpt is a flag value which adds a rule to a list of mirror
+// rules. Its format is a comma separated list of key=value pairs, e.g.
+// "repository=ghcr.io/org/*,mirror=mirror.local/ghcr/org/*". mirror may be
+// repeated, insecure-mirror adds a mirror with the Insecure option. The
+// credentials of mirrors can only be set in the configuration file.
+type MirrorRulesOpt struct {
+	name   string
+	values *[]MirrorRule
+}
+
+// NewNamedMirrorRulesOpt creates a new MirrorRulesOpt
+func NewNamedMirrorRulesOpt(name string, ref *[]MirrorRule) *MirrorRulesOpt {
+	if ref == nil {
+		ref = &[]MirrorRule{}
+	}
+	return &MirrorRulesOpt{name: name, values: ref}
+}
+
+// Name returns the name of the MirrorRulesOpt in the configuration.
+func (o *MirrorRulesOpt) Name() string {
+	return o.name
+}
+
+// Set parses a mirror rule and adds it to the list of rules.
+func (o *MirrorRulesOpt) Set(val string) error {
+	var rule MirrorRule
+	for _, field := range strings.Split(val, ",") {
+		parts := strings.SplitN(field, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("invalid mirror rule field %q: must be a key=value pair", field)
+		}
+		key, value := strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+		switch key {
+		case "repository":
+			rule.Repository = value
+		case "mirror":
+			rule.Mirrors = append(rule.Mirrors, Mirror{Location: value})
+		case "insecure-mirror":
+			rule.Mirrors = append(rule.Mirrors, Mirror{Location: value, Insecure: true})
+		default:
+			return fmt.Errorf("invalid mirror rule field %q: unknown key %q", field, key)
+		}
+	}
+	if err := ValidateMirrorRule(rule); err != nil {
+		return err
+	}
+	*o.values = append(*o.values, rule)
+	return nil
+}
+
+// String returns the repository patterns of the rules as a string.
+func (o *MirrorRulesOpt) String() string {
+	var out []string
+	for _, rule := range *o.values {
+		out = append(out, rule.Repository)
+	}
+	return fmt.Sprintf("%v", out)
+}
+
+// Type returns the type of the option
+func (o *MirrorRulesOpt) Type() string {
+	return "mirror-rule"
+}
@@ -0,0 +1,154 @@
+package registry
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/docker/distribution/reference"
+)
+
+func TestValidateMirrorRule(t *testing.T) {
+	valid := []MirrorRule{
+		{Repository: "ghcr.io/org/*", Mirrors: []Mirror{{Location: "mirror.local/ghcr/org/*"}, {Location: "mirror2.local:5000"}}},
+		{Repository: "ghcr.io/*", Mirrors: []Mirror{{Location: "mirror.local/ghcr/*"}}},
+		{Repository: "ghcr.io/org/app", Mirrors: []Mirror{{Location: "mirror.local/app", Insecure: true}}},
+		{Repository: "docker.io/library/*", Mirrors: []Mirror{{Location: "mirror.local", Username: "user", Password: "pass"}}},
+	}
+	for _, rule := range valid {
+		if err := ValidateMirrorRule(rule); err != nil {
+			t.Errorf("expected rule %+v to be valid: %v", rule, err)
+		}
+	}
+
+	invalid := []MirrorRule{
+		{Repository: "ghcr.io/org/*"},
+		{Repository: "", Mirrors: []Mirror{{Location: "mirror.local"}}},
+		{Repository: "ghcr.io/Org/*", Mirrors: []Mirror{{Location: "mirror.local"}}},
+		{Repository: "ghcr.io/org:latest", Mirrors: []Mirror{{Location: "mirror.local/app"}}},
+		{Repository: "ubuntu", Mirrors: []Mirror{{Location: "mirror.local/ubuntu"}}},
+		{Repository: "ghcr.io/org/*", Mirrors: []Mirror{{Location: "https://mirror.local"}}},
+		{Repository: "ghcr.io/org/*", Mirrors: []Mirror{{Location: "mirror.local/ghcr/org"}}},
+		{Repository: "ghcr.io/org/app", Mirrors: []Mirror{{Location: "mirror.local/ghcr/org/*"}}},
+	}
+	for _, rule := range invalid {
+		if err := ValidateMirrorRule(rule); err == nil {
+			t.Errorf("expected an error for rule %+v", rule)
+		}
+	}
+}
+
+func TestLookupPullEndpointsMirrorRules(t *testing.T) {
+	s, err := NewService(ServiceOptions{
+		MirrorRules: []MirrorRule{
+			{Repository: "ghcr.io/org/app", Mirrors: []Mirror{{Location: "app.mirror.local/app"}}},
+			{Repository: "ghcr.io/org/*", Mirrors: []Mirror{
+				{Location: "mirror.local/ghcr/org/*", Username: "user", Password: "pass"},
+				{Location: "backup.local:5000", Insecure: true},
+			}},
+			{Repository: "docker.io/*", Mirrors: []Mirror{{Location: "hub.local/hub/*"}}},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type expectedEndpoint struct {
+		url, remoteName, username string
+	}
+	for name, expected := range map[string][]expectedEndpoint{
+		"ghcr.io/org/app": {
+			{"https://app.mirror.local", "app", ""},
+			{"https://ghcr.io", "", ""},
+		},
+		"ghcr.io/org/team/tool": {
+			{"https://mirror.local", "ghcr/org/team/tool", "user"},
+			{"https://backup.local:5000", "org/team/tool", ""},
+			{"http://backup.local:5000", "org/team/tool", ""},
+			{"https://ghcr.io", "", ""},
+		},
+		"ghcr.io/other/app": {
+			{"https://ghcr.io", "", ""},
+		},
+		"busybox": {
+			{"https://hub.local", "hub/library/busybox", ""},
+			{DefaultV2Registry.String(), "", ""},
+		},
+	} {
+		ref, err := reference.ParseNormalizedNamed(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		endpoints, err := s.LookupPullEndpoints(ref)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var actual []expectedEndpoint
+		for _, e := range endpoints {
+			if e.Version != APIVersion2 {
+				continue
+			}
+			var username string
+			if e.AuthConfig != nil {
+				username = e.AuthConfig.Username
+			}
+			actual = append(actual, expectedEndpoint{e.URL.String(), e.RemoteName, username})
+			if e.RemoteName != "" && !e.Mirror {
+				t.Errorf("expected endpoint %s of %s to be a mirror", e.URL, name)
+			}
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("expected endpoints %v for %s, got %v", expected, name, actual)
+		}
+
+		pushEndpoints, err := s.LookupPushEndpoints(reference.Domain(ref))
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, e := range pushEndpoints {
+			if e.Mirror || e.RemoteName != "" {
+				t.Errorf("expected no mirror in the push endpoints of %s, got %s", name, e.URL)
+			}
+		}
+	}
+
+	// The rules are replaced when they are loaded again.
+	if err := s.LoadMirrorRules(nil); err != nil {
+		t.Fatal(err)
+	}
+	ref, _ := reference.ParseNormalizedNamed("ghcr.io/org/app")
+	endpoints, err := s.LookupPullEndpoints(ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if endpoints[0].URL.Host != "ghcr.io" {
+		t.Fatalf("expected no mirror after the rules are removed, got %s", endpoints[0].URL)
+	}
+	if err := s.LoadMirrorRules([]MirrorRule{{Repository: "ghcr.io/*"}}); err == nil {
+		t.Fatal("expected an error loading an invalid rule")
+	}
+}
+
+func TestMirrorRulesOpt(t *testing.T) {
+	var rules []MirrorRule
+	o := NewNamedMirrorRulesOpt("registry-mirror-rules", &rules)
+
+	if err := o.Set("repository=ghcr.io/org/*,mirror=mirror.local/ghcr/org/*,insecure-mirror=backup.local:5000"); err != nil {
+		t.Fatal(err)
+	}
+	expected := []MirrorRule{{
+		Repository: "ghcr.io/org/*",
+		Mirrors: []Mirror{
+			{Location: "mirror.local/ghcr/org/*"},
+			{Location: "backup.local:5000", Insecure: true},
+		},
+	}}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Fatalf("expected %v, got %v", expected, rules)
+	}
+
+	for _, invalid := range []string{"repository=ghcr.io/org/*", "mirror=mirror.local", "repository=ghcr.io/org/*,mirror", "repository=ghcr.io/org/*,mirror=mirror.local,username=user"} {
+		if err := o.Set(invalid); err == nil {
+			t.Fatalf("expected an error for %q", invalid)
+		}
+	}
+}
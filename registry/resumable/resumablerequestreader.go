@@ -1,6 +1,7 @@
 package resumable
 
 import (
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -9,11 +10,19 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+// ErrNoByteRanges is returned when resuming a request, or reading a range of
+// its body, from a server which does not support byte ranges.
+var ErrNoByteRanges = errors.New("the server doesn't support byte ranges")
+
 type requestReader struct {
-	client          *http.Client
-	request         *http.Request
-	lastRange       int64
-	totalSize       int64
+	client    *http.Client
+	request   *http.Request
+	lastRange int64
+	totalSize int64
+	// offset is the offset of the range of the body which is read, if
+	// ranged is set.
+	offset          int64
+	ranged          bool
 	currentResponse *http.Response
 	failures        uint32
 	maxFailures     uint32
@@ -33,14 +42,27 @@ func NewRequestReaderWithInitialResponse(c *http.Client, r *http.Request, maxfai
 	return &requestReader{client: c, request: r, maxFailures: maxfail, totalSize: totalsize, currentResponse: initialResponse, waitDuration: 5 * time.Second}
 }
 
+// NewRangeRequestReader makes it possible to resume reading the size bytes
+// at offset of a request's body transparently. The range is requested with
+// a Range header, so the server must support byte ranges.
+func NewRangeRequestReader(c *http.Client, r *http.Request, maxfail uint32, offset, size int64) io.ReadCloser {
+	return &requestReader{client: c, request: r, maxFailures: maxfail, offset: offset, totalSize: size, ranged: true, waitDuration: 5 * time.Second}
+}
+
 func (r *requestReader) Read(p []byte) (n int, err error) {
 	if r.client == nil || r.request == nil {
 		return 0, fmt.Errorf("client and request can't be nil")
 	}
 	isFreshRequest := false
-	if r.lastRange != 0 && r.currentResponse == nil {
-		readRange := fmt.Sprintf("bytes=%d-%d", r.lastRange, r.totalSize)
+	if r.ranged && r.currentResponse == nil {
+		readRange := fmt.Sprintf("bytes=%d-%d", r.offset+r.lastRange, r.offset+r.totalSize-1)
 		r.request.Header.Set("Range", readRange)
+	}
+	if r.lastRange != 0 && r.currentResponse == nil {
+		if !r.ranged {
+			readRange := fmt.Sprintf("bytes=%d-%d", r.lastRange, r.totalSize)
+			r.request.Header.Set("Range", readRange)
+		}
 		time.Sleep(r.waitDuration)
 	}
 	if r.currentResponse == nil {
@@ -59,9 +81,13 @@ func (r *requestReader) Read(p []byte) (n int, err error) {
 	if r.currentResponse.StatusCode == 416 && r.lastRange == r.totalSize && r.currentResponse.ContentLength == 0 {
 		r.cleanUpResponse()
 		return 0, io.EOF
-	} else if r.currentResponse.StatusCode != 206 && r.lastRange != 0 && isFreshRequest {
+	} else if r.currentResponse.StatusCode != 206 && (r.lastRange != 0 || r.ranged) && isFreshRequest {
+		statusCode := r.currentResponse.StatusCode
 		r.cleanUpResponse()
-		return 0, fmt.Errorf("the server doesn't support byte ranges")
+		if r.ranged && statusCode != http.StatusOK {
+			return 0, fmt.Errorf("unexpected status code %d for range request", statusCode)
+		}
+		return 0, ErrNoByteRanges
 	}
 	if r.totalSize == 0 {
 		r.totalSize = r.currentResponse.ContentLength
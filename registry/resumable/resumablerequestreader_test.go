@@ -255,3 +255,45 @@ func TestResumableRequestReaderWithInitialResponse(t *testing.T) {
 	resstr := strings.TrimSuffix(string(data), "\n")
 	assert.Equal(t, srvtxt, resstr)
 }
+
+func TestResumableRangeRequestReader(t *testing.T) {
+	srvtxt := "some response text data"
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.ServeContent(w, r, "", time.Time{}, strings.NewReader(srvtxt))
+	}))
+	defer ts.Close()
+
+	var req *http.Request
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	require.NoError(t, err)
+
+	client := &http.Client{}
+	retries := uint32(5)
+
+	resreq := NewRangeRequestReader(client, req, retries, 5, 8)
+	defer resreq.Close()
+
+	data, err := ioutil.ReadAll(resreq)
+	require.NoError(t, err)
+	assert.Equal(t, "response", string(data))
+}
+
+func TestResumableRangeRequestReaderWithServerDoesntSupportByteRanges(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintln(w, "some response text data")
+	}))
+	defer ts.Close()
+
+	var req *http.Request
+	req, err := http.NewRequest("GET", ts.URL, nil)
+	require.NoError(t, err)
+
+	client := &http.Client{}
+
+	resreq := NewRangeRequestReader(client, req, 5, 5, 8)
+	defer resreq.Close()
+
+	_, err = ioutil.ReadAll(resreq)
+	assert.Equal(t, ErrNoByteRanges, err)
+}
@@ -25,7 +25,7 @@ const (
 // Service is the interface defining what a registry service should implement.
 type Service interface {
 	Auth(ctx context.Context, authConfig *types.AuthConfig, userAgent string) (status, token string, err error)
-	LookupPullEndpoints(hostname string) (endpoints []APIEndpoint, err error)
+	LookupPullEndpoints(name reference.Named) (endpoints []APIEndpoint, err error)
 	LookupPushEndpoints(hostname string) (endpoints []APIEndpoint, err error)
 	ResolveRepository(name reference.Named) (*RepositoryInfo, error)
 	Search(ctx context.Context, term string, limit int, authConfig *types.AuthConfig, userAgent string, headers map[string][]string) (*registrytypes.SearchResults, error)
@@ -33,6 +33,7 @@ type Service interface {
 	TLSConfig(hostname string) (*tls.Config, error)
 	LoadAllowNondistributableArtifacts([]string) error
 	LoadMirrors([]string) error
+	LoadMirrorRules([]MirrorRule) error
 	LoadInsecureRegistries([]string) error
 }
 
@@ -95,6 +96,14 @@ func (s *DefaultService) LoadMirrors(mirrors []string) error {
 	return s.config.LoadMirrors(mirrors)
 }
 
+// LoadMirrorRules loads registry mirror rules for Service
+func (s *DefaultService) LoadMirrorRules(rules []MirrorRule) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.config.LoadMirrorRules(rules)
+}
+
 // LoadInsecureRegistries loads insecure registries for Service
 func (s *DefaultService) LoadInsecureRegistries(registries []string) error {
 	s.mu.Lock()
@@ -256,6 +265,12 @@ type APIEndpoint struct {
 	Official                       bool
 	TrimHostname                   bool
 	TLSConfig                      *tls.Config
+	// RemoteName, if set, is the name of the repository on the endpoint,
+	// which is a mirror of the repository with another name.
+	RemoteName string
+	// AuthConfig, if set, holds the credentials of the endpoint, which are
+	// used instead of the credentials of the registry of the repository.
+	AuthConfig *types.AuthConfig
 }
 
 // ToV1Endpoint returns a V1 API endpoint based on the APIEndpoint
@@ -280,14 +295,23 @@ func (s *DefaultService) tlsConfigForMirror(mirrorURL *url.URL) (*tls.Config, er
 	return s.tlsConfig(mirrorURL.Host)
 }
 
-// LookupPullEndpoints creates a list of endpoints to try to pull from, in order of preference.
+// LookupPullEndpoints creates a list of endpoints to try to pull the repository name from, in order of preference.
 // It gives preference to v2 endpoints over v1, mirrors over the actual
-// registry, and HTTPS over plain HTTP.
-func (s *DefaultService) LookupPullEndpoints(hostname string) (endpoints []APIEndpoint, err error) {
+// registry, and HTTPS over plain HTTP. The mirrors of the first mirror rule
+// matching name are preferred over the other mirrors.
+func (s *DefaultService) LookupPullEndpoints(name reference.Named) (endpoints []APIEndpoint, err error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	return s.lookupEndpoints(hostname)
+	endpoints, err = s.lookupMirrorRuleEndpoints(name)
+	if err != nil {
+		return nil, err
+	}
+	registryEndpoints, err := s.lookupEndpoints(reference.Domain(name))
+	if err != nil {
+		return nil, err
+	}
+	return append(endpoints, registryEndpoints...), nil
 }
 
 // LookupPushEndpoints creates a list of endpoints to try to push to, in order of preference.
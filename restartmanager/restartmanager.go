@@ -10,9 +10,10 @@ import (
 )
 
 const (
-	backoffMultiplier = 2
-	defaultTimeout    = 100 * time.Millisecond
-	maxRestartTimeout = 1 * time.Minute
+	backoffMultiplier  = 2
+	defaultTimeout     = 100 * time.Millisecond
+	maxRestartTimeout  = 1 * time.Minute
+	defaultResetWindow = 10 * time.Second
 )
 
 // ErrRestartCanceled is returned when the restart manager has been
@@ -66,19 +67,23 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	if rm.active {
 		return false, nil, fmt.Errorf("invalid call on an active restart manager")
 	}
-	// if the container ran for more than 10s, regardless of status and policy reset the
-	// the timeout back to the default.
-	if executionDuration.Seconds() >= 10 {
+	initialTimeout := durationWithDefault(rm.policy.InitialDelay, defaultTimeout)
+	maxTimeout := durationWithDefault(rm.policy.MaxDelay, maxRestartTimeout)
+	resetWindow := durationWithDefault(rm.policy.ResetWindow, defaultResetWindow)
+
+	// if the container ran for longer than the reset window, regardless of status and
+	// policy reset the timeout back to the initial delay.
+	if executionDuration >= resetWindow {
 		rm.timeout = 0
 	}
 	switch {
 	case rm.timeout == 0:
-		rm.timeout = defaultTimeout
-	case rm.timeout < maxRestartTimeout:
+		rm.timeout = initialTimeout
+	case rm.timeout < maxTimeout:
 		rm.timeout *= backoffMultiplier
 	}
-	if rm.timeout > maxRestartTimeout {
-		rm.timeout = maxRestartTimeout
+	if rm.timeout > maxTimeout {
+		rm.timeout = maxTimeout
 	}
 
 	var restart bool
@@ -87,7 +92,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 		restart = true
 	case rm.policy.IsUnlessStopped() && !hasBeenManuallyStopped:
 		restart = true
-	case rm.policy.IsOnFailure():
+	case rm.policy.IsOnFailure(), rm.policy.IsOnUnhealthy():
 		// the default value of 0 for MaximumRetryCount means that we will not enforce a maximum count
 		if max := rm.policy.MaximumRetryCount; max == 0 || rm.restartCount < max {
 			restart = exitCode != 0
@@ -103,6 +108,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 
 	unlockOnExit = false
 	rm.active = true
+	timeout := rm.timeout
 	rm.Unlock()
 
 	ch := make(chan error)
@@ -111,7 +117,7 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 		case <-rm.cancel:
 			ch <- ErrRestartCanceled
 			close(ch)
-		case <-time.After(rm.timeout):
+		case <-time.After(timeout):
 			rm.Lock()
 			close(ch)
 			rm.active = false
@@ -122,6 +128,14 @@ func (rm *restartManager) ShouldRestart(exitCode uint32, hasBeenManuallyStopped
 	return true, ch, nil
 }
 
+// durationWithDefault returns d, or defaultValue if d is zero.
+func durationWithDefault(d, defaultValue time.Duration) time.Duration {
+	if d == 0 {
+		return defaultValue
+	}
+	return d
+}
+
 func (rm *restartManager) Cancel() error {
 	rm.Do(func() {
 		rm.Lock()
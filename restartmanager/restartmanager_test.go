@@ -34,3 +34,66 @@ func TestRestartManagerTimeoutReset(t *testing.T) {
 		t.Fatalf("restart manager should have a timeout of 100 ms but has %s", rm.timeout)
 	}
 }
+
+func TestRestartManagerBackoff(t *testing.T) {
+	policy := container.RestartPolicy{
+		Name:         "always",
+		InitialDelay: 10 * time.Millisecond,
+		MaxDelay:     30 * time.Millisecond,
+		ResetWindow:  time.Minute,
+	}
+	rm := New(policy, 0).(*restartManager)
+	defer rm.Cancel()
+	restart := func(executionDuration time.Duration) {
+		_, ch, err := rm.ShouldRestart(0, false, executionDuration)
+		if err != nil {
+			t.Fatal(err)
+		}
+		// wait for the restart delay, so that the next call finds the
+		// restart manager inactive
+		if err := <-ch; err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, expected := range []time.Duration{10 * time.Millisecond, 20 * time.Millisecond, 30 * time.Millisecond, 30 * time.Millisecond} {
+		restart(30 * time.Second)
+		if rm.timeout != expected {
+			t.Fatalf("restart manager should have a timeout of %s but has %s", expected, rm.timeout)
+		}
+	}
+
+	// running for longer than the reset window resets the backoff
+	restart(time.Minute)
+	if rm.timeout != 10*time.Millisecond {
+		t.Fatalf("restart manager should have a timeout of 10ms but has %s", rm.timeout)
+	}
+}
+
+func TestRestartManagerOnUnhealthy(t *testing.T) {
+	rm := New(container.RestartPolicy{Name: "on-unhealthy", MaximumRetryCount: 1}, 0).(*restartManager)
+	defer rm.Cancel()
+	should, _, err := rm.ShouldRestart(0, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if should {
+		t.Fatal("container exiting successfully should not be restarted")
+	}
+	should, ch, err := rm.ShouldRestart(137, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !should {
+		t.Fatal("container should be restarted")
+	}
+	if err := <-ch; err != nil {
+		t.Fatal(err)
+	}
+	should, _, err = rm.ShouldRestart(137, false, time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if should {
+		t.Fatal("container should not be restarted more than the maximum retry count")
+	}
+}